@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/joshi4/splash/parser"
+)
+
+// configPath is the --config flag, naming a file of user-defined formats
+var configPath string
+
+var loadConfigOnce = sync.OnceValue(func() error {
+	return loadFormatConfig(configPath, defaultConfigPath())
+})
+
+// defaultConfigPath is where user-defined formats are read from without
+// --config, e.g. ~/.config/splash/formats.yaml on Linux
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "splash", "formats.yaml")
+}
+
+// loadFormatConfig registers the formats in the config file at path, or at
+// fallback when path is empty. A missing fallback file isn't an error.
+func loadFormatConfig(path, fallback string) error {
+	explicit := path != ""
+	if !explicit {
+		path = fallback
+	}
+	if path == "" {
+		return nil
+	}
+
+	config, err := parser.LoadFormatConfig(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("loading formats: %w", err)
+	}
+	if _, err := config.Register(); err != nil {
+		return fmt.Errorf("loading formats from %s: %w", path, err)
+	}
+	return nil
+}
+
+// displayConfigPath shows the default config path in help text
+func displayConfigPath() string {
+	path := defaultConfigPath()
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		if rel, err := filepath.Rel(home, path); err == nil && filepath.IsLocal(rel) {
+			return filepath.Join("~", rel)
+		}
+	}
+	return path
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshi4/splash/parser"
+)
+
+func TestLoadFormatConfig(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.yaml")
+
+	if err := loadFormatConfig("", missing); err != nil {
+		t.Errorf("missing default config: error = %v, want none", err)
+	}
+	if err := loadFormatConfig(missing, ""); err == nil {
+		t.Error("missing --config file: no error")
+	}
+
+	path := filepath.Join(dir, "formats.yaml")
+	config := "formats:\n  - name: Test Cmd Format\n    pattern: '^CMDFMT (?P<message>.*)'\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadFormatConfig("", path); err != nil {
+		t.Fatalf("loadFormatConfig() error = %v", err)
+	}
+	if got := parser.NewParser().DetectFormat("CMDFMT hello").String(); got != "Test Cmd Format" {
+		t.Errorf("DetectFormat() = %q, want the configured format", got)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("formats:\n  - name: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadFormatConfig(bad, ""); err == nil || !strings.Contains(err.Error(), "bad.yaml") {
+		t.Errorf("invalid config: error = %v, want it to name the file", err)
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshi4/splash/parser"
+)
+
+// Detect flags
+var (
+	explainDetection bool
+	summarizeFormats bool
+)
+
+// detectCmd represents the detect command
+var detectCmd = &cobra.Command{
+	Use:   "detect [file...]",
+	Short: "Show the format detected for each line",
+	Long: `Show the format splash detects for each line of the inputs, or of stdin.
+
+--explain lists every detector that matched a line, with its score, confidence,
+specificity and pattern length, and why the winner won: a higher score, which
+includes a bonus for the previous line's format, a tie broken by specificity or
+pattern length, or a multi-line entry in progress. --summary counts the lines of
+each format instead.`,
+	Example: `  splash detect --explain app.log
+  splash detect --summary 'logs/*.log'
+  kubectl logs my-pod | splash detect --summary`,
+	Run: func(_ *cobra.Command, args []string) {
+		exitOnError(runDetect(args))
+	},
+}
+
+func runDetect(args []string) error {
+	if err := loadConfigOnce(); err != nil {
+		return err
+	}
+	parserOpts, err := parserOptionsFromFlags()
+	if err != nil {
+		return err
+	}
+	inputs, err := expandInputs(args)
+	if err != nil {
+		return err
+	}
+
+	for i, name := range inputs {
+		if len(inputs) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("==> %s <==\n", inputLabel(name))
+		}
+		r, err := openInput(name)
+		if err != nil {
+			return err
+		}
+		err = detectInput(os.Stdout, r, parserOpts...)
+		_ = r.Close()
+		if err != nil {
+			return fmt.Errorf("reading %s: %v", inputLabel(name), err)
+		}
+	}
+	return nil
+}
+
+// detectInput writes the format of each line of r, or a summary with --summary
+func detectInput(w io.Writer, r io.Reader, opts ...parser.Option) error {
+	logParser := parser.NewParser(opts...)
+	counts := make(map[parser.LogFormat]int)
+	total := 0
+
+	scanner := parser.NewLineScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		total++
+		explanation := logParser.Explain(line)
+		counts[explanation.Format]++
+
+		switch {
+		case summarizeFormats:
+		case explainDetection:
+			writeExplanation(w, total, line, explanation)
+		default:
+			continued := ""
+			if explanation.Continued {
+				continued = " +"
+			}
+			fmt.Fprintf(w, "%6d  %-24s %s\n", total, explanation.Format.String()+continued, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if summarizeFormats {
+		writeFormatSummary(w, counts, total)
+	}
+	return nil
+}
+
+// writeExplanation writes a line's format, how it was decided and every
+// detector that matched it
+func writeExplanation(w io.Writer, n int, line string, e parser.Explanation) {
+	fmt.Fprintf(w, "%d: %s\n", n, line)
+	fmt.Fprintf(w, "  format:   %s\n", e.Format)
+	fmt.Fprintf(w, "  decision: %s", e.Decision)
+	if reason := tieBreak(e); reason != "" {
+		fmt.Fprintf(w, " (%s)", reason)
+	}
+	fmt.Fprintln(w)
+	if e.Active != parser.UnknownFormat {
+		fmt.Fprintf(w, "  active:   %s entry from an earlier line\n", e.Active)
+	}
+	if e.Locked != parser.UnknownFormat {
+		fmt.Fprintf(w, "  locked:   %s\n", e.Locked)
+	}
+	if e.Envelope != parser.NoEnvelope {
+		fmt.Fprintf(w, "  envelope: %s, detected the message inside\n", e.Envelope)
+	}
+
+	if len(e.Candidates) == 0 {
+		fmt.Fprintln(w, "  matched:  none")
+		return
+	}
+	fmt.Fprintln(w, "  matched:")
+	width := 0
+	for _, c := range e.Candidates {
+		width = max(width, len(c.Format.String()))
+	}
+	for _, c := range e.Candidates {
+		marker := " "
+		if c.Format == e.Format {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "  %s %-*s  score %.2f  confidence %.2f  specificity %3d  pattern length %3d\n",
+			marker, width, c.Format, c.Score, c.Confidence, c.Specificity, c.PatternLength)
+	}
+}
+
+// tieBreak explains why the winner was chosen over the other matches
+func tieBreak(e parser.Explanation) string {
+	switch e.Decision {
+	case parser.DecisionLocked:
+		if !slices.ContainsFunc(e.Candidates, func(c parser.Candidate) bool { return c.Format == e.Format }) {
+			return fmt.Sprintf("%s didn't match, but the lock holds", e.Format)
+		}
+		return ""
+	case parser.DecisionRanked, parser.DecisionPrevious:
+	default:
+		return ""
+	}
+
+	if len(e.Candidates) == 1 {
+		return "only match"
+	}
+	top, next := e.Candidates[0], e.Candidates[1]
+	switch {
+	case top.Score != next.Score:
+		return fmt.Sprintf("beat %s on score, %.2f > %.2f", next.Format, top.Score, next.Score)
+	case e.Decision == parser.DecisionPrevious:
+		return fmt.Sprintf("tied with %s, the previous line's format wins", next.Format)
+	case top.Specificity != next.Specificity:
+		return fmt.Sprintf("beat %s on specificity, %d > %d", next.Format, top.Specificity, next.Specificity)
+	case top.PatternLength != next.PatternLength:
+		return fmt.Sprintf("beat %s on pattern length, %d > %d", next.Format, top.PatternLength, next.PatternLength)
+	default:
+		return fmt.Sprintf("beat %s by detector order", next.Format)
+	}
+}
+
+// writeFormatSummary writes a histogram of the lines of each format, most common first
+func writeFormatSummary(w io.Writer, counts map[parser.LogFormat]int, total int) {
+	formats := make([]parser.LogFormat, 0, len(counts))
+	width := 0
+	for format := range counts {
+		formats = append(formats, format)
+		width = max(width, len(format.String()))
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		if counts[formats[i]] != counts[formats[j]] {
+			return counts[formats[i]] > counts[formats[j]]
+		}
+		return formats[i] < formats[j]
+	})
+
+	const barWidth = 40
+	for _, format := range formats {
+		n := counts[format]
+		bar := strings.Repeat("█", max(1, n*barWidth/total))
+		fmt.Fprintf(w, "%-*s  %7d  %5.1f%%  %s\n", width, format, n, 100*float64(n)/float64(total), bar)
+	}
+	fmt.Fprintf(w, "%-*s  %7d\n", width, "Total", total)
+}
+
+func init() {
+	detectCmd.Flags().BoolVar(&explainDetection, "explain", false, "show every detector that matched each line and why the winner won")
+	detectCmd.Flags().BoolVar(&summarizeFormats, "summary", false, "count the lines of each format instead of listing them")
+	rootCmd.AddCommand(detectCmd)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshi4/splash/parser"
+)
+
+func TestDetectInputExplain(t *testing.T) {
+	explainDetection = true
+	defer func() { explainDetection = false }()
+
+	input := strings.Join([]string{
+		"TypeError: Cannot read properties of undefined (reading 'id')",
+		`Exception in thread "main" java.lang.IllegalStateException: boom`,
+		"\tat com.example.Main.run(Main.java:42)",
+		"plain text",
+		`2025-01-19T10:30:00.123456789Z stdout F {"level":"info","msg":"ok"}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := detectInput(&out, strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"  format:   JavaScript Exception\n",
+		"  decision: highest ranked match (beat Python Exception on score, 0.90 > 0.75)\n",
+		"  * JavaScript Exception  score 0.90  confidence 0.90  specificity  70  pattern length 138\n",
+		"    Python Exception      score 0.75  confidence 0.75  specificity  70  pattern length  64\n",
+		"  decision: continues the active entry\n",
+		"  active:   Java Exception entry from an earlier line\n",
+		"  decision: no detector matched\n  active:   Java Exception entry from an earlier line\n  matched:  none\n",
+		"  format:   JSON\n  decision: highest ranked match (only match)\n  envelope: CRI, detected the message inside\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("detectInput() output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDetectInputSummary(t *testing.T) {
+	summarizeFormats = true
+	defer func() { summarizeFormats = false }()
+
+	input := strings.Join([]string{
+		`{"level":"info"}`,
+		`{"level":"warn"}`,
+		`{"level":"error"}`,
+		"plain text",
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := detectInput(&out, strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	want := "JSON           3   75.0%  " + strings.Repeat("█", 30) + "\n" +
+		"Unknown        1   25.0%  " + strings.Repeat("█", 10) + "\n" +
+		"Total          4\n"
+	if got := out.String(); got != want {
+		t.Errorf("detectInput() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTieBreak(t *testing.T) {
+	rails := parser.Candidate{Format: parser.RailsFormat, Specificity: 50, PatternLength: 54, Score: 1}
+	goStandard := parser.Candidate{Format: parser.GoStandardFormat, Specificity: 50, PatternLength: 38, Score: 1}
+	json := parser.Candidate{Format: parser.JSONFormat, Specificity: 100, Score: 1}
+	logfmt := parser.Candidate{Format: parser.LogfmtFormat, Specificity: 100, Score: 0.6}
+	previousGoStandard := goStandard
+	previousGoStandard.Score = 1.25
+
+	tests := []struct {
+		name string
+		e    parser.Explanation
+		want string
+	}{
+		{
+			name: "only match",
+			e:    parser.Explanation{Format: parser.RailsFormat, Decision: parser.DecisionRanked, Candidates: []parser.Candidate{rails}},
+			want: "only match",
+		},
+		{
+			name: "score",
+			e:    parser.Explanation{Format: parser.GoStandardFormat, Decision: parser.DecisionRanked, Candidates: []parser.Candidate{goStandard, logfmt}},
+			want: "beat Logfmt on score, 1.00 > 0.60",
+		},
+		{
+			name: "specificity",
+			e:    parser.Explanation{Format: parser.JSONFormat, Decision: parser.DecisionRanked, Candidates: []parser.Candidate{json, rails}},
+			want: "beat Rails on specificity, 100 > 50",
+		},
+		{
+			name: "pattern length",
+			e:    parser.Explanation{Format: parser.RailsFormat, Decision: parser.DecisionRanked, Candidates: []parser.Candidate{rails, goStandard}},
+			want: "beat Go Standard on pattern length, 54 > 38",
+		},
+		{
+			name: "detector order",
+			e:    parser.Explanation{Format: parser.RailsFormat, Decision: parser.DecisionRanked, Candidates: []parser.Candidate{rails, rails}},
+			want: "beat Rails by detector order",
+		},
+		{
+			name: "previous line's format",
+			e:    parser.Explanation{Format: parser.GoStandardFormat, Decision: parser.DecisionPrevious, Candidates: []parser.Candidate{previousGoStandard, rails}},
+			want: "beat Rails on score, 1.25 > 1.00",
+		},
+		{
+			name: "previous line's format wins a tie",
+			e:    parser.Explanation{Format: parser.GoStandardFormat, Decision: parser.DecisionPrevious, Candidates: []parser.Candidate{goStandard, rails}},
+			want: "tied with Rails, the previous line's format wins",
+		},
+		{
+			name: "locked format kept though it didn't match",
+			e:    parser.Explanation{Format: parser.LogfmtFormat, Decision: parser.DecisionLocked, Candidates: []parser.Candidate{goStandard}},
+			want: "Logfmt didn't match, but the lock holds",
+		},
+		{
+			name: "continued",
+			e:    parser.Explanation{Format: parser.RailsFormat, Decision: parser.DecisionContinued, Candidates: []parser.Candidate{rails}},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tieBreak(tt.e); got != tt.want {
+				t.Errorf("tieBreak() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectInputWithForcedFormat(t *testing.T) {
+	formatName = "nginx"
+	defer func() { formatName = "" }()
+
+	opts, err := parserOptionsFromFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := detectInput(&out, strings.NewReader(`{"level":"info"}`), opts...); err != nil {
+		t.Fatal(err)
+	}
+	if want := "     1  Nginx                    {\"level\":\"info\"}\n"; out.String() != want {
+		t.Errorf("detectInput() = %q, want %q", out.String(), want)
+	}
+
+	formatName = "cobol"
+	if _, err := parserOptionsFromFlags(); err == nil {
+		t.Error("parserOptionsFromFlags() accepted an unknown format")
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// Wrap mode flags
+var usePTY bool
+
+// Exit codes used when the wrapped command can't be run, matching the shell's conventions
+const (
+	exitCommandNotFound   = 127
+	exitCommandNotStarted = 126
+)
+
+// runWrapped runs argv as a child process, colorizing its stdout and stderr,
+// and returns the exit code splash should exit with
+func runWrapped(argv []string) int {
+	logColorizer, err := newColorizerFromFlags()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	out, err := newLineWriter(logColorizer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	code, err := runCommand(argv, out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "splash: %v\n", err)
+		if errors.Is(err, exec.ErrNotFound) {
+			return exitCommandNotFound
+		}
+		return exitCommandNotStarted
+	}
+
+	CheckForUpgradesOnExit()
+	return code
+}
+
+// runCommand starts argv, colorizes its output until both streams close and
+// returns the child's exit code. Stdout and stderr each get their own parser
+// so multi-line state on one stream is never broken by lines on the other.
+// Stderr lines are written to stderr behind a gutter. Signals splash receives
+// are forwarded to the child, which decides when everyone exits.
+func runCommand(argv []string, out *lineWriter) (int, error) {
+	child := exec.Command(argv[0], argv[1:]...)
+	child.Stdin = os.Stdin
+
+	stderr, err := child.StderrPipe()
+	if err != nil {
+		return 0, err
+	}
+
+	var stdout io.ReadCloser
+	var signals relayedSignals
+	if usePTY {
+		stdout, signals, err = startWithPTY(child)
+	} else {
+		stdout, signals, err = startWithPipes(child)
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer stdout.Close()
+
+	stopForwarding := forwardSignals(child.Process, signals)
+	defer stopForwarding()
+
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := colorizeStream(ctx, stdout, "", out, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "splash: reading stdout: %v\n", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := colorizeStream(ctx, stderr, out.colorizer.StderrGutter(), out, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "splash: reading stderr: %v\n", err)
+		}
+	}()
+	wg.Wait()
+
+	return exitCode(child.Wait()), nil
+}
+
+// startWithPipes starts the child with its stdout connected to a pipe
+func startWithPipes(child *exec.Cmd) (io.ReadCloser, relayedSignals, error) {
+	stdout, err := child.StdoutPipe()
+	if err != nil {
+		return nil, relayedSignals{}, err
+	}
+	signals := setProcessGroup(child)
+	if err := child.Start(); err != nil {
+		return nil, relayedSignals{}, err
+	}
+	return stdout, signals, nil
+}
+
+// relayedSignals are the signals splash handles while a child runs
+type relayedSignals struct {
+	forward []os.Signal // relayed to the child
+	absorb  []os.Signal // reach the child without splash, which only outlives them
+}
+
+// forwardSignals relays the child's forwarded signals to it and swallows the
+// absorbed ones. The returned function stops forwarding.
+func forwardSignals(process *os.Process, signals relayedSignals) func() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, slices.Concat(signals.forward, signals.absorb)...)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigChan:
+				if slices.Contains(signals.forward, sig) {
+					relaySignal(process, sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
+
+// exitCode translates the result of waiting on the child into an exit code.
+// A child killed by a signal exits with 128 plus the signal number, as in a shell.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 1
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/joshi4/splash/colorizer"
+)
+
+func TestRunCommandPropagatesExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	tests := []struct {
+		name   string
+		script string
+		want   int
+	}{
+		{name: "success", script: "echo ok", want: 0},
+		{name: "failure", script: "echo failing >&2; exit 3", want: 3},
+		{name: "killed by signal", script: "kill -TERM $$", want: 143},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := runCommand([]string{"sh", "-c", tt.script}, &lineWriter{colorizer: colorizer.NewColorizer()})
+			if err != nil {
+				t.Fatalf("runCommand() error = %v", err)
+			}
+			if code != tt.want {
+				t.Errorf("runCommand() exit code = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCommandNotFound(t *testing.T) {
+	_, err := runCommand([]string{"splash-test-command-that-does-not-exist"}, &lineWriter{colorizer: colorizer.NewColorizer()})
+	if !errors.Is(err, exec.ErrNotFound) {
+		t.Fatalf("runCommand() error = %v, want exec.ErrNotFound", err)
+	}
+}
+
+func TestRunCommandReadsStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses cat")
+	}
+
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+
+	origStdin, origStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdinReader, stdout
+	defer func() { os.Stdin, os.Stdout = origStdin, origStdout }()
+
+	go func() {
+		_, _ = stdinWriter.WriteString("hello from stdin\n")
+		_ = stdinWriter.Close()
+	}()
+
+	code, err := runCommand([]string{"cat"}, &lineWriter{colorizer: colorizer.NewColorizer()})
+	_ = stdinReader.Close()
+	if err != nil {
+		t.Fatalf("runCommand() error = %v", err)
+	}
+	if code != 0 {
+		t.Errorf("runCommand() exit code = %d, want 0", code)
+	}
+
+	got, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "hello from stdin") {
+		t.Errorf("runCommand() output = %q, want it to contain the child's stdin", got)
+	}
+}
@@ -0,0 +1,110 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+// ownGroupSignals are handled for a child in a process group of its own. The
+// terminal's signals don't reach that group, so splash relays them all,
+// including Ctrl-Z, the shell's fg and window resizes.
+var ownGroupSignals = relayedSignals{
+	forward: []os.Signal{
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGHUP,
+		syscall.SIGQUIT,
+		syscall.SIGUSR1,
+		syscall.SIGUSR2,
+		syscall.SIGTSTP,
+		syscall.SIGCONT,
+		syscall.SIGWINCH,
+	},
+}
+
+// sharedGroupSignals are handled for a child in splash's process group. The
+// terminal sends Ctrl-C and Ctrl-\ to the whole group, so splash only catches
+// those to outlive the child. Job control and resizes reach both on their own.
+var sharedGroupSignals = relayedSignals{
+	forward: []os.Signal{
+		syscall.SIGTERM,
+		syscall.SIGHUP,
+		syscall.SIGUSR1,
+		syscall.SIGUSR2,
+	},
+	absorb: []os.Signal{
+		syscall.SIGINT,
+		syscall.SIGQUIT,
+	},
+}
+
+// setProcessGroup starts the child in a process group of its own. Keystrokes
+// like Ctrl-C then reach only splash, which forwards them, so the child sees
+// each signal exactly once instead of once from the terminal and again from
+// splash. A child reading from the terminal stays in splash's group, because
+// the terminal stops a background group that reads it.
+func setProcessGroup(child *exec.Cmd) relayedSignals {
+	if !isStdinFromPipe() {
+		return sharedGroupSignals
+	}
+	child.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return ownGroupSignals
+}
+
+// relaySignal sends sig to the child. Splash stops itself after relaying
+// Ctrl-Z, as the terminal would have stopped both, and the shell's fg then
+// continues splash, which relays that too.
+func relaySignal(process *os.Process, sig os.Signal) {
+	_ = process.Signal(sig)
+	if sig == syscall.SIGTSTP {
+		_ = syscall.Kill(os.Getpid(), syscall.SIGSTOP)
+	}
+}
+
+// startWithPTY starts the child with its stdout connected to a pseudo-terminal
+// so it keeps the line buffering it uses when writing to a terminal. The
+// returned reader yields what the child writes there.
+func startWithPTY(child *exec.Cmd) (io.ReadCloser, relayedSignals, error) {
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		return nil, relayedSignals{}, err
+	}
+	defer tty.Close()
+
+	// Programs that lay out output for the terminal should see its real width
+	if size, err := pty.GetsizeFull(os.Stdout); err == nil {
+		_ = pty.Setsize(ptmx, size)
+	}
+
+	child.Stdout = tty
+	// The pty becomes the controlling terminal of the child's new session; Ctty is
+	// the child's descriptor number for it, which is stdout. Only the pty path
+	// starts a session, as a child needs one to take a controlling terminal.
+	child.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 1}
+	if err := child.Start(); err != nil {
+		_ = ptmx.Close()
+		return nil, relayedSignals{}, err
+	}
+	return ptyReader{ptmx}, ownGroupSignals, nil
+}
+
+// ptyReader reads the pty master. Linux reports EIO once the child side has
+// been closed, which for our purposes is simply the end of the output.
+type ptyReader struct {
+	*os.File
+}
+
+func (r ptyReader) Read(p []byte) (int, error) {
+	n, err := r.File.Read(p)
+	if errors.Is(err, syscall.EIO) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// forwardedSignals are relayed from splash to a wrapped command
+var forwardedSignals = relayedSignals{forward: []os.Signal{os.Interrupt}}
+
+// setProcessGroup leaves the child alone on Windows, where console signals reach every process attached to the console
+func setProcessGroup(_ *exec.Cmd) relayedSignals {
+	return forwardedSignals
+}
+
+// relaySignal sends sig to the child
+func relaySignal(process *os.Process, sig os.Signal) {
+	_ = process.Signal(sig)
+}
+
+// startWithPTY is not supported on Windows
+func startWithPTY(_ *exec.Cmd) (io.ReadCloser, relayedSignals, error) {
+	return nil, relayedSignals{}, errors.New("--pty is not supported on Windows")
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/joshi4/splash/parser"
+)
+
+// Filter flags
+var (
+	filterMode    bool
+	invertMatch   bool
+	afterContext  int
+	beforeContext int
+	bothContext   int
+)
+
+// contextSeparator is printed between groups of entries that aren't adjacent, as in grep
+const contextSeparator = "--"
+
+// filterOptions controls which entries an entryFilter prints
+type filterOptions struct {
+	before int  // entries of leading context
+	after  int  // entries of trailing context
+	invert bool // print the entries that don't match instead
+}
+
+// filterOptionsFromFlags validates the filter flags. Context and --invert
+// imply --filter. ok is false when filtering is off. hasSearch reports
+// whether there is anything to match entries against.
+func filterOptionsFromFlags(hasSearch bool) (opts filterOptions, ok bool, err error) {
+	if !filterMode && !invertMatch && afterContext == 0 && beforeContext == 0 && bothContext == 0 {
+		return filterOptions{}, false, nil
+	}
+	if !hasSearch {
+		return filterOptions{}, false, fmt.Errorf("--filter requires --search, --regexp or --where")
+	}
+	if afterContext < 0 || beforeContext < 0 || bothContext < 0 {
+		return filterOptions{}, false, fmt.Errorf("context must not be negative")
+	}
+
+	opts = filterOptions{before: bothContext, after: bothContext, invert: invertMatch}
+	if beforeContext > 0 {
+		opts.before = beforeContext
+	}
+	if afterContext > 0 {
+		opts.after = afterContext
+	}
+	return opts, true, nil
+}
+
+// entryFilter prints only the entries that match, along with the requested
+// number of entries of context around them. An entry is whatever unit the
+// caller groups lines into (a line plus its stack trace, for example), so
+// one matching frame brings the whole trace with it.
+type entryFilter[E any] struct {
+	opts      filterOptions
+	matches   func(E) bool
+	emit      func(E)
+	separator func()
+
+	pending   []E  // entries held back as leading context
+	afterLeft int  // trailing context entries still to print
+	printed   bool // whether any entry has been printed
+	skipped   bool // whether entries were dropped since the last printed one
+}
+
+func newEntryFilter[E any](opts filterOptions, matches func(E) bool, emit func(E), separator func()) *entryFilter[E] {
+	return &entryFilter[E]{opts: opts, matches: matches, emit: emit, separator: separator}
+}
+
+// add considers the next entry of the stream
+func (f *entryFilter[E]) add(entry E) {
+	if f.matches(entry) != f.opts.invert {
+		if f.printed && f.skipped && f.hasContext() {
+			f.separator()
+		}
+		for _, e := range f.pending {
+			f.emit(e)
+		}
+		f.pending = f.pending[:0]
+		f.emit(entry)
+		f.printed = true
+		f.skipped = false
+		f.afterLeft = f.opts.after
+		return
+	}
+
+	if f.afterLeft > 0 {
+		f.emit(entry)
+		f.afterLeft--
+		return
+	}
+
+	if f.opts.before == 0 {
+		f.skipped = true
+		return
+	}
+	if len(f.pending) == f.opts.before {
+		f.pending = append(f.pending[:0], f.pending[1:]...)
+		f.skipped = true
+	}
+	f.pending = append(f.pending, entry)
+}
+
+func (f *entryFilter[E]) hasContext() bool {
+	return f.opts.before > 0 || f.opts.after > 0
+}
+
+// entryLine is one line of a log entry along with its detected format
+type entryLine struct {
+	text   string
+	format parser.LogFormat
+}
+
+// lineEntryFilter groups lines into entries using the parser's multi-line
+// tracking and filters them
+type lineEntryFilter struct {
+	filter  *entryFilter[[]entryLine]
+	current []entryLine
+}
+
+func newLineEntryFilter(opts filterOptions, matches func(entryLine) bool, emit func(entryLine), separator func()) *lineEntryFilter {
+	return &lineEntryFilter{
+		filter: newEntryFilter(opts,
+			func(entry []entryLine) bool {
+				return anyLineMatches(entry, matches)
+			},
+			func(entry []entryLine) {
+				for _, line := range entry {
+					emit(line)
+				}
+			},
+			separator,
+		),
+	}
+}
+
+// add appends a line to the current entry, or starts a new entry when the
+// line doesn't continue the current one
+func (f *lineEntryFilter) add(line entryLine, continued bool) {
+	if !continued {
+		f.flush()
+	}
+	f.current = append(f.current, line)
+}
+
+// flush hands the current entry to the filter
+func (f *lineEntryFilter) flush() {
+	if len(f.current) == 0 {
+		return
+	}
+	f.filter.add(f.current)
+	f.current = nil
+}
+
+// anyLineMatches reports whether any line of an entry satisfies matches
+func anyLineMatches(lines []entryLine, matches func(entryLine) bool) bool {
+	for _, line := range lines {
+		if matches(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshi4/splash/colorizer"
+	"github.com/joshi4/splash/parser"
+)
+
+// runLineFilter feeds lines through a lineEntryFilter the way colorizeStream does
+// and returns what would be printed
+func runLineFilter(opts filterOptions, pattern string, lines []string) []string {
+	var out []string
+	filter := newLineEntryFilter(opts,
+		func(line entryLine) bool { return strings.Contains(line.text, pattern) },
+		func(line entryLine) { out = append(out, line.text) },
+		func() { out = append(out, contextSeparator) },
+	)
+
+	p := parser.NewParser()
+	for _, line := range lines {
+		format, continued := p.DetectEntry(line)
+		filter.add(entryLine{text: line, format: format}, continued)
+	}
+	filter.flush()
+	return out
+}
+
+func TestEntryFilter(t *testing.T) {
+	logs := []string{
+		"2025/01/19 08:30:00 INFO: one",
+		"2025/01/19 08:30:01 INFO: two",
+		"2025/01/19 08:30:02 ERROR: three",
+		"2025/01/19 08:30:03 INFO: four",
+		"2025/01/19 08:30:04 INFO: five",
+		"2025/01/19 08:30:05 INFO: six",
+		"2025/01/19 08:30:06 ERROR: seven",
+	}
+
+	tests := []struct {
+		name    string
+		opts    filterOptions
+		pattern string
+		lines   []string
+		want    []string
+	}{
+		{
+			name:    "matches only",
+			pattern: "ERROR",
+			lines:   logs,
+			want:    []string{logs[2], logs[6]},
+		},
+		{
+			name:    "context with separator between groups",
+			opts:    filterOptions{before: 1, after: 1},
+			pattern: "ERROR",
+			lines:   logs,
+			want:    []string{logs[1], logs[2], logs[3], contextSeparator, logs[5], logs[6]},
+		},
+		{
+			name:    "overlapping context isn't repeated",
+			opts:    filterOptions{before: 2, after: 2},
+			pattern: "ERROR",
+			lines:   logs,
+			want:    logs,
+		},
+		{
+			name:    "invert",
+			opts:    filterOptions{invert: true},
+			pattern: "INFO",
+			lines:   logs,
+			want:    []string{logs[2], logs[6]},
+		},
+		{
+			name:    "a matching frame prints the whole stack trace",
+			pattern: "calculate",
+			lines: []string{
+				`{"level":"INFO","message":"Starting application"}`,
+				`Exception in thread "main" java.lang.ArithmeticException: / by zero`,
+				"\tat com.example.MyClass.divide(MyClass.java:10)",
+				"\tat com.example.MyClass.calculate(MyClass.java:6)",
+				"Caused by: java.lang.IllegalStateException: bad input",
+				"\tat com.example.MyClass.main(MyClass.java:3)",
+				`{"level":"ERROR","message":"Application crashed"}`,
+			},
+			want: []string{
+				`Exception in thread "main" java.lang.ArithmeticException: / by zero`,
+				"\tat com.example.MyClass.divide(MyClass.java:10)",
+				"\tat com.example.MyClass.calculate(MyClass.java:6)",
+				"Caused by: java.lang.IllegalStateException: bad input",
+				"\tat com.example.MyClass.main(MyClass.java:3)",
+			},
+		},
+		{
+			name:    "context counts whole entries",
+			opts:    filterOptions{after: 1},
+			pattern: "Starting",
+			lines: []string{
+				`{"level":"INFO","message":"Starting application"}`,
+				"Traceback (most recent call last):",
+				`  File "app.py", line 3, in <module>`,
+				"ValueError: bad value",
+				`{"level":"INFO","message":"Restarted"}`,
+			},
+			want: []string{
+				`{"level":"INFO","message":"Starting application"}`,
+				"Traceback (most recent call last):",
+				`  File "app.py", line 3, in <module>`,
+				"ValueError: bad value",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runLineFilter(tt.opts, tt.pattern, tt.lines)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("filter printed:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to read while a stream writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFilterStreamFlushesTrailingEntry(t *testing.T) {
+	c := colorizer.NewColorizer()
+	c.SetSearchString("bad value")
+	out := &lineWriter{colorizer: c, filter: &filterOptions{}}
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	dest := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() { done <- colorizeStream(context.Background(), pr, "", out, dest) }()
+
+	// The stream stays open, as when following a live log
+	_, _ = io.WriteString(pw, "Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>\nValueError: bad value\n")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(dest.String(), "ValueError") {
+		if time.Now().After(deadline) {
+			t.Fatalf("trailing traceback wasn't flushed, printed %q", dest.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(dest.String(), "Traceback") {
+		t.Errorf("printed %q, want the whole traceback", dest.String())
+	}
+
+	_ = pw.Close()
+	if err := <-done; err != nil {
+		t.Errorf("colorizeStream() error = %v", err)
+	}
+}
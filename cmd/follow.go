@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// followPollInterval is how often a followed file is checked for new data
+const followPollInterval = 250 * time.Millisecond
+
+// follower is an io.Reader over a growing file that behaves like `tail -F`.
+// At end of file it waits for more data instead of returning io.EOF, reopens
+// the path when the file is rotated (renamed and recreated) and starts over
+// when the file is truncated. Read returns io.EOF once ctx is canceled.
+type follower struct {
+	ctx      context.Context
+	path     string
+	file     *os.File
+	offset   int64
+	interval time.Duration
+}
+
+// newFollower opens path and positions it at the start of its last n lines
+func newFollower(ctx context.Context, path string, n int) (*follower, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := seekLastLines(file, n)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &follower{
+		ctx:      ctx,
+		path:     path,
+		file:     file,
+		offset:   offset,
+		interval: followPollInterval,
+	}, nil
+}
+
+func (f *follower) Read(p []byte) (int, error) {
+	for {
+		n, err := f.file.Read(p)
+		if n > 0 {
+			f.offset += int64(n)
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		// At end of file: pick up a rotated or truncated file before waiting
+		reset, err := f.checkRotation()
+		if err != nil {
+			return 0, err
+		}
+		if reset {
+			continue
+		}
+
+		select {
+		case <-f.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(f.interval):
+		}
+	}
+}
+
+// checkRotation reopens the path if it now names a different file and rewinds
+// if the current file shrank. It reports whether the read position was reset.
+func (f *follower) checkRotation() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		// The file is missing between rename and recreate; keep waiting for it
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	current, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	if !os.SameFile(info, current) {
+		file, err := os.Open(f.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		_ = f.file.Close()
+		f.file = file
+		f.offset = 0
+		fmt.Fprintf(os.Stderr, "splash: %s has been replaced; following new file\n", f.path)
+		return true, nil
+	}
+
+	if info.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		f.offset = 0
+		fmt.Fprintf(os.Stderr, "splash: %s: file truncated\n", f.path)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (f *follower) Close() error {
+	return f.file.Close()
+}
+
+// seekLastLines positions file at the start of its last n lines and returns
+// the new offset. A final line without a trailing newline counts as a line.
+func seekLastLines(file *os.File, n int) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+
+	if n <= 0 {
+		return file.Seek(0, io.SeekEnd)
+	}
+
+	const chunkSize = 4096
+	buf := make([]byte, chunkSize)
+	pos := size
+	newlines := 0
+
+	for pos > 0 {
+		readSize := int64(chunkSize)
+		if pos < readSize {
+			readSize = pos
+		}
+		pos -= readSize
+
+		if _, err := file.ReadAt(buf[:readSize], pos); err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		chunk := buf[:readSize]
+		for {
+			i := bytes.LastIndexByte(chunk, '\n')
+			if i < 0 {
+				break
+			}
+			chunk = chunk[:i]
+
+			// The newline that terminates the final line doesn't start a new one
+			if pos+int64(i) == size-1 {
+				continue
+			}
+			newlines++
+			if newlines == n {
+				return file.Seek(pos+int64(i)+1, io.SeekStart)
+			}
+		}
+	}
+
+	return file.Seek(0, io.SeekStart)
+}
+
+// isCompressedFile reports whether the file at path starts like a
+// compressed stream, the way openInput tells
+func isCompressedFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	return isCompressed(bufio.NewReader(file))
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeekLastLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		n       int
+		want    string
+	}{
+		{name: "last two lines", content: "a\nb\nc\n", n: 2, want: "b\nc\n"},
+		{name: "no trailing newline", content: "a\nb\nc", n: 2, want: "b\nc"},
+		{name: "more lines than file", content: "a\nb\n", n: 10, want: "a\nb\n"},
+		{name: "zero lines", content: "a\nb\n", n: 0, want: ""},
+		{name: "empty file", content: "", n: 3, want: ""},
+		{name: "spans chunks", content: strings.Repeat("x", 5000) + "\n" + strings.Repeat("y", 5000) + "\n", n: 1, want: strings.Repeat("y", 5000) + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			if _, err := seekLastLines(file, tt.n); err != nil {
+				t.Fatalf("seekLastLines() error = %v", err)
+			}
+			rest, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			offset, _ := file.Seek(0, io.SeekCurrent)
+			if got := string(rest[offset:]); got != tt.want {
+				t.Errorf("seekLastLines() left %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCompressedFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{name: "bzip2", content: bzip2Hello, want: true},
+		{name: "zstd", content: append([]byte(nil), zstdMagic...), want: true},
+		{name: "plain line starting with the bzip2 magic", content: []byte("BZh ticket opened\n"), want: false},
+		{name: "plain line with the bzip2 magic and a digit", content: []byte("BZh9 rollout started\n"), want: false},
+		{name: "plain text", content: []byte("level=info msg=started\n"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, tt.content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := isCompressedFile(path); got != tt.want {
+				t.Errorf("isCompressedFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowerSurvivesTruncationAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	f, err := newFollower(ctx, path, 1)
+	if err != nil {
+		t.Fatalf("newFollower() error = %v", err)
+	}
+	defer f.Close()
+	f.interval = 10 * time.Millisecond
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-lines:
+			if got != want {
+				t.Fatalf("got line %q, want %q", got, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+	appendLine := func(p, line string) {
+		t.Helper()
+		file, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, err := file.WriteString(line + "\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expect("old 2")
+
+	appendLine(path, "appended")
+	expect("appended")
+
+	// Truncate and write less than was there before
+	if err := os.WriteFile(path, []byte("t\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expect("t")
+
+	// Rename away and recreate, as logrotate does
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendLine(path, "rotated")
+	expect("rotated")
+
+	cancel()
+	for range lines {
+	}
+}
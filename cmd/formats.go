@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshi4/splash/colorizer"
+	"github.com/joshi4/splash/parser"
+)
+
+// formatsCmd represents the formats command
+var formatsCmd = &cobra.Command{
+	Use:   "formats",
+	Short: "List the log formats splash detects",
+	Long: `List every log format splash detects, built-in and user-defined, in the
+order lines are checked against them, along with a sample line of each.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		exitOnError(runFormatsList())
+	},
+}
+
+var formatsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the log formats splash detects",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		exitOnError(runFormatsList())
+	},
+}
+
+var formatsDescribeCmd = &cobra.Command{
+	Use:   "describe name",
+	Short: "Show how a format is detected and what fields it has",
+	Example: `  splash formats describe nginx
+  splash formats describe "go standard"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		logColorizer, err := newColorizerFromFlags()
+		if err == nil {
+			err = describeFormat(os.Stdout, logColorizer, args[0])
+		}
+		exitOnError(err)
+	},
+}
+
+var formatsTestCmd = &cobra.Command{
+	Use:   "test name file...",
+	Short: "Report how many lines of a file are detected as a format",
+	Long: `Report how many lines of each file are detected as a format, how many
+match its pattern on their own, and which lines went to other formats. Use it
+to check a user-defined format against sample logs. Exits with an error when
+no line is detected as the format.`,
+	Example: `  splash formats test payments payments.log
+  splash formats test --config ./formats.yaml payments 'samples/*.log'`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		err := loadConfigOnce()
+		if err == nil {
+			err = testFormat(os.Stdout, args[0], args[1:])
+		}
+		exitOnError(err)
+	},
+}
+
+// exitOnError prints err and exits when it isn't nil
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runFormatsList() error {
+	logColorizer, err := newColorizerFromFlags()
+	if err != nil {
+		return err
+	}
+	listFormats(os.Stdout, logColorizer)
+	return nil
+}
+
+// specificityTier names the band a detector's specificity falls in
+func specificityTier(specificity int) string {
+	switch {
+	case specificity >= 100:
+		return "structured"
+	case specificity >= 70:
+		return "specific"
+	case specificity >= 50:
+		return "regex"
+	default:
+		return "fallback"
+	}
+}
+
+// listFormats writes a line per detector, in rank order, with its sample colorized
+func listFormats(w io.Writer, logColorizer *colorizer.Colorizer) {
+	detectors := parser.NewParser().Detectors()
+	width := 0
+	for _, detector := range detectors {
+		width = max(width, len(detector.Format().String()))
+	}
+
+	for _, detector := range detectors {
+		format := detector.Format()
+		fmt.Fprintf(w, "%-*s  %3d %-10s  %s\n", width, format, detector.Specificity(),
+			specificityTier(detector.Specificity()), colorizedSample(logColorizer, detector))
+	}
+}
+
+// colorizedSample renders a detector's sample line in its format
+func colorizedSample(logColorizer *colorizer.Colorizer, detector parser.FormatDetector) string {
+	describer, ok := detector.(parser.FormatDescriber)
+	if !ok || describer.Sample() == "" {
+		return ""
+	}
+	return logColorizer.ColorizeLog(describer.Sample(), detector.Format())
+}
+
+// lookupFormat finds the format with the given name
+func lookupFormat(name string) (parser.LogFormat, error) {
+	format, ok := parser.FormatByName(name)
+	if !ok {
+		return parser.UnknownFormat, fmt.Errorf("unknown format %q, see `splash formats` for the list", name)
+	}
+	return format, nil
+}
+
+// lookupDetector finds the detector of the format with the given name
+func lookupDetector(name string) (parser.FormatDetector, error) {
+	format, err := lookupFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, detector := range parser.NewParser().Detectors() {
+		if detector.Format() == format {
+			return detector, nil
+		}
+	}
+	return nil, fmt.Errorf("format %s has no detector", format)
+}
+
+// describeFormat writes how a format is detected, a sample and the fields
+// parsed from the sample
+func describeFormat(w io.Writer, logColorizer *colorizer.Colorizer, name string) error {
+	detector, err := lookupDetector(name)
+	if err != nil {
+		return err
+	}
+
+	format := detector.Format()
+	_, multiLine := detector.(parser.StatefulDetector)
+	fmt.Fprintf(w, "Name:           %s\n", format)
+	fmt.Fprintf(w, "Specificity:    %d (%s)\n", detector.Specificity(), specificityTier(detector.Specificity()))
+	fmt.Fprintf(w, "Pattern length: %d\n", detector.PatternLength())
+	fmt.Fprintf(w, "Multi-line:     %s\n", yesNo(multiLine))
+
+	describer, ok := detector.(parser.FormatDescriber)
+	if !ok {
+		return nil
+	}
+	fmt.Fprintln(w, "Pattern:")
+	for _, pattern := range strings.Split(describer.Pattern(), "\n") {
+		fmt.Fprintf(w, "  %s\n", pattern)
+	}
+
+	sample := describer.Sample()
+	if sample == "" {
+		return nil
+	}
+	fmt.Fprintln(w, "Sample:")
+	fmt.Fprintf(w, "  %s\n", logColorizer.ColorizeLog(sample, format))
+
+	fields := parser.Fields(sample, format)
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	width := 0
+	for key := range fields {
+		keys = append(keys, key)
+		width = max(width, len(key))
+	}
+	sort.Strings(keys)
+	fmt.Fprintln(w, "Fields:")
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, key, fields[key])
+	}
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// maxListedMisses caps how many line numbers formats test lists
+const maxListedMisses = 10
+
+// formatTestResult counts how the lines of one input were detected
+type formatTestResult struct {
+	total    int
+	detected int            // lines the parser detected as the format
+	matched  int            // lines the format's detector matches on its own
+	others   map[string]int // lines detected as other formats, by format name
+	misses   []int          // numbers of the first lines not detected as the format
+}
+
+// testFormatInput detects every line of r with a full parser and with the
+// format's detector alone
+func testFormatInput(r io.Reader, detector parser.FormatDetector) (formatTestResult, error) {
+	result := formatTestResult{others: make(map[string]int)}
+	logParser := parser.NewParser()
+	ctx := context.Background()
+
+	scanner := parser.NewLineScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		result.total++
+		if detector.Detect(ctx, line) {
+			result.matched++
+		}
+		if format := logParser.DetectFormat(line); format == detector.Format() {
+			result.detected++
+		} else {
+			result.others[format.String()]++
+			if len(result.misses) < maxListedMisses {
+				result.misses = append(result.misses, result.total)
+			}
+		}
+	}
+	return result, scanner.Err()
+}
+
+// testFormat writes a match report for each input. It fails when no line of
+// any input was detected as the format.
+func testFormat(w io.Writer, name string, args []string) error {
+	detector, err := lookupDetector(name)
+	if err != nil {
+		return err
+	}
+	inputs, err := expandInputs(args)
+	if err != nil {
+		return err
+	}
+
+	detected := 0
+	for _, input := range inputs {
+		r, err := openInput(input)
+		if err != nil {
+			return err
+		}
+		result, err := testFormatInput(r, detector)
+		_ = r.Close()
+		if err != nil {
+			return fmt.Errorf("reading %s: %v", inputLabel(input), err)
+		}
+		writeFormatTestResult(w, inputLabel(input), detector.Format(), result)
+		detected += result.detected
+	}
+
+	if detected == 0 {
+		return fmt.Errorf("no lines were detected as %s", detector.Format())
+	}
+	return nil
+}
+
+func writeFormatTestResult(w io.Writer, label string, format parser.LogFormat, result formatTestResult) {
+	fmt.Fprintf(w, "%s: %d lines\n", label, result.total)
+	fmt.Fprintf(w, "  detected as %s: %s\n", format, rate(result.detected, result.total))
+	fmt.Fprintf(w, "  match its pattern: %s\n", rate(result.matched, result.total))
+
+	if len(result.others) > 0 {
+		names := make([]string, 0, len(result.others))
+		for name := range result.others {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if result.others[names[i]] != result.others[names[j]] {
+				return result.others[names[i]] > result.others[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprintf("%s %d", name, result.others[name])
+		}
+		fmt.Fprintf(w, "  detected as others: %s\n", strings.Join(parts, ", "))
+	}
+
+	if len(result.misses) > 0 {
+		numbers := make([]string, len(result.misses))
+		for i, n := range result.misses {
+			numbers[i] = fmt.Sprint(n)
+		}
+		more := ""
+		if missed := result.total - result.detected; missed > len(result.misses) {
+			more = fmt.Sprintf(" (and %d more)", missed-len(result.misses))
+		}
+		fmt.Fprintf(w, "  lines not detected: %s%s\n", strings.Join(numbers, ", "), more)
+	}
+}
+
+// rate formats n of total as a count and percentage
+func rate(n, total int) string {
+	if total == 0 {
+		return "0/0"
+	}
+	return fmt.Sprintf("%d/%d (%.1f%%)", n, total, 100*float64(n)/float64(total))
+}
+
+func init() {
+	formatsCmd.AddCommand(formatsListCmd, formatsDescribeCmd, formatsTestCmd)
+	rootCmd.AddCommand(formatsCmd)
+}
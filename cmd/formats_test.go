@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/joshi4/splash/colorizer"
+)
+
+func TestDescribeFormat(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	var out bytes.Buffer
+	if err := describeFormat(&out, colorizer.NewColorizer(), "apache-common"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Name:           Apache Common\n",
+		"Specificity:    50 (regex)\n",
+		"Multi-line:     no\n",
+		"  method     GET\n",
+		"  status     200\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("describeFormat() output is missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := describeFormat(&out, colorizer.NewColorizer(), "cobol"); err == nil {
+		t.Error("describeFormat() of an unknown format succeeded")
+	}
+}
+
+func TestTestFormatInput(t *testing.T) {
+	detector, err := lookupDetector("python exception")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := strings.Join([]string{
+		"Traceback (most recent call last):",
+		`  File "app.py", line 3, in <module>`,
+		"ValueError: bad value",
+		`{"level":"info"}`,
+		"plain text",
+	}, "\n")
+	result, err := testFormatInput(strings.NewReader(input), detector)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.total != 5 || result.detected != 3 || result.matched != 2 {
+		t.Errorf("total %d, detected %d, matched %d; want 5, 3, 2", result.total, result.detected, result.matched)
+	}
+	if result.others["JSON"] != 1 || result.others["Unknown"] != 1 {
+		t.Errorf("others = %v, want JSON 1 and Unknown 1", result.others)
+	}
+	if len(result.misses) != 2 || result.misses[0] != 4 || result.misses[1] != 5 {
+		t.Errorf("misses = %v, want [4 5]", result.misses)
+	}
+
+	var out bytes.Buffer
+	writeFormatTestResult(&out, "app.log", detector.Format(), result)
+	want := `app.log: 5 lines
+  detected as Python Exception: 3/5 (60.0%)
+  match its pattern: 2/5 (40.0%)
+  detected as others: JSON 1, Unknown 1
+  lines not detected: 4, 5
+`
+	if out.String() != want {
+		t.Errorf("report =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// stdinName is the file argument that selects standard input
+const stdinName = "-"
+
+// Magic bytes used to detect compressed inputs
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh")
+
+	// bzip2BlockMagics follow bzip2Magic and the block size digit: the magic
+	// of the first block, or of the end of the stream when it is empty
+	bzip2BlockMagics = [][]byte{
+		{0x31, 0x41, 0x59, 0x26, 0x53, 0x59},
+		{0x17, 0x72, 0x45, 0x38, 0x50, 0x90},
+	}
+)
+
+// expandInputs resolves file arguments into the ordered list of inputs to read.
+// Arguments containing glob metacharacters are expanded, "-" selects stdin and
+// no arguments at all means stdin.
+func expandInputs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{stdinName}, nil
+	}
+
+	var inputs []string
+	for _, arg := range args {
+		if arg == stdinName || !hasGlobMeta(arg) {
+			inputs = append(inputs, arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %v", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", arg)
+		}
+		inputs = append(inputs, matches...)
+	}
+	return inputs, nil
+}
+
+// hasGlobMeta reports whether the argument contains glob metacharacters
+func hasGlobMeta(arg string) bool {
+	for i := 0; i < len(arg); i++ {
+		switch arg[i] {
+		case '*', '?', '[':
+			return true
+		}
+	}
+	return false
+}
+
+// inputLabel returns the name shown in the source prefix for an input
+func inputLabel(name string) string {
+	if name == stdinName {
+		return "stdin"
+	}
+	return name
+}
+
+// openInput opens a named input for reading, transparently decompressing it
+func openInput(name string) (io.ReadCloser, error) {
+	if name == stdinName {
+		// Stdin is left open so later inputs named "-" still see EOF cleanly
+		return decompress(os.Stdin, nil)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := decompress(file, file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return rc, nil
+}
+
+// decompress wraps r in a decompressor chosen by the stream's magic bytes.
+// Uncompressed input is passed through unchanged. Closing the returned
+// reader also closes underlying when it is not nil.
+func decompress(r io.Reader, underlying io.Closer) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+
+	switch {
+	case hasMagic(br, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &inputReader{Reader: gz, closers: []io.Closer{gz, underlying}}, nil
+	case hasMagic(br, zstdMagic):
+		dec, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		zr := dec.IOReadCloser()
+		return &inputReader{Reader: zr, closers: []io.Closer{zr, underlying}}, nil
+	case hasBzip2Magic(br):
+		return &inputReader{Reader: bzip2Reader{bzip2.NewReader(br)}, closers: []io.Closer{underlying}}, nil
+	default:
+		return &inputReader{Reader: br, closers: []io.Closer{underlying}}, nil
+	}
+}
+
+// isCompressed reports whether the buffered stream starts like one of the
+// compressed formats decompress reads
+func isCompressed(br *bufio.Reader) bool {
+	return hasMagic(br, gzipMagic) || hasMagic(br, zstdMagic) || hasBzip2Magic(br)
+}
+
+// hasMagic reports whether the buffered stream starts with magic
+func hasMagic(br *bufio.Reader, magic []byte) bool {
+	return hasMagicAt(br, 0, magic)
+}
+
+// hasMagicAt reports whether magic is at offset of the buffered stream. It
+// peeks one byte at a time so a short line on an interactive pipe is never
+// held back waiting for bytes that could only belong to a compressed stream.
+func hasMagicAt(br *bufio.Reader, offset int, magic []byte) bool {
+	for i := range magic {
+		peeked, err := br.Peek(offset + i + 1)
+		if err != nil || peeked[offset+i] != magic[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasBzip2Magic reports whether the buffered stream starts like a bzip2
+// stream: "BZh", a block size from '1' to '9' and a block magic. "BZh" alone
+// is too likely at the start of a plain text log.
+func hasBzip2Magic(br *bufio.Reader) bool {
+	if !hasMagic(br, bzip2Magic) {
+		return false
+	}
+	peeked, err := br.Peek(len(bzip2Magic) + 1)
+	if err != nil || peeked[len(bzip2Magic)] < '1' || peeked[len(bzip2Magic)] > '9' {
+		return false
+	}
+	for _, magic := range bzip2BlockMagics {
+		if hasMagicAt(br, len(bzip2Magic)+1, magic) {
+			return true
+		}
+	}
+	return false
+}
+
+// bzip2Reader says that the input was read as bzip2 when decompressing it
+// fails, as compress/bzip2 reports a truncated stream as a bare unexpected EOF
+type bzip2Reader struct {
+	r io.Reader
+}
+
+func (r bzip2Reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("decompressing bzip2: %w", err)
+	}
+	return n, err
+}
+
+// inputReader reads from a (possibly decompressed) input and closes every
+// layer beneath it on Close
+type inputReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *inputReader) Close() error {
+	var firstErr error
+	for _, c := range r.closers {
+		if c == nil {
+			continue
+		}
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
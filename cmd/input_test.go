@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// bzip2Hello is "hello\n" compressed with bzip2 (the standard library has no bzip2 writer)
+var bzip2Hello = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xc1, 0xc0,
+	0x80, 0xe2, 0x00, 0x00, 0x01, 0x41, 0x00, 0x00, 0x10, 0x02, 0x44, 0xa0,
+	0x00, 0x30, 0xcd, 0x00, 0xc3, 0x46, 0x29, 0x97, 0x17, 0x72, 0x45, 0x38,
+	0x50, 0x90, 0xc1, 0xc0, 0x80, 0xe2,
+}
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	return enc.EncodeAll([]byte(data), nil)
+}
+
+func TestDecompressByMagicBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{name: "plain", input: []byte("hello\n"), want: "hello\n"},
+		{name: "gzip", input: gzipBytes(t, "hello\n"), want: "hello\n"},
+		{name: "zstd", input: zstdBytes(t, "hello\n"), want: "hello\n"},
+		{name: "bzip2", input: bzip2Hello, want: "hello\n"},
+		{name: "short plain line starting like bzip2", input: []byte("BZ\n"), want: "BZ\n"},
+		{name: "plain line starting with the bzip2 magic", input: []byte("BZh ticket opened\n"), want: "BZh ticket opened\n"},
+		{name: "plain line with the bzip2 magic and a digit", input: []byte("BZh9 rollout started\n"), want: "BZh9 rollout started\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, err := decompress(bytes.NewReader(tt.input), nil)
+			if err != nil {
+				t.Fatalf("decompress() error = %v", err)
+			}
+			defer rc.Close()
+
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("decompress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecompressCorruptBzip2(t *testing.T) {
+	rc, err := decompress(bytes.NewReader(bzip2Hello[:len(bzip2Hello)/2]), nil)
+	if err != nil {
+		t.Fatalf("decompress() error = %v", err)
+	}
+	defer rc.Close()
+
+	_, err = io.ReadAll(rc)
+	if err == nil || !strings.Contains(err.Error(), "bzip2") {
+		t.Errorf("ReadAll() error = %v, want one naming bzip2", err)
+	}
+}
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.log", "a.log", "c.log.gz"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := expandInputs([]string{filepath.Join(dir, "c.log.gz"), filepath.Join(dir, "*.log"), "-"})
+	if err != nil {
+		t.Fatalf("expandInputs() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "c.log.gz"),
+		filepath.Join(dir, "a.log"),
+		filepath.Join(dir, "b.log"),
+		"-",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expandInputs() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expandInputs()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got, _ := expandInputs(nil); len(got) != 1 || got[0] != stdinName {
+		t.Errorf("expandInputs(nil) = %v, want [-]", got)
+	}
+
+	if _, err := expandInputs([]string{filepath.Join(dir, "*.zst")}); err == nil {
+		t.Error("expandInputs() with an unmatched glob should fail")
+	}
+}
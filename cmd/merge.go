@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joshi4/splash/parser"
+)
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge file...",
+	Short: "Interleave log files into one chronological stream",
+	Long: `Interleave several log files into one chronological stream.
+
+Each entry's timestamp is parsed according to its detected format, so files in
+different formats (JSON, Go standard, Nginx, ...) can be merged together. Lines
+without a timestamp, such as stack trace frames, stay attached to the entry
+they follow. Every line is tagged with a colored label naming its source.
+
+Each file is expected to be in chronological order already.`,
+	Example: `  splash merge api.log worker.log nginx.log
+  splash merge 'logs/*.log.gz' -s "request_id=42"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runMerge(args); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// runMerge merges the inputs named in args by timestamp and writes them to stdout
+func runMerge(args []string) error {
+	inputs, err := expandInputs(args)
+	if err != nil {
+		return err
+	}
+
+	logColorizer, err := newColorizerFromFlags()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	out, err := newLineWriter(logColorizer)
+	if err != nil {
+		return err
+	}
+
+	prefixes := sourcePrefixes(inputs, logColorizer)
+	sources := make([]*mergeSource, 0, len(inputs))
+	for i, name := range inputs {
+		r, err := openInput(name)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		sources = append(sources, newMergeSource(i, name, r, out.parserOpts...))
+	}
+
+	emit := func(entry *mergeEntry) {
+		for _, line := range entry.lines {
+			out.writeLine(prefixes[entry.source], line.text, line.format)
+		}
+	}
+	if out.filter != nil {
+		filter := newEntryFilter(*out.filter,
+			func(entry *mergeEntry) bool { return anyLineMatches(entry.lines, out.matches) },
+			emit,
+			func() { out.writeSeparatorTo(os.Stdout) },
+		)
+		emit = filter.add
+	}
+
+	if err := mergeEntries(ctx, sources, emit); err != nil {
+		return err
+	}
+
+	CheckForUpgradesOnExit()
+	return nil
+}
+
+// mergeEntry is a timestamped line followed by the lines without a timestamp
+// that belong to it, such as the frames of a stack trace
+type mergeEntry struct {
+	time   time.Time
+	source int
+	seq    int
+	lines  []entryLine
+}
+
+// mergeSource reads one input as a sequence of entries
+type mergeSource struct {
+	index   int
+	name    string
+	scanner *parser.LineScanner
+	parser  *parser.Parser
+	pending *entryLine // the timestamped line that starts the next entry
+	last    time.Time  // timestamp of the previous entry
+	seq     int
+}
+
+func newMergeSource(index int, name string, r io.Reader, opts ...parser.Option) *mergeSource {
+	return &mergeSource{
+		index:   index,
+		name:    name,
+		scanner: parser.NewLineScanner(r),
+		parser:  parser.NewParser(opts...),
+	}
+}
+
+// next returns the source's next entry, or nil at the end of the input.
+// Lines before the first timestamp form an entry stamped with the zero time.
+func (s *mergeSource) next() (*mergeEntry, error) {
+	entry := &mergeEntry{time: s.last, source: s.index, seq: s.seq}
+	s.seq++
+
+	if s.pending != nil {
+		entry.lines = append(entry.lines, *s.pending)
+		s.pending = nil
+	}
+
+	for s.scanner.Scan() {
+		text := s.scanner.Text()
+		line := entryLine{text: text, format: s.parser.DetectFormat(text)}
+
+		t, ok := parser.ParseTimestamp(text, line.format)
+		if !ok {
+			entry.lines = append(entry.lines, line)
+			continue
+		}
+
+		if len(entry.lines) == 0 {
+			entry.time = t
+			entry.lines = append(entry.lines, line)
+			continue
+		}
+
+		// This line starts the next entry
+		s.pending = &line
+		s.last = t
+		return entry, nil
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", inputLabel(s.name), err)
+	}
+	if len(entry.lines) == 0 {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// mergeEntries repeatedly emits the earliest pending entry across sources.
+// Entries with equal timestamps keep the order of their sources on the
+// command line, and entries from the same source keep their file order.
+func mergeEntries(ctx context.Context, sources []*mergeSource, emit func(*mergeEntry)) error {
+	queue := &entryQueue{}
+	for _, s := range sources {
+		entry, err := s.next()
+		if err != nil {
+			return err
+		}
+		if entry != nil {
+			heap.Push(queue, entry)
+		}
+	}
+
+	for queue.Len() > 0 {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		entry := heap.Pop(queue).(*mergeEntry)
+		emit(entry)
+
+		next, err := sources[entry.source].next()
+		if err != nil {
+			return err
+		}
+		if next != nil {
+			heap.Push(queue, next)
+		}
+	}
+	return nil
+}
+
+// entryQueue is a min-heap of entries ordered by time, then source, then sequence
+type entryQueue []*mergeEntry
+
+func (q entryQueue) Len() int { return len(q) }
+
+func (q entryQueue) Less(i, j int) bool {
+	if !q[i].time.Equal(q[j].time) {
+		return q[i].time.Before(q[j].time)
+	}
+	if q[i].source != q[j].source {
+		return q[i].source < q[j].source
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q entryQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *entryQueue) Push(x any) { *q = append(*q, x.(*mergeEntry)) }
+
+func (q *entryQueue) Pop() any {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	*q = old[:n-1]
+	return entry
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMergeEntriesOrdersByTimestamp(t *testing.T) {
+	api := `{"time":"2025-01-19T08:30:05Z","level":"INFO","msg":"api up"}
+{"time":"2025-01-19T08:30:20Z","level":"ERROR","msg":"api failed"}
+`
+	worker := `2025-01-19T08:30:10.000Z INFO worker started
+2025-01-19T08:30:15.000Z ERROR worker crashed
+Exception in thread "main" java.lang.IllegalStateException: boom
+	at com.example.Worker.run(Worker.java:42)
+2025-01-19T08:30:30.000Z INFO worker restarted
+`
+	nginx := `127.0.0.1 - - [19/Jan/2025:08:30:12 +0000] "GET / HTTP/1.1" 200 1234 "-" "curl"
+`
+
+	sources := []*mergeSource{
+		newMergeSource(0, "api.log", strings.NewReader(api)),
+		newMergeSource(1, "worker.log", strings.NewReader(worker)),
+		newMergeSource(2, "nginx.log", strings.NewReader(nginx)),
+	}
+
+	var got []string
+	err := mergeEntries(context.Background(), sources, func(entry *mergeEntry) {
+		for _, line := range entry.lines {
+			got = append(got, sources[entry.source].name+": "+line.text)
+		}
+	})
+	if err != nil {
+		t.Fatalf("mergeEntries() error = %v", err)
+	}
+
+	want := []string{
+		`api.log: {"time":"2025-01-19T08:30:05Z","level":"INFO","msg":"api up"}`,
+		`worker.log: 2025-01-19T08:30:10.000Z INFO worker started`,
+		`nginx.log: 127.0.0.1 - - [19/Jan/2025:08:30:12 +0000] "GET / HTTP/1.1" 200 1234 "-" "curl"`,
+		`worker.log: 2025-01-19T08:30:15.000Z ERROR worker crashed`,
+		`worker.log: Exception in thread "main" java.lang.IllegalStateException: boom`,
+		`worker.log: 	at com.example.Worker.run(Worker.java:42)`,
+		`api.log: {"time":"2025-01-19T08:30:20Z","level":"ERROR","msg":"api failed"}`,
+		`worker.log: 2025-01-19T08:30:30.000Z INFO worker restarted`,
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("mergeEntries() emitted %d lines, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMergeSourceKeepsLeadingLinesWithoutTimestamp(t *testing.T) {
+	s := newMergeSource(0, "app.log", strings.NewReader("preamble\n2025-01-19T08:30:10.000Z INFO started\n"))
+
+	first, err := s.next()
+	if err != nil || first == nil {
+		t.Fatalf("next() = %v, %v", first, err)
+	}
+	if !first.time.IsZero() || len(first.lines) != 1 || first.lines[0].text != "preamble" {
+		t.Errorf("first entry = %+v, want the untimestamped preamble at the zero time", first)
+	}
+
+	second, err := s.next()
+	if err != nil || second == nil || second.time.IsZero() {
+		t.Fatalf("second entry = %+v, %v; want a timestamped entry", second, err)
+	}
+
+	if last, err := s.next(); err != nil || last != nil {
+		t.Errorf("next() at end = %v, %v; want nil, nil", last, err)
+	}
+}
@@ -1,13 +1,16 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
@@ -15,17 +18,25 @@ import (
 
 	"github.com/joshi4/splash/colorizer"
 	"github.com/joshi4/splash/parser"
+	"github.com/joshi4/splash/query"
 )
 
 // Command flags
 var (
-	searchPattern string
-	regexPattern  string
-	lightTheme    bool
-	darkTheme     bool
-	noColor       bool
+	lightTheme  bool
+	darkTheme   bool
+	noColor     bool
+	showPrefix  bool
+	follow      bool
+	followLines int
+	formatName  string
+	autoLock    int
 )
 
+// autoLockMisses is how many lines in a row the locked format's detector must
+// reject before --auto-lock resumes detection
+const autoLockMisses = 3
+
 // createSplashHeader creates a colorful SPLASH header using log colors
 func createSplashHeader() string {
 	theme := colorizer.NewAdaptiveTheme()
@@ -128,21 +139,41 @@ func createColorizerWithTheme() *colorizer.Colorizer {
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "splash",
+	Use:   "splash [file...]",
 	Short: "Add color to your logs",
 	Long:  createSplashHeader() + "\nSplash transforms streams of boring plaintext into colorful and easy to read logs.\nUse Splash to easily scan and debug issues from your logs.\n\nSupported formats: JSON, Logfmt, Syslog, Apache, Nginx, Rails, Docker,\nKubernetes, Heroku, Go standard logs, and more.",
 	Example: `  tail -f /var/log/app.log | splash
   docker logs mycontainer | splash
   kubectl logs pod-name | splash -s "ERROR"
-  cat access.log | splash -r "[45]\d\d"`,
-	Run: func(cmd *cobra.Command, _ []string) {
-		// If stdin is not a pipe and no search flags are provided, show usage
-		if !isStdinFromPipe() && searchPattern == "" && regexPattern == "" {
+  cat access.log | splash -r "[45]\d\d"
+  splash app.log 'archive/*.log.gz' other.log.zst
+  splash --prefix web.log worker.log
+  splash -f -n 50 /var/log/app.log
+  splash -- go test ./...
+  splash --pty -- make build
+  splash --filter -C 2 -s "OutOfMemoryError" app.log`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Everything after "--" is a command to run and colorize
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			if dash > 0 {
+				fmt.Fprintf(os.Stderr, "cannot combine file arguments with a wrapped command\n")
+				os.Exit(1)
+			}
+			if len(args) == 0 {
+				fmt.Fprintf(os.Stderr, "missing command after --\n")
+				os.Exit(1)
+			}
+			os.Exit(runWrapped(args))
+		}
+
+		// If there are no files, stdin is not a pipe and no search flags are provided, show usage
+		if len(args) == 0 && !isStdinFromPipe() && len(searchTerms) == 0 && whereExpr == "" {
 			_ = cmd.Help()
 			return
 		}
 
-		if err := runSplash(); err != nil {
+		if err := runSplash(args); err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
@@ -167,8 +198,14 @@ func isStdinFromPipe() bool {
 	return (stat.Mode() & os.ModeCharDevice) == 0
 }
 
-// runSplash is the main function that reads from stdin and writes to stdout
-func runSplash() error {
+// newColorizerFromFlags sets up the color profile and returns a colorizer
+// configured with the theme and search flags shared by every command
+func newColorizerFromFlags() (*colorizer.Colorizer, error) {
+	// User-defined formats must be registered before any parser is created
+	if err := loadConfigOnce(); err != nil {
+		return nil, err
+	}
+
 	// Handle color profile and theme detection
 	if noColor {
 		lipgloss.SetColorProfile(termenv.Ascii)
@@ -178,9 +215,23 @@ func runSplash() error {
 		lipgloss.SetColorProfile(termenv.TrueColor)
 	}
 
-	// Create a context that will be canceled when we receive a signal
+	// Create colorizer with theme detection
+	logColorizer := createColorizerWithTheme()
+
+	// Set search patterns if provided
+	if err := addSearchTerms(logColorizer, searchTerms); err != nil {
+		return nil, err
+	}
+	if legend := logColorizer.SearchLegend(); legend != "" {
+		fmt.Fprintln(os.Stderr, legend)
+	}
+
+	return logColorizer, nil
+}
+
+// newSignalContext returns a context that is canceled on SIGINT, SIGTERM or SIGHUP
+func newSignalContext() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
 	// Set up signal handling
 	sigChan := make(chan os.Signal, 1)
@@ -188,53 +239,83 @@ func runSplash() error {
 
 	// Start a goroutine to handle signals
 	go func() {
-		<-sigChan
-		cancel()
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigChan)
 	}()
 
-	// Create optimized parser and colorizer with theme detection
-	logParser := parser.NewParser()
-	logColorizer := createColorizerWithTheme()
+	return ctx, cancel
+}
 
-	// Set search patterns if provided
-	if searchPattern != "" && regexPattern != "" {
-		return fmt.Errorf("cannot use both --search and --regexp flags simultaneously")
+// runSplash is the main function that reads the inputs named in args (or stdin) and writes to stdout
+func runSplash(args []string) error {
+	inputs, err := expandInputs(args)
+	if err != nil {
+		return err
 	}
 
-	if searchPattern != "" {
-		logColorizer.SetSearchString(searchPattern)
-	} else if regexPattern != "" {
-		err := logColorizer.SetSearchRegex(regexPattern)
-		if err != nil {
-			return fmt.Errorf("invalid regex pattern: %v", err)
-		}
+	logColorizer, err := newColorizerFromFlags()
+	if err != nil {
+		return err
 	}
 
-	// Read from stdin and write to stdout
-	scanner := bufio.NewScanner(os.Stdin)
+	// Create a context that will be canceled when we receive a signal
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	// Source prefixes are only shown when reading more than one input
+	prefixes := make([]string, len(inputs))
+	if showPrefix && len(inputs) > 1 {
+		prefixes = sourcePrefixes(inputs, logColorizer)
+	}
+
+	out, err := newLineWriter(logColorizer)
+	if err != nil {
+		return err
+	}
 
 	// Channel to signal when reading is done
 	done := make(chan bool)
+	var failed atomic.Int32
+
+	readInput := func(i int, name string) {
+		var err error
+		if follow && name != stdinName && !isCompressedFile(name) {
+			err = followInput(ctx, name, prefixes[i], out)
+		} else {
+			err = colorizeInput(ctx, name, prefixes[i], out)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", inputLabel(name), err)
+			failed.Add(1)
+		}
+	}
 
 	go func() {
 		defer close(done)
-		for scanner.Scan() {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				line := scanner.Text()
-				// Detect log format for this line using optimized parser
-				format := logParser.DetectFormat(line)
-				// Apply colors based on detected format
-				colorizedLine := logColorizer.ColorizeLog(line, format)
-				fmt.Println(colorizedLine)
+
+		// Followed inputs never end on their own, so they are read concurrently
+		if follow {
+			var wg sync.WaitGroup
+			for i, name := range inputs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					readInput(i, name)
+				}()
 			}
+			wg.Wait()
+			return
 		}
 
-		// Check for scanner errors
-		if err := scanner.Err(); err != nil && err != io.EOF {
-			fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
+		for i, name := range inputs {
+			if ctx.Err() != nil {
+				return
+			}
+			readInput(i, name)
 		}
 	}()
 
@@ -247,17 +328,236 @@ func runSplash() error {
 	case <-done:
 		// Check for upgrades before normal exit
 		CheckForUpgradesOnExit()
+		if n := failed.Load(); n > 0 {
+			return fmt.Errorf("failed to read %d of %d inputs", n, len(inputs))
+		}
 		return nil
 	}
 }
 
+// lineWriter colorizes and prints lines, serializing output from inputs read concurrently
+type lineWriter struct {
+	mu         sync.Mutex
+	colorizer  *colorizer.Colorizer
+	where      *query.Query   // nil without --where
+	filter     *filterOptions // nil unless only matching entries are printed
+	parserOpts []parser.Option
+}
+
+// newLineWriter returns a lineWriter for the colorizer configured by the
+// --where and filter flags
+func newLineWriter(logColorizer *colorizer.Colorizer) (*lineWriter, error) {
+	out := &lineWriter{colorizer: logColorizer}
+
+	parserOpts, err := parserOptionsFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	out.parserOpts = parserOpts
+
+	where, err := whereQueryFromFlags()
+	if err != nil {
+		return nil, err
+	}
+	out.where = where
+
+	opts, ok, err := filterOptionsFromFlags(logColorizer.HasSearch() || where != nil)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		out.filter = &opts
+	}
+	return out, nil
+}
+
+// parserOptionsFromFlags returns the parser options set by --format and --auto-lock
+func parserOptionsFromFlags() ([]parser.Option, error) {
+	var opts []parser.Option
+	if formatName != "" {
+		format, err := lookupFormat(formatName)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, parser.WithFormat(format))
+	}
+	if autoLock < 0 {
+		return nil, fmt.Errorf("--auto-lock must not be negative")
+	}
+	if autoLock > 0 {
+		opts = append(opts, parser.WithAutoLock(autoLock, autoLockMisses))
+	}
+	return opts, nil
+}
+
+// writeLine colorizes a line in its detected format and prints it to stdout after prefix
+func (w *lineWriter) writeLine(prefix, line string, format parser.LogFormat) {
+	w.writeLineTo(os.Stdout, prefix, line, format)
+}
+
+// writeLineTo colorizes a line in its detected format and prints it to dest after prefix.
+// Without a filter, lines are marked in a gutter by whether they satisfy --where.
+func (w *lineWriter) writeLineTo(dest io.Writer, prefix, line string, format parser.LogFormat) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.where != nil && w.filter == nil {
+		prefix = w.colorizer.MatchGutter(w.where.Match(parser.Fields(line, format))) + prefix
+	}
+	fmt.Fprintln(dest, prefix+w.colorizer.ColorizeLog(line, format))
+}
+
+// matches reports whether a line satisfies --where and contains a search
+// match, for whichever of the two are set
+func (w *lineWriter) matches(line entryLine) bool {
+	if w.where != nil && !w.where.Match(parser.Fields(line.text, line.format)) {
+		return false
+	}
+	if w.colorizer.HasSearch() && !w.colorizer.MatchesSearch(line.text) {
+		return false
+	}
+	return true
+}
+
+// writeSeparatorTo prints the separator between non-adjacent groups of filtered entries
+func (w *lineWriter) writeSeparatorTo(dest io.Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintln(dest, contextSeparator)
+}
+
+// colorizeInput reads one input to the end and writes each colorized line to stdout
+func colorizeInput(ctx context.Context, name, prefix string, out *lineWriter) error {
+	r, err := openInput(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return colorizeStream(ctx, r, prefix, out, os.Stdout)
+}
+
+// followInput writes the last lines of a file and then every line appended to it,
+// following the file across rotation and truncation until ctx is canceled
+func followInput(ctx context.Context, name, prefix string, out *lineWriter) error {
+	f, err := newFollower(ctx, name, followLines)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return colorizeStream(ctx, f, prefix, out, os.Stdout)
+}
+
+// colorizeStream reads r line by line and writes each colorized line to dest.
+// Every stream gets its own parser so stateful detection never spans inputs,
+// while a followed file keeps its multi-line state across reads and rotations.
+func colorizeStream(ctx context.Context, r io.Reader, prefix string, out *lineWriter, dest io.Writer) error {
+	if out.filter != nil {
+		return filterStream(ctx, r, prefix, out, dest)
+	}
+
+	logParser := parser.NewParser(out.parserOpts...)
+	scanner := parser.NewLineScanner(r)
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			line := scanner.Text()
+			// Detect log format for this line using optimized parser
+			format := logParser.DetectFormat(line)
+			// Apply colors based on detected format
+			out.writeLineTo(dest, prefix, line, format)
+		}
+	}
+
+	// Check for scanner errors
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// entryFlushTimeout is how long a filtered stream waits for more lines of an
+// entry before deciding whether to print what it has
+const entryFlushTimeout = 250 * time.Millisecond
+
+// filterStream is colorizeStream with a filter. Lines are grouped into
+// entries so a match anywhere in a stack trace prints the whole trace, and
+// a trace at the end of a live stream is flushed rather than held until the
+// next line arrives.
+func filterStream(ctx context.Context, r io.Reader, prefix string, out *lineWriter, dest io.Writer) error {
+	entries := parser.NewEntryReader(r, out.parserOpts...)
+	entries.SetFlushTimeout(entryFlushTimeout)
+	defer entries.Close()
+
+	filter := newLineEntryFilter(*out.filter, out.matches,
+		func(line entryLine) { out.writeLineTo(dest, prefix, line.text, line.format) },
+		func() { out.writeSeparatorTo(dest) },
+	)
+	for ctx.Err() == nil {
+		entry, err := entries.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for i, text := range entry.Lines {
+			filter.add(entryLine{text: text, format: entry.Format}, i > 0)
+		}
+		filter.flush()
+	}
+	return nil
+}
+
+// sourcePrefixes builds the colored, aligned source name prefix for each input
+func sourcePrefixes(inputs []string, logColorizer *colorizer.Colorizer) []string {
+	width := 0
+	for _, name := range inputs {
+		width = max(width, len(inputLabel(name)))
+	}
+
+	prefixes := make([]string, len(inputs))
+	for i, name := range inputs {
+		label := inputLabel(name)
+		padding := strings.Repeat(" ", width-len(label))
+		prefixes[i] = logColorizer.ColorizeSourceLabel(label, i) + padding + " | "
+	}
+	return prefixes
+}
+
 func init() {
 	// Search flags
-	rootCmd.Flags().StringVarP(&searchPattern, "search", "s", "", "search for all instances of a string")
-	rootCmd.Flags().StringVarP(&regexPattern, "regexp", "r", "", "search for text that matches a regexp")
+	rootCmd.PersistentFlags().VarP(&searchFlag{}, "search", "s", "search for all instances of a string (repeatable)")
+	rootCmd.PersistentFlags().VarP(&searchFlag{regex: true}, "regexp", "r", "search for text that matches a regexp (repeatable)")
+	rootCmd.PersistentFlags().BoolVarP(&ignoreCase, "ignore-case", "i", false, "search case-insensitively")
+	rootCmd.PersistentFlags().BoolVarP(&smartCase, "smart-case", "S", false, "search case-insensitively unless a pattern contains uppercase")
+	rootCmd.PersistentFlags().BoolVarP(&wordMatch, "word", "w", false, "only match search patterns as whole words")
 
 	// Theme flags
-	rootCmd.Flags().BoolVar(&lightTheme, "light", false, "force light theme colors (for light terminal backgrounds)")
-	rootCmd.Flags().BoolVar(&darkTheme, "dark", false, "force dark theme colors (for dark terminal backgrounds)")
-	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "disable all colors")
+	rootCmd.PersistentFlags().BoolVar(&lightTheme, "light", false, "force light theme colors (for light terminal backgrounds)")
+	rootCmd.PersistentFlags().BoolVar(&darkTheme, "dark", false, "force dark theme colors (for dark terminal backgrounds)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable all colors")
+
+	// Format flags
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "read user-defined formats from this file (default "+displayConfigPath()+")")
+	rootCmd.PersistentFlags().StringVar(&formatName, "format", "", "treat every line as this format instead of detecting it, e.g. nginx")
+	rootCmd.PersistentFlags().IntVar(&autoLock, "auto-lock", 0, "lock onto a format once N lines in a row are detected as it, e.g. 10")
+
+	// Filter flags
+	rootCmd.PersistentFlags().StringVar(&whereExpr, "where", "", "mark entries whose fields satisfy an expression, e.g. 'level == error && status >= 500'")
+	rootCmd.PersistentFlags().BoolVar(&filterMode, "filter", false, "print only entries that match --search, --regexp or --where")
+	rootCmd.PersistentFlags().BoolVar(&invertMatch, "invert", false, "print only entries that don't match (implies --filter)")
+	rootCmd.PersistentFlags().IntVarP(&afterContext, "after-context", "A", 0, "print NUM entries after each match (implies --filter)")
+	rootCmd.PersistentFlags().IntVarP(&beforeContext, "before-context", "B", 0, "print NUM entries before each match (implies --filter)")
+	rootCmd.PersistentFlags().IntVarP(&bothContext, "context", "C", 0, "print NUM entries before and after each match (implies --filter)")
+
+	// Input flags
+	rootCmd.Flags().BoolVar(&showPrefix, "prefix", false, "prefix each line with its source file name when reading multiple files")
+	rootCmd.Flags().BoolVarP(&follow, "follow", "f", false, "keep reading files as they grow, following rotation and truncation like tail -F")
+	rootCmd.Flags().IntVarP(&followLines, "lines", "n", 10, "number of trailing lines to show before following (with --follow)")
+
+	// Wrap mode flags
+	rootCmd.Flags().BoolVar(&usePTY, "pty", false, "run a wrapped command under a pseudo-terminal so it keeps line buffering")
 }
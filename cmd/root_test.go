@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAutoLockTakesSeparateValue(t *testing.T) {
+	defer func() { autoLock = 0 }()
+
+	for _, args := range [][]string{
+		{"--auto-lock", "5", "app.log"},
+		{"--auto-lock=5", "app.log"},
+	} {
+		autoLock = 0
+		flags := rootCmd.Flags()
+		if err := rootCmd.ParseFlags(args); err != nil {
+			t.Fatalf("ParseFlags(%q) error = %v", args, err)
+		}
+		if autoLock != 5 {
+			t.Errorf("ParseFlags(%q): --auto-lock = %d, want 5", args, autoLock)
+		}
+		if got := flags.Args(); !slices.Equal(got, []string{"app.log"}) {
+			t.Errorf("ParseFlags(%q): args = %q, want only app.log", args, got)
+		}
+	}
+}
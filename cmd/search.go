@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joshi4/splash/colorizer"
+)
+
+// searchTerm is one -s or -r pattern from the command line
+type searchTerm struct {
+	pattern string
+	regex   bool
+}
+
+// Search flags. -s and -r append to the same list so patterns keep the order
+// they were given in, which decides their colors and which wins an overlap.
+var searchTerms []searchTerm
+
+// Search option flags, applied to every pattern
+var (
+	ignoreCase bool
+	smartCase  bool
+	wordMatch  bool
+)
+
+// searchFlag is a repeatable flag that appends its values to searchTerms
+type searchFlag struct {
+	regex bool
+}
+
+func (f *searchFlag) String() string {
+	var values []string
+	for _, term := range searchTerms {
+		if term.regex == f.regex {
+			values = append(values, term.pattern)
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
+
+func (f *searchFlag) Set(value string) error {
+	searchTerms = append(searchTerms, searchTerm{pattern: value, regex: f.regex})
+	return nil
+}
+
+func (f *searchFlag) Type() string {
+	return "stringArray"
+}
+
+// addSearchTerms adds the search flags to the colorizer in command line order
+func addSearchTerms(logColorizer *colorizer.Colorizer, terms []searchTerm) error {
+	err := logColorizer.SetSearchOptions(colorizer.SearchOptions{
+		IgnoreCase: ignoreCase,
+		SmartCase:  smartCase,
+		Word:       wordMatch,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, term := range terms {
+		if !term.regex {
+			logColorizer.AddSearchString(term.pattern)
+			continue
+		}
+		if err := logColorizer.AddSearchRegex(term.pattern); err != nil {
+			return fmt.Errorf("invalid regex pattern %q: %v", term.pattern, err)
+		}
+	}
+	return nil
+}
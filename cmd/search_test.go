@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestSearchFlagsKeepCommandLineOrder(t *testing.T) {
+	defer func() { searchTerms = nil }()
+	searchTerms = nil
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.VarP(&searchFlag{}, "search", "s", "")
+	flags.VarP(&searchFlag{regex: true}, "regexp", "r", "")
+
+	if err := flags.Parse([]string{"-s", "user_42", "-r", "timeout|deadline", "--search", "10.0.0.5"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []searchTerm{
+		{pattern: "user_42"},
+		{pattern: "timeout|deadline", regex: true},
+		{pattern: "10.0.0.5"},
+	}
+	if !reflect.DeepEqual(searchTerms, want) {
+		t.Errorf("searchTerms = %+v, want %+v", searchTerms, want)
+	}
+	if got := flags.Lookup("search").Value.String(); got != "[user_42,10.0.0.5]" {
+		t.Errorf("--search value = %q, want %q", got, "[user_42,10.0.0.5]")
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/joshi4/splash/query"
+)
+
+// Query flags
+var whereExpr string
+
+// whereQueryFromFlags parses --where. It returns nil when the flag isn't set.
+func whereQueryFromFlags() (*query.Query, error) {
+	if whereExpr == "" {
+		return nil, nil
+	}
+
+	q, err := query.Parse(whereExpr)
+	if err != nil {
+		return nil, whereError(whereExpr, err)
+	}
+	return q, nil
+}
+
+// whereError explains a bad --where expression, pointing at the problem
+func whereError(expr string, err error) error {
+	var syntaxErr *query.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return fmt.Errorf("invalid --where expression: %v", err)
+	}
+	return fmt.Errorf("invalid --where expression: %s\n  %s\n  %s^",
+		syntaxErr.Msg, expr, strings.Repeat(" ", utf8.RuneCountInString(expr[:syntaxErr.Pos])))
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/joshi4/splash/colorizer"
+	"github.com/joshi4/splash/parser"
+	"github.com/joshi4/splash/query"
+)
+
+func TestWhereErrorPointsAtProblem(t *testing.T) {
+	expr := "status >= && level == error"
+	_, err := query.Parse(expr)
+	if err == nil {
+		t.Fatal("Parse() succeeded, want an error")
+	}
+
+	want := `invalid --where expression: expected a value after ">=", found "&&"
+  status >= && level == error
+            ^`
+	if got := whereError(expr, err).Error(); got != want {
+		t.Errorf("whereError() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLineWriterMatchesWhereAndSearch(t *testing.T) {
+	where, err := query.Parse("status >= 500")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := colorizer.NewColorizer()
+	c.SetSearchString("api")
+	out := &lineWriter{colorizer: c, where: where}
+
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`{"service":"api","status":503}`, true},
+		{`{"service":"web","status":503}`, false},
+		{`{"service":"api","status":200}`, false},
+		{`10.0.0.5 - - [19/Jan/2025:08:30:00 +0000] "GET /api HTTP/1.1" 502 12`, true},
+		{`plain text about the api`, false},
+	}
+
+	p := parser.NewParser()
+	for _, tt := range tests {
+		line := entryLine{text: tt.line, format: p.DetectFormat(tt.line)}
+		if got := out.matches(line); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package colorizer
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/joshi4/splash/parser"
+)
+
+// benchmarkLine is a representative line of a log format. A variant names
+// a second line of the same format that takes another path through the
+// colorizer.
+type benchmarkLine struct {
+	format  parser.LogFormat
+	variant string
+	line    string
+}
+
+// name is the name of the line's sub-benchmark
+func (l benchmarkLine) name() string {
+	if l.variant == "" {
+		return l.format.String()
+	}
+	return l.format.String() + " " + l.variant
+}
+
+// benchmarkLines holds a representative line for every log format
+var benchmarkLines = []benchmarkLine{
+	{format: parser.UnknownFormat, line: `2025-01-19 08:30:00 something happened ERROR while processing request id=42`},
+	{format: parser.JSONFormat, line: `{"timestamp":"2025-01-19T08:30:00Z","level":"ERROR","message":"Database connection failed","service":"api","attempt":3,"meta":{"host":"db-1","retry":true}}`},
+	{format: parser.LogfmtFormat, line: `time="2025-01-19T08:30:00Z" level=error msg="Database connection failed" service=api attempt=3`},
+	{format: parser.ApacheCommonFormat, line: `127.0.0.1 - - [19/Jan/2025:08:30:00 +0000] "GET /api/users HTTP/1.1" 500 1234`},
+	{format: parser.NginxFormat, line: `127.0.0.1 - - [19/Jan/2025:08:30:00 +0000] "GET /api/users HTTP/1.1" 500 1234 "-" "Mozilla/5.0"`},
+	{format: parser.SyslogFormat, line: `Jan 19 08:30:00 server01 nginx[1234]: Connection refused from 10.0.0.1`},
+	{format: parser.SyslogFormat, variant: "journal short-iso", line: `2025-01-19T08:30:00+0000 server01 billing[4321]: Database connection failed`},
+	{format: parser.SyslogFormat, variant: "journal short-precise", line: `Jan 19 08:30:00.123456 server01 billing[4321]: Database connection failed`},
+	{format: parser.RsyslogFormat, line: `Jan 19 08:30:00 server01 rsyslogd[1234]: [origin software="rsyslogd"] start`},
+	{format: parser.GoStandardFormat, line: `2025/01/19 08:30:00 ERROR: database connection failed after 3 attempts`},
+	{format: parser.RailsFormat, line: `[2025-01-19T08:30:00.123456 #1234] ERROR -- : Database connection failed`},
+	{format: parser.DockerFormat, line: `2025-01-19T08:30:00.123456789Z ERROR Database connection failed`},
+	{format: parser.KubernetesFormat, line: `2025-01-19T08:30:00.123456Z 1 main.go:42] Database connection failed`},
+	{format: parser.HerokuFormat, line: `2025-01-19T08:30:00+00:00 app[web.1]: Database connection failed`},
+	{format: parser.GoTestFormat, line: `--- FAIL: TestDatabaseConnection (0.05s)`},
+	{format: parser.JavaExceptionFormat, line: `	at com.example.db.ConnectionPool.acquire(ConnectionPool.java:142)`},
+	{format: parser.PythonExceptionFormat, line: `  File "/app/db/pool.py", line 142, in acquire`},
+	{format: parser.JavaScriptExceptionFormat, line: `    at Pool.acquire (/app/db/pool.js:142:17)`},
+	{format: parser.GoroutineStackTraceFormat, line: `	/usr/local/go/src/net/http/server.go:3142 +0x2c5`},
+	{format: parser.RFC5424Format, line: `<131>1 2025-01-19T08:30:00.123Z server01 billing 4321 DB001 [origin@32473 ip="10.0.0.5" region="eu"] Database connection failed`},
+	{format: parser.JournalJSONFormat, line: `{"__REALTIME_TIMESTAMP":"1737275400123456","PRIORITY":"3","_HOSTNAME":"server01","_SYSTEMD_UNIT":"billing.service","_PID":"4321","MESSAGE":"Database connection failed"}`},
+	{format: parser.JournalExportFormat, line: `MESSAGE=Database connection failed`},
+	{format: parser.KlogFormat, line: `E0119 08:30:00.123456       1 main.go:42] "Database connection failed" service="api" attempt=3`},
+}
+
+// TestBenchmarkLinesCoverBuiltinFormats keeps a format added later from
+// going unbenchmarked
+func TestBenchmarkLinesCoverBuiltinFormats(t *testing.T) {
+	covered := make(map[parser.LogFormat]bool)
+	for _, l := range benchmarkLines {
+		covered[l.format] = true
+	}
+	for _, format := range parser.BuiltinFormats() {
+		if !covered[format] {
+			t.Errorf("benchmarkLines has no line of %v", format)
+		}
+	}
+}
+
+func benchmarkColorizer(b *testing.B, configure func(c *Colorizer)) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	for _, l := range benchmarkLines {
+		line, format := l.line, l.format
+		b.Run(l.name(), func(b *testing.B) {
+			c := NewColorizer()
+			if configure != nil {
+				configure(c)
+			}
+			b.ReportAllocs()
+			b.SetBytes(int64(len(line)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.ColorizeLog(line, format)
+			}
+		})
+	}
+}
+
+func BenchmarkColorizeLog(b *testing.B) {
+	benchmarkColorizer(b, nil)
+}
+
+func BenchmarkColorizeLogWithSearch(b *testing.B) {
+	benchmarkColorizer(b, func(c *Colorizer) {
+		c.SetSearchString("Database")
+	})
+}
@@ -1,9 +1,7 @@
 package colorizer
 
 import (
-	"encoding/json"
-	"fmt"
-	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -27,9 +25,17 @@ const (
 
 // Colorizer handles adding colors to log lines based on their format
 type Colorizer struct {
-	theme        *ColorTheme
-	searchString string
-	searchRegex  *regexp.Regexp
+	theme         *ColorTheme
+	patterns      []searchPattern
+	searchOptions SearchOptions
+	// ownerBuf records which pattern owns each byte while overlapping
+	// matches are resolved
+	ownerBuf []int
+	// matchBuf is reused across calls to applySearchHighlighting to avoid
+	// allocating a fresh match slice for every segment
+	matchBuf []SearchMatch
+	// line tracks the raw line and its matches during ColorizeLog
+	line lineSearch
 }
 
 // NewColorizer creates a new colorizer with adaptive theming
@@ -44,97 +50,118 @@ func (c *Colorizer) SetTheme(theme *ColorTheme) {
 	c.theme = theme
 }
 
-// SetSearchString sets a literal string to search for and highlight
-func (c *Colorizer) SetSearchString(pattern string) {
-	c.searchString = pattern
-	c.searchRegex = nil
+// ColorizeSourceLabel renders the name of an input source, cycling through
+// the theme's source label palette by index so each input keeps its color
+func (c *Colorizer) ColorizeSourceLabel(label string, index int) string {
+	if len(c.theme.SourceLabels) == 0 {
+		return label
+	}
+	if index < 0 {
+		index = -index
+	}
+	return c.theme.SourceLabels[index%len(c.theme.SourceLabels)].Render(label)
 }
 
-// SetSearchRegex sets a regular expression to search for and highlight
-func (c *Colorizer) SetSearchRegex(pattern string) error {
-	regex, err := regexp.Compile(pattern)
-	if err != nil {
-		return err
+// StderrGutter returns the marker placed before lines a wrapped command wrote to stderr
+func (c *Colorizer) StderrGutter() string {
+	return c.theme.StderrGutter.Render("▌") + " "
+}
+
+// MatchGutter returns the marker placed before lines that satisfy a --where
+// query, or blank space of the same width before lines that don't
+func (c *Colorizer) MatchGutter(matched bool) string {
+	if !matched {
+		return "  "
 	}
-	c.searchRegex = regex
-	c.searchString = ""
-	return nil
+	return c.theme.MatchGutter.Render("▌") + " "
 }
 
-// SearchMatch represents a found search match with its position
-type SearchMatch struct {
-	start int
-	end   int
-	text  string
+// lineSearch holds the search matches found in the line being colorized.
+// Segments are located in the raw line in the order they are emitted, so a
+// match spanning several segments is highlighted in each of them.
+type lineSearch struct {
+	active  bool
+	raw     string
+	matches []SearchMatch
+	cursor  int // end of the last segment located in raw
+}
+
+// segmentMatches returns the matches within a segment of output text. While
+// a line is being colorized the segment is found in the raw line and the
+// line's matches are clipped to it. Text that isn't part of the raw line is
+// searched on its own.
+func (c *Colorizer) segmentMatches(text string, dst []SearchMatch) []SearchMatch {
+	if !c.line.active {
+		return c.findMatches(text, dst)
+	}
+
+	pos := strings.Index(c.line.raw[c.line.cursor:], text)
+	if pos == -1 {
+		return c.findMatches(text, dst)
+	}
+	start := c.line.cursor + pos
+	end := start + len(text)
+	c.line.cursor = end
+
+	for _, match := range c.line.matches {
+		if match.end <= start || match.start >= end {
+			continue
+		}
+		from := max(match.start, start) - start
+		to := min(match.end, end) - start
+		dst = append(dst, SearchMatch{start: from, end: to, text: text[from:to], pattern: match.pattern})
+	}
+	return dst
 }
 
 // applySearchHighlighting applies search highlighting to any text, highlighting only matching parts
 // This is used during single-pass colorization for all formats
 func (c *Colorizer) applySearchHighlighting(text string, normalStyle lipgloss.Style) string {
-	if c.searchString == "" && c.searchRegex == nil {
+	if !c.HasSearch() {
 		return normalStyle.Render(text)
 	}
+	return c.highlightMatches(text, func(s string) string { return normalStyle.Render(s) })
+}
 
-	// Find all search matches in the plain text
-	var matches []SearchMatch
-
-	if c.searchRegex != nil {
-		// Handle regex search
-		regexMatches := c.searchRegex.FindAllStringIndex(text, -1)
-		for _, match := range regexMatches {
-			matches = append(matches, SearchMatch{
-				start: match[0],
-				end:   match[1],
-				text:  text[match[0]:match[1]],
-			})
-		}
-	} else if c.searchString != "" {
-		// Handle string search (case-sensitive)
-		searchLen := len(c.searchString)
-		startPos := 0
-
-		for {
-			pos := strings.Index(text[startPos:], c.searchString)
-			if pos == -1 {
-				break
-			}
-
-			actualPos := startPos + pos
-			matches = append(matches, SearchMatch{
-				start: actualPos,
-				end:   actualPos + searchLen,
-				text:  c.searchString,
-			})
-			startPos = actualPos + searchLen
-		}
+// highlightPlain writes separators and whitespace between styled segments
+// unchanged, highlighting any part of them covered by a search match
+func (c *Colorizer) highlightPlain(text string) string {
+	if !c.HasSearch() {
+		return text
 	}
+	return c.highlightMatches(text, func(s string) string { return s })
+}
+
+// highlightMatches renders the matching parts of text with the search
+// highlight and everything else with render
+func (c *Colorizer) highlightMatches(text string, render func(string) string) string {
+	matches := c.segmentMatches(text, c.matchBuf[:0])
+	defer func() { c.matchBuf = matches[:0] }()
 
 	if len(matches) == 0 {
-		return normalStyle.Render(text)
+		return render(text)
 	}
 
 	// Build result with highlighted matches
 	result := strings.Builder{}
+	result.Grow(len(text) * 2)
 	lastEnd := 0
 
 	for _, match := range matches {
 		// Add text before match with normal style
 		if match.start > lastEnd {
-			beforeText := text[lastEnd:match.start]
-			result.WriteString(normalStyle.Render(beforeText))
+			result.WriteString(render(text[lastEnd:match.start]))
 		}
 
 		// Add highlighted match
-		matchText := text[match.start:match.end]
-		result.WriteString(c.theme.UnifiedSearchHighlight.Render(matchText))
+		result.WriteString(c.searchStyle(match.pattern).Render(match.text))
 
 		lastEnd = match.end
 	}
 
 	// Add remaining text after last match
 	if lastEnd < len(text) {
-		remainingText := text[lastEnd:]
-		result.WriteString(normalStyle.Render(remainingText))
+		result.WriteString(render(text[lastEnd:]))
 	}
 
 	return result.String()
@@ -146,6 +173,26 @@ func (c *Colorizer) ColorizeLog(line string, format parser.LogFormat) string {
 		return line
 	}
 
+	// Search once against the raw line so matches can cross segment boundaries
+	if c.HasSearch() {
+		c.line = lineSearch{active: true, raw: line, matches: c.findMatches(line, c.line.matches[:0])}
+		defer func() { c.line = lineSearch{matches: c.line.matches[:0]} }()
+	}
+
+	// The envelope of CRI and kubectl lines is colored on its own, and the
+	// message inside it in its detected format
+	if envelope, ok := parser.ParseEnvelope(line); ok {
+		result := c.colorizeEnvelope(line, envelope)
+		if message := line[envelope.Span.End:]; message != "" {
+			result += c.colorizeFormat(message, format)
+		}
+		return result
+	}
+	return c.colorizeFormat(line, format)
+}
+
+// colorizeFormat colors a line that has no envelope in the given format
+func (c *Colorizer) colorizeFormat(line string, format parser.LogFormat) string {
 	var result string
 
 	// Apply colorization with integrated search highlighting (single-pass)
@@ -162,6 +209,12 @@ func (c *Colorizer) ColorizeLog(line string, format parser.LogFormat) string {
 		result = c.colorizeSyslog(line)
 	case parser.RsyslogFormat:
 		result = c.colorizeRsyslog(line)
+	case parser.RFC5424Format:
+		result = c.colorizeRFC5424(line)
+	case parser.JournalJSONFormat:
+		result = c.colorizeJournalJSON(line)
+	case parser.JournalExportFormat:
+		result = c.colorizeJournalExport(line)
 	case parser.GoStandardFormat:
 		result = c.colorizeGoStandard(line)
 	case parser.RailsFormat:
@@ -170,6 +223,8 @@ func (c *Colorizer) ColorizeLog(line string, format parser.LogFormat) string {
 		result = c.colorizeDocker(line)
 	case parser.KubernetesFormat:
 		result = c.colorizeKubernetes(line)
+	case parser.KlogFormat:
+		result = c.colorizeKlog(line)
 	case parser.HerokuFormat:
 		result = c.colorizeHeroku(line)
 	case parser.GoTestFormat:
@@ -183,350 +238,458 @@ func (c *Colorizer) ColorizeLog(line string, format parser.LogFormat) string {
 	case parser.GoroutineStackTraceFormat:
 		result = c.colorizeGoroutineStackTrace(line)
 	default:
-		result = c.colorizeGenericLog(line)
+		if colorize := parser.FormatColorizer(format); colorize != nil {
+			result = c.colorizeSegments(line, colorize(line))
+		} else {
+			result = c.colorizeGenericLog(line)
+		}
 	}
 
 	return result
 }
 
-// colorizeJSON adds colors to JSON log lines
-func (c *Colorizer) colorizeJSON(line string) string {
-	var data map[string]interface{}
-	if err := json.Unmarshal([]byte(line), &data); err != nil {
-		return line // Return original if not valid JSON
+// colorizeEnvelope colors the envelope of a CRI or kubectl line, which
+// covers the start of the line up to the message
+func (c *Colorizer) colorizeEnvelope(line string, envelope parser.Envelope) string {
+	// Each field gets its style, and the spaces, brackets and slashes between
+	// them are dimmed. Fields are in line order: kubectl lines have the first
+	// three, CRI lines the last three.
+	fields := []struct {
+		field parser.Field
+		style lipgloss.Style
+	}{
+		{envelope.Pod, c.theme.Hostname},
+		{envelope.Container, c.theme.Service},
+		{envelope.Timestamp.Field, c.theme.Timestamp},
+		{envelope.Stream, c.theme.Service},
+		{envelope.Tag, c.theme.Bracket},
 	}
 
-	// Re-marshal with indentation to get clean formatting, then colorize
 	result := strings.Builder{}
-	result.WriteString(c.theme.Bracket.Render("{"))
-
-	first := true
-	for key, value := range data {
-		if !first {
-			result.WriteString(", ")
+	pos := 0
+	for _, f := range fields {
+		if !f.field.Found() {
+			continue
 		}
-		first = false
-
-		// Colorize key
-		result.WriteString(c.theme.Quote.Render(`"`))
-
-		// Special handling for common log fields with search highlighting
-		keyStyle := c.theme.JSONKey
-		if c.isLogLevelKey(key) {
-			keyStyle = c.theme.GetLogLevelStyle(key)
+		if start := f.field.Span.Start; start > pos {
+			result.WriteString(c.applySearchHighlighting(line[pos:start], c.theme.Bracket))
 		}
-		styledKey := c.applySearchHighlighting(key, keyStyle)
-		result.WriteString(styledKey)
-		result.WriteString(c.theme.Quote.Render(`"`))
-		result.WriteString(c.theme.Equals.Render(":"))
-
-		// Colorize value based on key and type
-		result.WriteString(c.colorizeJSONValue(key, value))
+		result.WriteString(c.applySearchHighlighting(f.field.Value, f.style))
+		pos = f.field.Span.End
+	}
+	if end := envelope.Span.End; end > pos {
+		result.WriteString(c.applySearchHighlighting(line[pos:end], c.theme.Bracket))
 	}
-
-	result.WriteString(c.theme.Bracket.Render("}"))
 	return result.String()
 }
 
-// colorizeJSONValue colors a JSON value based on context and type with integrated search highlighting
-func (c *Colorizer) colorizeJSONValue(key string, value interface{}) string {
-	switch v := value.(type) {
-	case string:
-		// Special handling for known fields
-		if c.isLogLevelKey(key) {
-			styledValue := c.applySearchHighlighting(v, c.theme.GetLogLevelStyle(v))
-			return c.theme.Quote.Render(`"`) + styledValue + c.theme.Quote.Render(`"`)
-		}
-		if c.isTimestampKey(key) {
-			styledValue := c.applySearchHighlighting(v, c.theme.Timestamp)
-			return c.theme.Quote.Render(`"`) + styledValue + c.theme.Quote.Render(`"`)
-		}
-		if c.isServiceKey(key) {
-			styledValue := c.applySearchHighlighting(v, c.theme.Service)
-			return c.theme.Quote.Render(`"`) + styledValue + c.theme.Quote.Render(`"`)
+// styledField is a field of a parsed line and how to draw its text
+type styledField struct {
+	field  parser.Field
+	render func(string) string
+}
+
+// styled returns a function drawing text in style, with search matches highlighted
+func (c *Colorizer) styled(style lipgloss.Style) func(string) string {
+	return func(text string) string {
+		return c.applySearchHighlighting(text, style)
+	}
+}
+
+// colorizeFields draws the fields of a line parsed by parser.ParseAs in line
+// order, starting at offset pos, and the text between them with gap. Fields
+// the line doesn't have are skipped. It also returns the offset where the
+// last field drawn ends.
+func (c *Colorizer) colorizeFields(line string, pos int, fields []styledField, gap func(string) string) (string, int) {
+	result := strings.Builder{}
+	for _, f := range fields {
+		span := f.field.Span
+		if !f.field.Found() || span.Start < pos {
+			continue
 		}
-		if c.isIdentifierKey(key) {
-			styledValue := c.applySearchHighlighting(v, c.theme.Service)
-			return c.theme.Quote.Render(`"`) + styledValue + c.theme.Quote.Render(`"`)
+		if span.Start > pos {
+			result.WriteString(gap(line[pos:span.Start]))
 		}
-		if c.isStatusKey(key) {
-			styledValue := c.applySearchHighlighting(v, c.theme.GetHTTPStatusStyle(v))
-			return c.theme.Quote.Render(`"`) + styledValue + c.theme.Quote.Render(`"`)
+		result.WriteString(f.render(line[span.Start:span.End]))
+		pos = span.End
+	}
+	return result.String(), pos
+}
+
+// colorizeSeparators colors the text between the fields of a line. Brackets
+// and colons are dimmed along with the spaces after them, as is the "-" of an
+// empty field. Quotes are colored as quotes and anything else is left plain.
+func (c *Colorizer) colorizeSeparators(text string) string {
+	result := strings.Builder{}
+	plain := 0 // start of the text not written yet
+	for i := 0; i < len(text); {
+		start := i
+		var style lipgloss.Style
+		switch ch := text[i]; {
+		case ch == '"':
+			for i < len(text) && (text[i] == '"' || text[i] == ' ') {
+				i++
+			}
+			style = c.theme.Quote
+		case strings.IndexByte("[]<>:", ch) >= 0:
+			for i < len(text) && strings.IndexByte("[]<>:", text[i]) >= 0 {
+				i++
+			}
+			for i < len(text) && text[i] == ' ' {
+				i++
+			}
+			style = c.theme.Bracket
+		case ch == '-' && (i == 0 || text[i-1] == ' ') && (i+1 == len(text) || text[i+1] == ' '):
+			i++
+			style = c.theme.Bracket
+		default:
+			i++
+			continue
 		}
-		// Regular string value
-		styledValue := c.applySearchHighlighting(v, c.theme.JSONString)
-		return c.theme.Quote.Render(`"`) + styledValue + c.theme.Quote.Render(`"`)
-	case float64:
-		numberStr := fmt.Sprintf("%g", v)
-		return c.applySearchHighlighting(numberStr, c.theme.JSONNumber)
-	case bool:
-		if v {
-			return c.applySearchHighlighting("true", c.theme.StatusOK)
+		if plain < start {
+			result.WriteString(c.highlightPlain(text[plain:start]))
 		}
-		return c.applySearchHighlighting("false", c.theme.StatusWarn)
-	case map[string]interface{}:
-		// Recursively colorize nested JSON objects
-		return c.colorizeNestedJSONObject(v)
-	case []interface{}:
-		// Handle JSON arrays
-		return c.colorizeJSONArray(v)
-	default:
-		// Fallback to JSON marshaling for other types (null, etc.)
-		jsonBytes, _ := json.Marshal(v)
-		return c.applySearchHighlighting(string(jsonBytes), c.theme.JSONValue)
+		result.WriteString(c.applySearchHighlighting(text[start:i], style))
+		plain = i
 	}
+	if plain < len(text) {
+		result.WriteString(c.highlightPlain(text[plain:]))
+	}
+	return result.String()
 }
 
-// colorizeNestedJSONObject recursively colorizes a nested JSON object
-func (c *Colorizer) colorizeNestedJSONObject(obj map[string]interface{}) string {
-	result := strings.Builder{}
-	result.WriteString(c.theme.Bracket.Render("{"))
+// colorizeSegments styles each segment of a line of a registered format by
+// its role. A message segment gets the same treatment as built-in formats'
+// messages, and segments that overlap an earlier one are ignored.
+func (c *Colorizer) colorizeSegments(line string, segments []parser.Segment) string {
+	segments = slices.Clone(segments)
+	slices.SortStableFunc(segments, func(a, b parser.Segment) int {
+		return a.Span.Start - b.Span.Start
+	})
 
-	first := true
-	for key, value := range obj {
-		if !first {
-			result.WriteString(", ")
+	result := strings.Builder{}
+	pos := 0
+	for _, segment := range segments {
+		start, end := segment.Span.Start, segment.Span.End
+		if start < pos || end > len(line) || start >= end {
+			continue
+		}
+		if start > pos {
+			result.WriteString(c.highlightPlain(line[pos:start]))
+		}
+		text := line[start:end]
+		if segment.Role == parser.RoleMessage {
+			result.WriteString(c.colorizeMessageWithHighlighting(text))
+		} else {
+			result.WriteString(c.applySearchHighlighting(text, c.theme.GetRoleStyle(segment.Role, text)))
 		}
-		first = false
+		pos = end
+	}
+	if pos < len(line) {
+		result.WriteString(c.highlightPlain(line[pos:]))
+	}
+	return result.String()
+}
 
-		// Colorize nested key with search highlighting
-		result.WriteString(c.theme.Quote.Render(`"`))
-		styledKey := c.applySearchHighlighting(key, c.theme.JSONKey)
-		result.WriteString(styledKey)
-		result.WriteString(c.theme.Quote.Render(`"`))
-		result.WriteString(c.theme.Equals.Render(":"))
+// colorizeJSON adds colors to JSON log lines by walking the original token
+// stream, so key order, number text, escapes and whitespace are preserved
+func (c *Colorizer) colorizeJSON(line string) string {
+	return c.walkJSON(line, false)
+}
 
-		// Recursively colorize nested value
-		result.WriteString(c.colorizeJSONValue(key, value))
-	}
+// colorizeJournalJSON adds colors to journalctl -o json records, styling the
+// journal's well-known fields by role
+func (c *Colorizer) colorizeJournalJSON(line string) string {
+	return c.walkJSON(line, true)
+}
 
-	result.WriteString(c.theme.Bracket.Render("}"))
+// walkJSON colorizes a JSON line, optionally as a journal record, from the
+// tokens parser.ScanJSON finds in it
+func (c *Colorizer) walkJSON(line string, journal bool) string {
+	result := strings.Builder{}
+	result.Grow(len(line) * 4)
+	pos := 0
+	valid := parser.ScanJSON(line, func(tok parser.JSONToken) {
+		result.WriteString(c.colorizeJSONPunctuation(line[pos:tok.Span.Start]))
+		text := line[tok.Span.Start:tok.Span.End]
+		result.WriteString(c.applySearchHighlighting(text, c.jsonTokenStyle(tok, text, journal)))
+		pos = tok.Span.End
+	})
+	if !valid {
+		return line // Return original if not valid JSON
+	}
+	result.WriteString(c.colorizeJSONPunctuation(line[pos:]))
 	return result.String()
 }
 
-// colorizeJSONArray colorizes a JSON array
-func (c *Colorizer) colorizeJSONArray(arr []interface{}) string {
+// colorizeJSONPunctuation colors the text between the tokens of a JSON line:
+// brackets, the quotes around strings, colons, commas and whitespace
+func (c *Colorizer) colorizeJSONPunctuation(text string) string {
 	result := strings.Builder{}
-	result.WriteString(c.theme.Bracket.Render("["))
-
-	for i, value := range arr {
-		if i > 0 {
-			result.WriteString(", ")
+	plain := 0 // start of the commas and whitespace not written yet
+	for i := 0; i < len(text); i++ {
+		var style lipgloss.Style
+		switch text[i] {
+		case '{', '}', '[', ']':
+			style = c.theme.Bracket
+		case '"':
+			style = c.theme.Quote
+		case ':':
+			style = c.theme.Equals
+		default:
+			continue
+		}
+		if plain < i {
+			result.WriteString(c.highlightPlain(text[plain:i]))
 		}
+		result.WriteString(c.applySearchHighlighting(text[i:i+1], style))
+		plain = i + 1
+	}
+	if plain < len(text) {
+		result.WriteString(c.highlightPlain(text[plain:]))
+	}
+	return result.String()
+}
+
+// jsonTokenStyle picks the style of a JSON key or value. Keys of the
+// top-level object get log level styling, nested keys use the plain key style.
+func (c *Colorizer) jsonTokenStyle(tok parser.JSONToken, text string, journal bool) lipgloss.Style {
+	topLevel := tok.Depth == 1
+	switch {
+	case tok.Key && topLevel && journal:
+		return c.journalKeyStyle(tok.Value)
+	case tok.Key && topLevel && c.isLogLevelKey(text):
+		return c.theme.GetLogLevelStyle(text)
+	case tok.Key:
+		return c.theme.JSONKey
+	case tok.String && journal:
+		return c.journalValueStyle(tok.Member, tok.Value)
+	case tok.String:
+		return c.jsonStringStyle(tok.Member, tok.Value)
+	}
+
+	switch text {
+	case "true":
+		return c.theme.StatusOK
+	case "false":
+		return c.theme.StatusWarn
+	case "null":
+		return c.theme.JSONValue
+	default:
+		// Numbers keep their original text so large IDs never lose precision
+		return c.theme.JSONNumber
+	}
+}
 
-		// Colorize array element (use empty key for array elements)
-		result.WriteString(c.colorizeJSONValue("", value))
+// jsonStringStyle picks the style for a string value, with special handling for known fields
+func (c *Colorizer) jsonStringStyle(key, value string) lipgloss.Style {
+	switch {
+	case c.isLogLevelKey(key):
+		return c.theme.GetLogLevelStyle(value)
+	case c.isTimestampKey(key):
+		return c.theme.Timestamp
+	case c.isServiceKey(key), c.isIdentifierKey(key):
+		return c.theme.Service
+	case c.isStatusKey(key):
+		return c.theme.GetHTTPStatusStyle(value)
+	default:
+		return c.theme.JSONString
 	}
+}
 
-	result.WriteString(c.theme.Bracket.Render("]"))
+// colorizeJournalExport adds colors to a line of a journalctl -o export
+// record. The raw data of binary fields and their bare names are left plain.
+func (c *Colorizer) colorizeJournalExport(line string) string {
+	entry := parser.ParseAs(line, parser.JournalExportFormat)
+	if len(entry.Attributes) == 0 {
+		return c.highlightPlain(line)
+	}
+	attr := entry.Attributes[0]
+	key, value := line[attr.KeySpan.Start:attr.KeySpan.End], attr.Value.Value
+
+	result := strings.Builder{}
+	result.WriteString(c.applySearchHighlighting(key, c.journalKeyStyle(key)))
+	result.WriteString(c.applySearchHighlighting(line[attr.KeySpan.End:attr.Value.Span.Start], c.theme.Equals))
+	result.WriteString(c.applySearchHighlighting(value, c.journalValueStyle(key, value)))
 	return result.String()
 }
 
+// journalKeyStyle styles the name of a journal field. The fields journald
+// adds itself, whose names start with an underscore, are dimmed so that the
+// record's MESSAGE stands out among them.
+func (c *Colorizer) journalKeyStyle(key string) lipgloss.Style {
+	if strings.HasPrefix(key, "_") {
+		return c.theme.Bracket
+	}
+	return c.theme.JSONKey
+}
+
+// journalValueStyle styles the value of a journal field by the role of the field
+func (c *Colorizer) journalValueStyle(key, value string) lipgloss.Style {
+	switch key {
+	case "MESSAGE":
+		return c.theme.Message
+	case "PRIORITY":
+		if priority, ok := parser.ParsePriority(value); ok {
+			return c.theme.GetLogLevelStyle(priority.Level().String())
+		}
+		return c.theme.JSONString
+	case "__REALTIME_TIMESTAMP", "_SOURCE_REALTIME_TIMESTAMP":
+		return c.theme.Timestamp
+	case "_HOSTNAME":
+		return c.theme.Hostname
+	case "_SYSTEMD_UNIT", "SYSLOG_IDENTIFIER", "_COMM":
+		return c.theme.Service
+	case "_PID", "SYSLOG_PID":
+		return c.theme.PID
+	}
+	if strings.HasPrefix(key, "_") {
+		return c.theme.Bracket
+	}
+	return c.theme.JSONString
+}
+
 // colorizeLogfmt adds colors to logfmt lines
 func (c *Colorizer) colorizeLogfmt(line string) string {
 	line = strings.TrimSpace(line)
 	if len(line) == 0 {
 		return line
 	}
+	entry := parser.ParseAs(line, parser.LogfmtFormat)
+	return c.colorizeAttributes(line, 0, entry.Attributes)
+}
 
+// colorizeAttributes colors the key=value pairs of line from offset pos on,
+// rendering the raw text of each value including its quotes. Bare words
+// between the pairs are colored as log levels when they look like one.
+func (c *Colorizer) colorizeAttributes(line string, pos int, attrs []parser.Attribute) string {
 	result := strings.Builder{}
-
-	// Parse the line character by character to handle quoted values properly
-	i := 0
-	for i < len(line) {
-		// Skip whitespace and add it to result
-		whitespaceStart := i
-		for i < len(line) && line[i] == ' ' {
-			i++
-		}
-		if i > whitespaceStart {
-			result.WriteString(line[whitespaceStart:i])
-		}
-		if i >= len(line) {
-			break
+	for _, attr := range attrs {
+		if attr.KeySpan.Start < pos {
+			continue
 		}
+		result.WriteString(c.colorizeBareWords(line[pos:attr.KeySpan.Start]))
 
-		// Look for key=value pairs
-		keyStart := i
-		for i < len(line) && line[i] != '=' && line[i] != ' ' {
-			i++
+		// Color the key with search highlighting
+		key := line[attr.KeySpan.Start:attr.KeySpan.End]
+		keyStyle := c.theme.LogfmtKey
+		if c.isLogLevelKey(key) {
+			keyStyle = c.theme.GetLogLevelStyle(key)
 		}
-
-		if i < len(line) && line[i] == '=' {
-			// Found a key=value pair
-			key := line[keyStart:i]
-			i++ // skip the '='
-
-			// Color the key with search highlighting
-			keyStyle := c.theme.LogfmtKey
-			if c.isLogLevelKey(key) {
-				keyStyle = c.theme.GetLogLevelStyle(key)
-			}
-			result.WriteString(c.applySearchHighlighting(key, keyStyle))
-			result.WriteString(c.theme.Equals.Render("="))
-
-			if i >= len(line) {
-				// Key with no value (key=)
-				break
+		result.WriteString(c.applySearchHighlighting(key, keyStyle))
+		result.WriteString(c.applySearchHighlighting("=", c.theme.Equals))
+
+		// Quoted values start after the quote that follows the "="
+		span := attr.Value.Span
+		cleanValue := line[span.Start:span.End]
+		valueStart, valueEnd := span.Start, span.End
+		quoted := span.Start > attr.KeySpan.End+1
+		if quoted {
+			valueStart--
+			if valueEnd < len(line) {
+				valueEnd++
 			}
-
-			valueStart := i
-			var value string
-			var cleanValue string
-
-			if line[i] == '"' {
-				// Quoted value
-				valueStart = i
-				i++ // skip opening quote
-				for i < len(line) && line[i] != '"' {
-					if line[i] == '\\' && i+1 < len(line) {
-						i += 2 // skip escaped character
-					} else {
-						i++
-					}
-				}
-				if i < len(line) {
-					i++ // skip closing quote
-				}
-				value = line[valueStart:i]
-				cleanValue = strings.Trim(value, `"`)
+		}
+		value := line[valueStart:valueEnd]
+		pos = valueEnd
+
+		// Color the value based on key with search highlighting
+		switch {
+		case c.isLogLevelKey(key):
+			if quoted && valueEnd > span.End {
+				result.WriteString(c.applySearchHighlighting(`"`, c.theme.Quote))
+				result.WriteString(c.applySearchHighlighting(cleanValue, c.theme.GetLogLevelStyle(cleanValue)))
+				result.WriteString(c.applySearchHighlighting(`"`, c.theme.Quote))
 			} else {
-				// Unquoted value - read until whitespace
-				for i < len(line) && line[i] != ' ' {
-					i++
-				}
-				value = line[valueStart:i]
-				cleanValue = value
+				result.WriteString(c.applySearchHighlighting(value, c.theme.GetLogLevelStyle(cleanValue)))
 			}
+		case c.isTimestampKey(key):
+			result.WriteString(c.applySearchHighlighting(value, c.theme.Timestamp))
+		case c.isServiceKey(key):
+			result.WriteString(c.applySearchHighlighting(value, c.theme.Service))
+		case c.isIdentifierKey(key):
+			result.WriteString(c.applySearchHighlighting(value, c.theme.Service)) // Use Service color for IDs
+		case c.isStatusKey(key):
+			// Parse status code and use appropriate HTTP status style
+			result.WriteString(c.applySearchHighlighting(value, c.theme.GetHTTPStatusStyle(cleanValue)))
+		default:
+			result.WriteString(c.applySearchHighlighting(value, c.theme.LogfmtValue))
+		}
+	}
+	result.WriteString(c.colorizeBareWords(line[pos:]))
 
-			// Color the value based on key with search highlighting
-			switch {
-			case c.isLogLevelKey(key):
-				if strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
-					result.WriteString(c.theme.Quote.Render(`"`))
-					result.WriteString(c.applySearchHighlighting(cleanValue, c.theme.GetLogLevelStyle(cleanValue)))
-					result.WriteString(c.theme.Quote.Render(`"`))
-				} else {
-					result.WriteString(c.applySearchHighlighting(value, c.theme.GetLogLevelStyle(cleanValue)))
-				}
-			case c.isTimestampKey(key):
-				result.WriteString(c.applySearchHighlighting(value, c.theme.Timestamp))
-			case c.isServiceKey(key):
-				result.WriteString(c.applySearchHighlighting(value, c.theme.Service))
-			case c.isIdentifierKey(key):
-				result.WriteString(c.applySearchHighlighting(value, c.theme.Service)) // Use Service color for IDs
-			case c.isStatusKey(key):
-				// Parse status code and use appropriate HTTP status style
-				statusCode := strings.Trim(cleanValue, `"`)
-				result.WriteString(c.applySearchHighlighting(value, c.theme.GetHTTPStatusStyle(statusCode)))
-			default:
-				result.WriteString(c.applySearchHighlighting(value, c.theme.LogfmtValue))
-			}
-		} else {
-			// Not a key=value pair, read the token
-			tokenEnd := i
-			for tokenEnd < len(line) && line[tokenEnd] != ' ' {
-				tokenEnd++
-			}
-			token := line[keyStart:tokenEnd]
-			i = tokenEnd
+	return result.String()
+}
 
-			// Check if it's a log level
-			if c.looksLikeLogLevel(token) {
-				result.WriteString(c.applySearchHighlighting(token, c.theme.GetLogLevelStyle(token)))
-			} else {
-				result.WriteString(c.applySearchHighlighting(token, lipgloss.NewStyle()))
-			}
+// colorizeBareWords colors the words of text that aren't key=value pairs,
+// styling the ones that look like a log level
+func (c *Colorizer) colorizeBareWords(text string) string {
+	result := strings.Builder{}
+	for len(text) > 0 {
+		end := strings.IndexAny(text, " \t")
+		if end < 0 {
+			end = len(text)
+		}
+		if end == 0 {
+			end = len(text) - len(strings.TrimLeft(text, " \t"))
+			result.WriteString(c.highlightPlain(text[:end]))
+		} else if token := text[:end]; c.looksLikeLogLevel(token) {
+			result.WriteString(c.applySearchHighlighting(token, c.theme.GetLogLevelStyle(token)))
+		} else {
+			result.WriteString(c.applySearchHighlighting(token, lipgloss.NewStyle()))
 		}
+		text = text[end:]
 	}
-
 	return result.String()
 }
 
 // colorizeApacheCommon adds colors to Apache Common Log format
 func (c *Colorizer) colorizeApacheCommon(line string) string {
 	// Apache Common Log format: IP - - [timestamp] "method URL protocol" status size
-	re := regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "([A-Z]+) ([^"]*) ([^"]*)" (\d+) (\S+)`)
-	matches := re.FindStringSubmatch(line)
-
-	if len(matches) != 10 {
+	entry := parser.ParseAs(line, parser.ApacheCommonFormat)
+	if !entry.Status.Found() {
 		return c.colorizeGenericLog(line)
 	}
-
-	ip := matches[1]
-	timestamp := matches[4]
-	method := matches[5]
-	url := matches[6]
-	protocol := matches[7]
-	status := matches[8]
-	size := matches[9]
-
-	result := strings.Builder{}
-	// Apply search highlighting during colorization (single-pass)
-	result.WriteString(c.applySearchHighlighting(ip, c.theme.IP))
-	result.WriteString(" - - ")
-	result.WriteString(c.theme.Bracket.Render("["))
-	result.WriteString(c.applySearchHighlighting(timestamp, c.theme.Timestamp))
-	result.WriteString(c.theme.Bracket.Render("] "))
-	result.WriteString(c.theme.Quote.Render(`"`))
-	result.WriteString(c.applySearchHighlighting(method, c.theme.Method))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(url, c.theme.URL))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(protocol, lipgloss.NewStyle()))
-	result.WriteString(c.theme.Quote.Render(`" `))
-	result.WriteString(c.applySearchHighlighting(status, c.theme.GetHTTPStatusStyle(status)))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(size, lipgloss.NewStyle()))
-
-	return result.String()
+	return c.colorizeAccessLog(line, entry, nil)
 }
 
 // colorizeNginx adds colors to Nginx log format (extends Apache)
 func (c *Colorizer) colorizeNginx(line string) string {
 	// Nginx format: IP - - [timestamp] "method URL protocol" status size "referer" "user-agent"
-	re := regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "([A-Z]+) ([^"]*) ([^"]*)" (\d+) (\S+) "([^"]*)" "([^"]*)"`)
-	matches := re.FindStringSubmatch(line)
-
-	if len(matches) != 12 {
+	entry := parser.ParseAs(line, parser.NginxFormat)
+	if !entry.Status.Found() {
 		return c.colorizeApacheCommon(line) // Fallback to Apache format
 	}
+	referer, _ := entry.Attribute("referer")
+	userAgent, _ := entry.Attribute("user_agent")
+	return c.colorizeAccessLog(line, entry, []styledField{
+		{referer, c.styled(lipgloss.NewStyle())},
+		{userAgent, c.styled(lipgloss.NewStyle())},
+	})
+}
 
-	ip := matches[1]
-	timestamp := matches[4]
-	method := matches[5]
-	url := matches[6]
-	protocol := matches[7]
-	status := matches[8]
-	size := matches[9]
-	referer := matches[10]
-	userAgent := matches[11]
+// colorizeAccessLog colors the request fields Apache and Nginx share,
+// followed by any extra fields of the format
+func (c *Colorizer) colorizeAccessLog(line string, entry parser.LogEntry, extra []styledField) string {
+	ip, _ := entry.Attribute("ip")
+	ident, _ := entry.Attribute("ident")
+	user, _ := entry.Attribute("user")
+	protocol, _ := entry.Attribute("protocol")
 
-	result := strings.Builder{}
 	// Apply search highlighting during colorization (single-pass)
-	result.WriteString(c.applySearchHighlighting(ip, c.theme.IP))
-	result.WriteString(" - - ")
-	result.WriteString(c.theme.Bracket.Render("["))
-	result.WriteString(c.applySearchHighlighting(timestamp, c.theme.Timestamp))
-	result.WriteString(c.theme.Bracket.Render("] "))
-	result.WriteString(c.theme.Quote.Render(`"`))
-	result.WriteString(c.applySearchHighlighting(method, c.theme.Method))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(url, c.theme.URL))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(protocol, lipgloss.NewStyle()))
-	result.WriteString(c.theme.Quote.Render(`" `))
-	result.WriteString(c.applySearchHighlighting(status, c.theme.GetHTTPStatusStyle(status)))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(size, lipgloss.NewStyle()))
-	result.WriteString(" ")
-	result.WriteString(c.theme.Quote.Render(`"`))
-	result.WriteString(c.applySearchHighlighting(referer, lipgloss.NewStyle()))
-	result.WriteString(c.theme.Quote.Render(`" "`))
-	result.WriteString(c.applySearchHighlighting(userAgent, lipgloss.NewStyle()))
-	result.WriteString(c.theme.Quote.Render(`"`))
-
-	return result.String()
+	fields := append([]styledField{
+		{ip, c.styled(c.theme.IP)},
+		{ident, c.highlightPlain},
+		{user, c.highlightPlain},
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{entry.Method, c.styled(c.theme.Method)},
+		{entry.Path, c.styled(c.theme.URL)},
+		{protocol, c.styled(lipgloss.NewStyle())},
+		{entry.Status, c.styled(c.theme.GetHTTPStatusStyle(entry.Status.Value))},
+		{entry.Size, c.styled(lipgloss.NewStyle())},
+	}, extra...)
+	result, pos := c.colorizeFields(line, 0, fields, c.colorizeSeparators)
+	return result + c.colorizeSeparators(line[pos:])
 }
 
 // Helper functions for identifying special keys
@@ -566,59 +729,43 @@ func (c *Colorizer) looksLikeLogLevel(s string) bool {
 
 // colorizeSyslog adds colors to syslog format lines
 func (c *Colorizer) colorizeSyslog(line string) string {
-	// Syslog format: "Jan 19 10:30:00 hostname myapp[1234]: ERROR: Database connection failed"
-	re := regexp.MustCompile(`^(\w{3} \d{1,2} \d{2}:\d{2}:\d{2}) (\S+) (\S+)\[(\d+)\]: (.*)`)
-	matches := re.FindStringSubmatch(line)
-
-	if len(matches) != 6 {
+	// Syslog format: "Jan 19 10:30:00 hostname myapp[1234]: ERROR: Database connection failed",
+	// optionally with a "<13>" priority, an RFC 3339 timestamp or no [pid]
+	entry := parser.ParseAs(line, parser.SyslogFormat)
+	if !entry.Message.Found() {
 		return c.colorizeGenericLog(line) // Fallback
 	}
+	return c.colorizeSyslogHeader(line, entry)
+}
 
-	timestamp := matches[1]
-	hostname := matches[2]
-	process := matches[3]
-	pid := matches[4]
-	message := matches[5]
+// colorizeSyslogHeader colors a syslog line from the fields of its header,
+// with a priority colored by the severity it encodes
+func (c *Colorizer) colorizeSyslogHeader(line string, entry parser.LogEntry) string {
+	pri, _ := entry.Attribute("pri")
 
-	result := strings.Builder{}
 	// Apply search highlighting during colorization (single-pass)
-	result.WriteString(c.applySearchHighlighting(timestamp, c.theme.Timestamp))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(hostname, c.theme.Hostname))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(process, c.theme.Service))
-	result.WriteString(c.theme.Bracket.Render("["))
-	result.WriteString(c.applySearchHighlighting(pid, c.theme.PID))
-	result.WriteString(c.theme.Bracket.Render("]: "))
-	result.WriteString(c.colorizeMessageWithHighlighting(message))
+	result, _ := c.colorizeFields(line, 0, []styledField{
+		{pri, c.styled(c.priorityStyle(pri.Value))},
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{entry.Host, c.styled(c.theme.Hostname)},
+		{entry.Process, c.styled(c.theme.Service)},
+		{entry.PID, c.styled(c.theme.PID)},
+		{entry.Message, c.colorizeMessageWithHighlighting},
+	}, c.colorizeSeparators)
+	return result
+}
 
-	return result.String()
+// priorityStyle is the style of a syslog PRI, the color of the severity it encodes
+func (c *Colorizer) priorityStyle(pri string) lipgloss.Style {
+	priority, _ := parser.ParsePriority(pri)
+	return c.theme.GetLogLevelStyle(priority.Level().String())
 }
 
 // colorizeRsyslog adds colors to rsyslog-style lines and continuation lines
 func (c *Colorizer) colorizeRsyslog(line string) string {
 	// Try to parse header like: "Aug  8 00:15:23 Host syslogd[347]: Message"
-	re := regexp.MustCompile(`^(\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2})\s+(\S+)\s+((?:rsyslogd|syslogd))\[(\d+)\]:\s*(.*)$`)
-	matches := re.FindStringSubmatch(line)
-
-	if len(matches) == 6 {
-		timestamp := matches[1]
-		hostname := matches[2]
-		proc := matches[3]
-		pid := matches[4]
-		message := matches[5]
-
-		result := strings.Builder{}
-		result.WriteString(c.applySearchHighlighting(timestamp, c.theme.Timestamp))
-		result.WriteString(" ")
-		result.WriteString(c.applySearchHighlighting(hostname, c.theme.Hostname))
-		result.WriteString(" ")
-		result.WriteString(c.applySearchHighlighting(proc, c.theme.Service))
-		result.WriteString(c.theme.Bracket.Render("["))
-		result.WriteString(c.applySearchHighlighting(pid, c.theme.PID))
-		result.WriteString(c.theme.Bracket.Render("]: "))
-		result.WriteString(c.colorizeMessageWithHighlighting(message))
-		return result.String()
+	if entry := parser.ParseAs(line, parser.RsyslogFormat); entry.Message.Found() {
+		return c.colorizeSyslogHeader(line, entry)
 	}
 
 	// Continuation or indented lines: render as plain message with slight indent styling
@@ -630,158 +777,200 @@ func (c *Colorizer) colorizeRsyslog(line string) string {
 	return c.colorizeSyslog(line)
 }
 
-func (c *Colorizer) colorizeGoStandard(line string) string {
-	// Go standard format: "2025/01/19 10:30:00 ERROR: Database connection failed"
-	re := regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) (.*)`)
-	matches := re.FindStringSubmatch(line)
-
-	if len(matches) != 3 {
+// colorizeRFC5424 adds colors to RFC 5424 syslog lines. The PRI is colored
+// by the severity it encodes and nil "-" fields are dimmed like punctuation.
+func (c *Colorizer) colorizeRFC5424(line string) string {
+	// RFC 5424 format: "<134>1 2025-01-19T10:30:00.123Z web01 billing 4321 PAY001 [origin@32473 ip="10.0.0.5"] Payment settled"
+	entry := parser.ParseAs(line, parser.RFC5424Format)
+	version, ok := entry.Attribute("version")
+	if !ok {
+		return c.colorizeGenericLog(line)
+	}
+	// The header ends after the version and five more fields, each followed by a space
+	headerEnd := version.Span.End
+	for range 5 {
+		headerEnd += 1 + strings.IndexByte(line[headerEnd+1:], ' ')
+	}
+	headerEnd++
+	elements, end, ok := parser.ParseStructuredData(line, headerEnd)
+	if !ok {
 		return c.colorizeGenericLog(line)
 	}
 
-	timestamp := matches[1]
-	message := matches[2]
+	pri, _ := entry.Attribute("pri")
+	msgid, _ := entry.Attribute("msgid")
+	header, pos := c.colorizeFields(line, 0, []styledField{
+		{pri, c.styled(c.priorityStyle(pri.Value))},
+		{version, c.highlightPlain},
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{entry.Host, c.styled(c.theme.Hostname)},
+		{entry.Process, c.styled(c.theme.Service)},
+		{entry.PID, c.styled(c.theme.PID)},
+		{msgid, c.styled(c.theme.JSONKey)},
+	}, c.colorizeSeparators)
 
 	result := strings.Builder{}
-	// Apply search highlighting during colorization (single-pass)
-	result.WriteString(c.applySearchHighlighting(timestamp, c.theme.Timestamp))
-	result.WriteString(" ")
-	result.WriteString(c.colorizeMessageWithHighlighting(message))
+	result.WriteString(header)
+	result.WriteString(c.colorizeSeparators(line[pos:headerEnd]))
+	if len(elements) == 0 {
+		result.WriteString(c.applySearchHighlighting("-", c.theme.Bracket))
+	}
+	for _, element := range elements {
+		c.writeSDElement(&result, line, element)
+	}
+	result.WriteString(c.colorizeMessageWithHighlighting(line[end:]))
 
 	return result.String()
 }
 
-func (c *Colorizer) colorizeRails(line string) string {
-	// Rails format: "[2025-01-19 10:30:00] ERROR -- : Database connection failed"
-	// WEBrick format: "[2025-01-19 10:30:00] INFO  WEBrick 1.4.4"
-
-	// Try Rails format first
-	railsRe := regexp.MustCompile(`^(\[[^\]]+\]) (\w+) (--) : (.*)`)
-	matches := railsRe.FindStringSubmatch(line)
-
-	if len(matches) == 5 {
-		timestamp := matches[1]
-		level := matches[2]
-		separator := matches[3]
-		message := matches[4]
-
-		result := strings.Builder{}
-		result.WriteString(c.theme.Bracket.Render("["))
-		timestampContent := timestamp[1 : len(timestamp)-1] // Remove brackets
-		// Apply search highlighting during colorization (single-pass)
-		result.WriteString(c.applySearchHighlighting(timestampContent, c.theme.Timestamp))
-		result.WriteString(c.theme.Bracket.Render("] "))
-		result.WriteString(c.applySearchHighlighting(level, c.theme.GetLogLevelStyle(level)))
-		result.WriteString(" ")
-		result.WriteString(c.applySearchHighlighting(separator, lipgloss.NewStyle()))
-		result.WriteString(" : ")
-		result.WriteString(c.applySearchHighlighting(message, lipgloss.NewStyle()))
+// writeSDElement colors a structured data element, such as [origin@32473 ip="10.0.0.5"]
+func (c *Colorizer) writeSDElement(result *strings.Builder, line string, element parser.SDElement) {
+	result.WriteString(c.applySearchHighlighting("[", c.theme.Bracket))
+	result.WriteString(c.applySearchHighlighting(element.ID.Value, c.theme.Service))
+	for _, param := range element.Params {
+		result.WriteString(c.highlightPlain(" "))
+		result.WriteString(c.applySearchHighlighting(param.Name.Value, c.theme.LogfmtKey))
+		result.WriteString(c.applySearchHighlighting("=", c.theme.Equals))
+		result.WriteString(c.applySearchHighlighting(`"`, c.theme.Quote))
+		// The raw value, since the parsed one has its escapes decoded
+		result.WriteString(c.applySearchHighlighting(line[param.Value.Span.Start:param.Value.Span.End], c.theme.LogfmtValue))
+		result.WriteString(c.applySearchHighlighting(`"`, c.theme.Quote))
+	}
+	result.WriteString(c.applySearchHighlighting("]", c.theme.Bracket))
+}
 
-		return result.String()
+func (c *Colorizer) colorizeGoStandard(line string) string {
+	// Go standard format: "2025/01/19 10:30:00 ERROR: Database connection failed",
+	// with a "main.go:42: " source location when the logger records one
+	entry := parser.ParseAs(line, parser.GoStandardFormat)
+	if !entry.Message.Found() {
+		return c.colorizeGenericLog(line)
 	}
 
-	// Try WEBrick format
-	webrickRe := regexp.MustCompile(`^(\[[^\]]+\]) (\w+)\s+(.*)`)
-	matches = webrickRe.FindStringSubmatch(line)
-
-	if len(matches) == 4 {
-		timestamp := matches[1]
-		level := matches[2]
-		message := matches[3]
-
-		result := strings.Builder{}
-		result.WriteString(c.theme.Bracket.Render("["))
-		timestampContent := timestamp[1 : len(timestamp)-1] // Remove brackets
-		// Apply search highlighting during colorization (single-pass)
-		result.WriteString(c.applySearchHighlighting(timestampContent, c.theme.Timestamp))
-		result.WriteString(c.theme.Bracket.Render("] "))
-		result.WriteString(c.applySearchHighlighting(level, c.theme.GetLogLevelStyle(level)))
-		result.WriteString(" ")
-		result.WriteString(c.applySearchHighlighting(message, c.theme.JSONValue))
+	// Apply search highlighting during colorization (single-pass)
+	result, _ := c.colorizeFields(line, 0, []styledField{
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{entry.File, c.styled(c.theme.Filename)},
+		{entry.Line, c.styled(c.theme.LineNum)},
+		{entry.Message, c.colorizeMessageWithHighlighting},
+	}, c.highlightPlain)
+	return result
+}
 
-		return result.String()
+func (c *Colorizer) colorizeRails(line string) string {
+	// Rails format: "[2025-01-19 10:30:00] ERROR -- : Database connection failed"
+	// WEBrick format: "[2025-01-19 10:30:00] INFO  WEBrick 1.4.4"
+	entry := parser.ParseAs(line, parser.RailsFormat)
+	if !entry.Message.Found() {
+		return c.colorizeGenericLog(line)
 	}
 
-	return c.colorizeGenericLog(line)
+	// Apply search highlighting during colorization (single-pass)
+	header, pos := c.colorizeFields(line, 0, []styledField{
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{entry.PID, c.styled(c.theme.PID)},
+		{entry.LevelText, c.styled(c.theme.GetLogLevelStyle(entry.LevelText.Value))},
+	}, c.colorizeSeparators)
+
+	// Rails separates the message with "-- : ", WEBrick only with spaces
+	separator := line[pos:entry.Message.Span.Start]
+	messageStyle := c.theme.JSONValue
+	if strings.Contains(separator, "--") {
+		messageStyle = lipgloss.NewStyle()
+	}
+	return header + c.highlightPlain(separator) + c.applySearchHighlighting(entry.Message.Value, messageStyle)
 }
 
 func (c *Colorizer) colorizeDocker(line string) string {
 	// Docker format: "2025-01-19T10:30:00.123456789Z ERROR Database connection failed"
-	re := regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d+Z)\s+([A-Z]+)\s+(.*)`)
-	matches := re.FindStringSubmatch(line)
+	entry := parser.ParseAs(line, parser.DockerFormat)
+	if !entry.Message.Found() {
+		return c.colorizeGenericLog(line)
+	}
+
+	// Apply search highlighting during colorization (single-pass)
+	result, _ := c.colorizeFields(line, 0, []styledField{
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{entry.LevelText, c.styled(c.theme.GetLogLevelStyle(entry.LevelText.Value))},
+		{entry.Message, c.styled(lipgloss.NewStyle())},
+	}, c.highlightPlain)
+	return result
+}
 
-	if len(matches) != 4 {
+func (c *Colorizer) colorizeKubernetes(line string) string {
+	// Kubernetes format: "2025-01-19T10:30:00.123Z 1 main.go:42] ERROR Database connection failed"
+	entry := parser.ParseAs(line, parser.KubernetesFormat)
+	thread, ok := entry.Attribute("thread")
+	if !ok {
 		return c.colorizeGenericLog(line)
 	}
 
-	timestamp := matches[1]
-	level := matches[2]
-	message := matches[3]
+	// Apply search highlighting during colorization (single-pass)
+	header, pos := c.colorizeFields(line, 0, []styledField{
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{thread, c.styled(c.theme.PID)},
+		{entry.File, c.styled(c.theme.Filename)},
+		{entry.Line, c.styled(c.theme.LineNum)},
+	}, c.highlightPlain)
 
 	result := strings.Builder{}
-	// Apply search highlighting during colorization (single-pass)
-	result.WriteString(c.applySearchHighlighting(timestamp, c.theme.Timestamp))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(level, c.theme.GetLogLevelStyle(level)))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(message, lipgloss.NewStyle()))
+	result.WriteString(header)
+	result.WriteString(c.applySearchHighlighting(line[pos:entry.Message.Span.Start], c.theme.Bracket))
+	result.WriteString(c.colorizeMessageWithHighlighting(entry.Message.Value))
 
 	return result.String()
 }
 
-func (c *Colorizer) colorizeKubernetes(line string) string {
-	// Kubernetes format: "2025-01-19T10:30:00.123Z 1 main.go:42] ERROR Database connection failed"
-	re := regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d+Z) (\d+) ([^:]+):(\d+)\] (.*)`)
-	matches := re.FindStringSubmatch(line)
-
-	if len(matches) != 6 {
+func (c *Colorizer) colorizeKlog(line string) string {
+	// Klog format: "I0119 10:30:00.123456       1 main.go:42] "Pod status updated" pod="kube-system/coredns""
+	entry := parser.ParseAs(line, parser.KlogFormat)
+	thread, ok := entry.Attribute("thread")
+	if !ok {
 		return c.colorizeGenericLog(line)
 	}
 
-	timestamp := matches[1]
-	severity := matches[2]
-	filename := matches[3]
-	lineNum := matches[4]
-	message := matches[5]
-
 	result := strings.Builder{}
 	// Apply search highlighting during colorization (single-pass)
-	result.WriteString(c.applySearchHighlighting(timestamp, c.theme.Timestamp))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(severity, c.theme.PID))
-	result.WriteString(" ")
-	result.WriteString(c.applySearchHighlighting(filename, c.theme.Filename))
-	result.WriteString(":")
-	result.WriteString(c.applySearchHighlighting(lineNum, c.theme.LineNum))
-	result.WriteString(c.theme.Bracket.Render("] "))
-	result.WriteString(c.colorizeMessageWithHighlighting(message))
+	header, pos := c.colorizeFields(line, 0, []styledField{
+		{entry.LevelText, c.styled(c.theme.GetLogLevelStyle(entry.Level.String()))},
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{thread, c.styled(c.theme.PID)},
+		{entry.File, c.styled(c.theme.Filename)},
+		{entry.Line, c.styled(c.theme.LineNum)},
+	}, c.highlightPlain)
+	result.WriteString(header)
+	end := pos + len("] ")
+	result.WriteString(c.applySearchHighlighting(line[pos:end], c.theme.Bracket))
+
+	// Structured lines quote the message and follow it with key="value" pairs
+	message := entry.Message.Span
+	if message.Start == end || message.End == len(line) {
+		result.WriteString(c.colorizeMessageWithHighlighting(line[end:]))
+		return result.String()
+	}
+	result.WriteString(c.applySearchHighlighting(`"`, c.theme.Quote))
+	result.WriteString(c.colorizeMessageWithHighlighting(line[message.Start:message.End]))
+	result.WriteString(c.applySearchHighlighting(`"`, c.theme.Quote))
+	result.WriteString(c.colorizeAttributes(line, message.End+1, entry.Attributes))
 
 	return result.String()
 }
 
 func (c *Colorizer) colorizeHeroku(line string) string {
 	// Heroku format: "2025-01-19T10:30:00+00:00 app[web.1]: ERROR Database connection failed"
-	re := regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}[+-]\d{2}:\d{2}) app\[([^\]]+)\]: (.*)`)
-	matches := re.FindStringSubmatch(line)
-
-	if len(matches) != 4 {
+	entry := parser.ParseAs(line, parser.HerokuFormat)
+	dyno, ok := entry.Attribute("dyno")
+	if !ok {
 		return c.colorizeGenericLog(line)
 	}
 
-	timestamp := matches[1]
-	dyno := matches[2]
-	message := matches[3]
-
-	result := strings.Builder{}
 	// Apply search highlighting during colorization (single-pass)
-	result.WriteString(c.applySearchHighlighting(timestamp, c.theme.Timestamp))
-	result.WriteString(" app")
-	result.WriteString(c.theme.Bracket.Render("["))
-	result.WriteString(c.applySearchHighlighting(dyno, c.theme.Service))
-	result.WriteString(c.theme.Bracket.Render("]: "))
-	result.WriteString(c.colorizeMessageWithHighlighting(message))
-
-	return result.String()
+	result, _ := c.colorizeFields(line, 0, []styledField{
+		{entry.Timestamp.Field, c.styled(c.theme.Timestamp)},
+		{dyno, c.styled(c.theme.Service)},
+		{entry.Message, c.colorizeMessageWithHighlighting},
+	}, c.colorizeSeparators)
+	return result
 }
 
 // colorizeMessageWithHighlighting colors message parts with integrated search highlighting
@@ -801,14 +990,14 @@ func (c *Colorizer) colorizeMessageWithHighlighting(message string) string {
 		if strings.HasSuffix(firstWord, ":") {
 			styledLevel := c.applySearchHighlighting(cleanWord, c.theme.GetLogLevelStyle(cleanWord))
 			result.WriteString(styledLevel)
-			result.WriteString(":")
+			result.WriteString(c.highlightPlain(":"))
 		} else {
 			styledLevel := c.applySearchHighlighting(cleanWord, c.theme.GetLogLevelStyle(cleanWord))
 			result.WriteString(styledLevel)
 		}
 
 		if len(parts) > 1 {
-			result.WriteString(" ")
+			result.WriteString(c.highlightPlain(" "))
 			remainingMessage := strings.Join(parts[1:], " ")
 			result.WriteString(c.applySearchHighlighting(remainingMessage, lipgloss.NewStyle()))
 		}
@@ -826,7 +1015,7 @@ func (c *Colorizer) colorizeGenericLog(line string) string {
 
 	for i, word := range words {
 		if i > 0 {
-			result.WriteString(" ")
+			result.WriteString(c.highlightPlain(" "))
 		}
 
 		cleanWord := strings.TrimSuffix(word, ":")
@@ -834,7 +1023,7 @@ func (c *Colorizer) colorizeGenericLog(line string) string {
 			if strings.HasSuffix(word, ":") {
 				styledLevel := c.applySearchHighlighting(cleanWord, c.theme.GetLogLevelStyle(cleanWord))
 				result.WriteString(styledLevel)
-				result.WriteString(":")
+				result.WriteString(c.highlightPlain(":"))
 			} else {
 				styledLevel := c.applySearchHighlighting(cleanWord, c.theme.GetLogLevelStyle(cleanWord))
 				result.WriteString(styledLevel)
@@ -853,8 +1042,7 @@ func (c *Colorizer) colorizeGoTest(line string) string {
 
 	// Package skip lines: ? github.com/path [no test files]
 	if strings.HasPrefix(line, "? ") && strings.Contains(line, "[no test files]") {
-		re := regexp.MustCompile(`^(\? )([^[]+)(\[no test files\])`)
-		matches := re.FindStringSubmatch(line)
+		matches := goTestNoFilesRegex.FindStringSubmatch(line)
 		if len(matches) == 4 {
 			result := strings.Builder{}
 			result.WriteString(c.applySearchHighlighting(matches[1], c.theme.StatusWarn.Bold(true)))
@@ -864,76 +1052,51 @@ func (c *Colorizer) colorizeGoTest(line string) string {
 		}
 	}
 
-	// Test execution lines: === RUN TestName or === RUN TestName/subtest
-	if strings.HasPrefix(line, "=== RUN ") {
-		re := regexp.MustCompile(`^(=== RUN )([ \t]+)?(.*)`)
-		matches := re.FindStringSubmatch(line)
-		if len(matches) >= 3 {
-			result := strings.Builder{}
-			// Make RUN keyword very prominent
-			result.WriteString(c.applySearchHighlighting("=== RUN", c.theme.Info.Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "6", Dark: "14"})))
-			if len(matches) > 3 && matches[2] != "" {
-				result.WriteString(matches[2]) // preserve whitespace
-			} else {
-				result.WriteString(" ")
-			}
-			// Make test name very prominent
-			testName := matches[len(matches)-1]
-			result.WriteString(c.applySearchHighlighting(testName, c.theme.Service.Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "5", Dark: "13"})))
-			return result.String()
-		}
-	}
-
-	// Test naming lines: === NAME TestName
-	if strings.HasPrefix(line, "=== NAME ") {
-		if result := c.formatTestMarkerLine(line, "=== NAME"); result != "" {
-			return result
-		}
-	}
+	entry := parser.ParseAs(line, parser.GoTestFormat)
 
-	// Test continuation lines: === CONT TestName
-	if strings.HasPrefix(line, "=== CONT ") {
-		if result := c.formatTestMarkerLine(line, "=== CONT"); result != "" {
-			return result
+	// Test execution lines: === RUN TestName or === RUN TestName/subtest,
+	// and the === NAME and === CONT lines of parallel tests
+	if action, ok := entry.Attribute("action"); ok {
+		switch action.Value {
+		case "RUN":
+			// Make RUN keyword and test name very prominent
+			return c.formatTestMarkerLine(line, entry,
+				c.theme.Info.Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "6", Dark: "14"}),
+				c.theme.Service.Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "5", Dark: "13"}))
+		case "NAME", "CONT":
+			return c.formatTestMarkerLine(line, entry, c.theme.Info.Bold(true), c.theme.Service.Bold(true))
 		}
 	}
 
 	// Test result lines: --- PASS: TestName (duration) or --- PASS: TestName
-	if strings.HasPrefix(line, "--- ") {
-		re := regexp.MustCompile(`^(--- )(PASS|FAIL|SKIP)(: )([ \t]*)?([^(]+?)(\s*\([^)]*\))?(\s*)$`)
-		matches := re.FindStringSubmatch(line)
-		if len(matches) >= 6 {
-			result := strings.Builder{}
-			result.WriteString(c.applySearchHighlighting(matches[1], lipgloss.NewStyle()))
-
-			// Color result based on status with bold emphasis
-			status := matches[2]
-			switch status {
-			case "PASS":
-				result.WriteString(c.applySearchHighlighting(status, c.theme.StatusOK.Bold(true)))
-			case "FAIL":
-				result.WriteString(c.applySearchHighlighting(status, c.theme.StatusError.Bold(true)))
-			case "SKIP":
-				result.WriteString(c.applySearchHighlighting(status, c.theme.StatusWarn.Bold(true)))
-			}
+	result, isResult := entry.Attribute("result")
+	if test, ok := entry.Attribute("test"); ok && isResult {
+		out := strings.Builder{}
+		out.WriteString(c.applySearchHighlighting(line[:result.Span.Start], lipgloss.NewStyle()))
+
+		// Color result based on status with bold emphasis
+		switch result.Value {
+		case "PASS":
+			out.WriteString(c.applySearchHighlighting(result.Value, c.theme.StatusOK.Bold(true)))
+		case "FAIL":
+			out.WriteString(c.applySearchHighlighting(result.Value, c.theme.StatusError.Bold(true)))
+		case "SKIP":
+			out.WriteString(c.applySearchHighlighting(result.Value, c.theme.StatusWarn.Bold(true)))
+		}
 
-			result.WriteString(c.applySearchHighlighting(matches[3], lipgloss.NewStyle()))
-			if len(matches) > 4 && matches[4] != "" {
-				result.WriteString(matches[4]) // preserve whitespace
-			}
-			// Make test name prominent
-			testName := strings.TrimSpace(matches[5])
-			result.WriteString(c.applySearchHighlighting(testName, c.theme.Service.Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "5", Dark: "13"})))
+		out.WriteString(c.applySearchHighlighting(line[result.Span.End:test.Span.Start], lipgloss.NewStyle()))
+		// Make test name prominent
+		out.WriteString(c.applySearchHighlighting(test.Value, c.theme.Service.Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "5", Dark: "13"})))
 
-			// Duration in subtle color
-			if len(matches) > 6 && matches[6] != "" {
-				result.WriteString(c.applySearchHighlighting(matches[6], c.theme.Timestamp))
-			}
-			if len(matches) > 7 && matches[7] != "" {
-				result.WriteString(matches[7]) // trailing whitespace
-			}
-			return result.String()
+		// Duration in subtle color, parentheses included
+		pos := test.Span.End
+		if duration, ok := entry.Attribute("duration"); ok {
+			end := min(duration.Span.End+len(")"), len(line))
+			out.WriteString(c.applySearchHighlighting(line[pos:end], c.theme.Timestamp))
+			pos = end
 		}
+		out.WriteString(c.highlightPlain(line[pos:])) // trailing whitespace
+		return out.String()
 	}
 
 	// Package result lines: PASS or FAIL (standalone) - make them very prominent
@@ -944,18 +1107,13 @@ func (c *Colorizer) colorizeGoTest(line string) string {
 		return c.applySearchHighlighting(line, c.theme.StatusError.Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "1", Dark: "9"}))
 	}
 
-	// Package completion: ok github.com/path duration
-	if strings.HasPrefix(line, "ok ") {
-		if result := c.formatPackageResultLine(line, "ok ", c.theme.StatusOK.Bold(true)); result != "" {
-			return result
-		}
-	}
-
-	// Package failure: FAIL github.com/path duration
-	if strings.HasPrefix(line, "FAIL ") {
-		if result := c.formatPackageResultLine(line, "FAIL ", c.theme.StatusError.Bold(true)); result != "" {
-			return result
+	// Package completion and failure: ok github.com/path duration or FAIL github.com/path duration
+	if _, ok := entry.Attribute("package"); ok && isResult {
+		style := c.theme.StatusOK.Bold(true)
+		if result.Value == "FAIL" {
+			style = c.theme.StatusError.Bold(true)
 		}
+		return c.formatPackageResultLine(line, entry, style)
 	}
 
 	// Handle mixed log output (timestamps, GIN logs, etc.) within Go test context
@@ -970,21 +1128,7 @@ func (c *Colorizer) colorizeGoTest(line string) string {
 
 // Helper functions for GoTest colorizer
 func (c *Colorizer) containsTimestamp(line string) bool {
-	// Check for various timestamp patterns
-	timestampPatterns := []string{
-		`\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`,     // Go standard: 2025/07/30 08:23:42
-		`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}`,     // ISO format: 2025-07-30T08:23:42
-		`\[\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\]`, // Rails format: [2025-07-30 08:23:42]
-		`\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2}`,     // Apache format: 30/Jul/2025:08:23:42
-	}
-
-	for _, pattern := range timestampPatterns {
-		matched, _ := regexp.MatchString(pattern, line)
-		if matched {
-			return true
-		}
-	}
-	return false
+	return timestampRegex.MatchString(line)
 }
 
 func (c *Colorizer) containsLogLevel(line string) bool {
@@ -1002,74 +1146,103 @@ func (c *Colorizer) containsLogLevel(line string) bool {
 
 // stripAnsiCodes removes ANSI escape sequences from text
 func (c *Colorizer) stripAnsiCodes(text string) string {
-	// ANSI escape sequence pattern: \033[...m or \x1b[...m
-	ansiRegex := regexp.MustCompile(`\033\[[0-9;]*m|\x1b\[[0-9;]*m`)
 	return ansiRegex.ReplaceAllString(text, "")
 }
 
-// matchesSearch checks if a line matches the current search pattern
-func (c *Colorizer) matchesSearch(line string) bool {
-	if c.searchRegex != nil {
-		return c.searchRegex.MatchString(line)
-	}
-	if c.searchString != "" {
-		return strings.Contains(line, c.searchString)
-	}
-	return false
+// formatTestMarkerLine formats Go test marker lines like "=== RUN TestName" and "=== NAME TestName"
+func (c *Colorizer) formatTestMarkerLine(line string, entry parser.LogEntry, markerStyle, nameStyle lipgloss.Style) string {
+	action, _ := entry.Attribute("action")
+	test, _ := entry.Attribute("test")
+
+	result := strings.Builder{}
+	result.WriteString(c.applySearchHighlighting(line[:action.Span.End], markerStyle))
+	result.WriteString(c.highlightPlain(line[action.Span.End:test.Span.Start])) // preserve whitespace
+	result.WriteString(c.applySearchHighlighting(test.Value, nameStyle))
+	result.WriteString(c.highlightPlain(line[test.Span.End:]))
+	return result.String()
 }
 
-// formatTestMarkerLine formats Go test marker lines like "=== NAME TestName" and "=== CONT TestName"
-func (c *Colorizer) formatTestMarkerLine(line, marker string) string {
-	re := regexp.MustCompile(`^(` + regexp.QuoteMeta(marker) + ` )([ \t]+)?(.*)`)
-	matches := re.FindStringSubmatch(line)
-	if len(matches) >= 3 {
-		result := strings.Builder{}
-		result.WriteString(c.applySearchHighlighting(marker, c.theme.Info.Bold(true)))
-		if len(matches) > 3 && matches[2] != "" {
-			result.WriteString(matches[2])
-		} else {
-			result.WriteString(" ")
+// formatPackageResultLine formats Go package result lines like "ok github.com/path duration" and "FAIL github.com/path duration"
+func (c *Colorizer) formatPackageResultLine(line string, entry parser.LogEntry, style lipgloss.Style) string {
+	result, _ := entry.Attribute("result")
+	pkg, _ := entry.Attribute("package")
+	duration, _ := entry.Attribute("duration")
+
+	out := strings.Builder{}
+	out.WriteString(c.applySearchHighlighting(result.Value, style))
+	out.WriteString(c.highlightPlain(line[result.Span.End:pkg.Span.Start]))
+	out.WriteString(c.applySearchHighlighting(pkg.Value, c.theme.Service.Bold(true)))
+	out.WriteString(c.highlightPlain(line[pkg.Span.End:duration.Span.Start]))
+	out.WriteString(c.applySearchHighlighting(duration.Value, c.theme.Timestamp))
+	// Anything after the duration, such as the coverage, in the same subtle color
+	if rest := line[duration.Span.End:]; rest != "" {
+		coverage := strings.TrimLeft(rest, " \t")
+		out.WriteString(c.highlightPlain(rest[:len(rest)-len(coverage)]))
+		if coverage != "" {
+			out.WriteString(c.applySearchHighlighting(coverage, c.theme.Timestamp))
 		}
-		testName := matches[len(matches)-1]
-		result.WriteString(c.applySearchHighlighting(testName, c.theme.Service.Bold(true)))
-		return result.String()
 	}
-	return ""
+	return out.String()
 }
 
-// formatPackageResultLine formats Go package result lines like "ok github.com/path duration" and "FAIL github.com/path duration"
-func (c *Colorizer) formatPackageResultLine(line, prefix string, style lipgloss.Style) string {
-	re := regexp.MustCompile(`^(` + regexp.QuoteMeta(prefix) + `)([ \t]+)?([^ ]+)([ \t]+)(.*)`)
-	matches := re.FindStringSubmatch(line)
-	if len(matches) == 6 {
-		result := strings.Builder{}
-		result.WriteString(c.applySearchHighlighting(matches[1], style))
-		if matches[2] != "" {
-			result.WriteString(matches[2])
+// colorizeExceptionMessage colors the ": message" that follows the exception
+// class of a stack trace header, when the line has one
+func (c *Colorizer) colorizeExceptionMessage(line string, exception, message parser.Field) string {
+	if !message.Found() {
+		return c.highlightPlain(line[exception.Span.End:])
+	}
+	result := strings.Builder{}
+	result.WriteString(c.applySearchHighlighting(line[exception.Span.End:message.Span.Start], c.theme.Equals)) // ": "
+	result.WriteString(c.applySearchHighlighting(message.Value, c.theme.JSONString))                           // message
+	return result.String()
+}
+
+// colorizeFileLocation colors the file, line number and, for JavaScript, the
+// column of a stack frame, along with the rest of the line
+func (c *Colorizer) colorizeFileLocation(line string, entry parser.LogEntry) string {
+	result := strings.Builder{}
+	// File name and line number with prominent styling - bright cyan and bright magenta, bold
+	pos := entry.File.Span.Start
+	fields := []parser.Field{entry.File, entry.Line}
+	if column, ok := entry.Attribute("column"); ok {
+		fields = append(fields, column)
+	}
+	for i, field := range fields {
+		if i > 0 {
+			result.WriteString(c.applySearchHighlighting(line[pos:field.Span.Start], c.theme.Equals)) // ":"
 		}
-		result.WriteString(c.applySearchHighlighting(matches[3], c.theme.Service.Bold(true)))
-		result.WriteString(matches[4])
-		result.WriteString(c.applySearchHighlighting(matches[5], c.theme.Timestamp))
-		return result.String()
+		style := stackLineStyle
+		if i == 0 {
+			style = stackFileStyle
+		}
+		result.WriteString(c.applySearchHighlighting(field.Value, style))
+		pos = field.Span.End
+	}
+	if rest := line[pos:]; strings.HasPrefix(rest, ")") {
+		result.WriteString(c.applySearchHighlighting(")", c.theme.Bracket))
+		pos++
 	}
-	return ""
+	if pos < len(line) {
+		result.WriteString(c.applySearchHighlighting(line[pos:], c.theme.JSONValue)) // any trailing text
+	}
+	return result.String()
 }
 
 // colorizeJavaException colorizes Java exception stack traces with prominent file/line highlighting
 func (c *Colorizer) colorizeJavaException(line string) string {
+	entry := parser.ParseAs(line, parser.JavaExceptionFormat)
+	exception, isException := entry.Attribute("exception")
+
 	// Handle exception header lines (Exception in thread "main" java.lang.ArithmeticException: / by zero)
 	if strings.HasPrefix(line, "Exception in thread") {
 		// Parse exception header: Exception in thread "thread-name" ExceptionClass: message
-		exceptionHeaderRegex := regexp.MustCompile(`^(Exception in thread ")([^"]+)(" )([^\s:]+)(: ?)(.*)`)
-		matches := exceptionHeaderRegex.FindStringSubmatch(line)
-		if len(matches) == 7 {
+		if thread, ok := entry.Attribute("thread"); ok {
 			result := strings.Builder{}
-			result.WriteString(c.applySearchHighlighting(matches[1], c.theme.StatusError.Bold(true))) // "Exception in thread "
-			result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Service.Bold(true)))     // thread name
-			result.WriteString(c.applySearchHighlighting(matches[3], c.theme.StatusError.Bold(true))) // "
-			result.WriteString(c.applySearchHighlighting(matches[4], c.theme.StatusError.Bold(true))) // ExceptionClass
-			result.WriteString(c.applySearchHighlighting(matches[5], c.theme.Equals))                 // ": "
-			result.WriteString(c.applySearchHighlighting(matches[6], c.theme.JSONString))             // message
+			result.WriteString(c.applySearchHighlighting(line[:thread.Span.Start], c.theme.StatusError.Bold(true)))                   // "Exception in thread "
+			result.WriteString(c.applySearchHighlighting(thread.Value, c.theme.Service.Bold(true)))                                   // thread name
+			result.WriteString(c.applySearchHighlighting(line[thread.Span.End:exception.Span.Start], c.theme.StatusError.Bold(true))) // "
+			result.WriteString(c.applySearchHighlighting(exception.Value, c.theme.StatusError.Bold(true)))                            // ExceptionClass
+			result.WriteString(c.colorizeExceptionMessage(line, exception, entry.Message))
 			return result.String()
 		}
 		// Fallback for other exception headers
@@ -1078,39 +1251,25 @@ func (c *Colorizer) colorizeJavaException(line string) string {
 
 	// Handle "Caused by:" lines
 	if strings.HasPrefix(strings.TrimSpace(line), "Caused by:") {
-		causedByRegex := regexp.MustCompile(`^(\s*)(Caused by: )([^\s:]+)(: ?)(.*)`)
-		matches := causedByRegex.FindStringSubmatch(line)
-		if len(matches) == 6 {
+		if isException {
+			causedBy := strings.Index(line, "Caused by:")
 			result := strings.Builder{}
-			result.WriteString(matches[1])                                                            // leading whitespace
-			result.WriteString(c.applySearchHighlighting(matches[2], c.theme.StatusWarn.Bold(true)))  // "Caused by: "
-			result.WriteString(c.applySearchHighlighting(matches[3], c.theme.StatusError.Bold(true))) // ExceptionClass
-			result.WriteString(c.applySearchHighlighting(matches[4], c.theme.Equals))                 // ": "
-			result.WriteString(c.applySearchHighlighting(matches[5], c.theme.JSONString))             // message
+			result.WriteString(c.highlightPlain(line[:causedBy]))                                                             // leading whitespace
+			result.WriteString(c.applySearchHighlighting(line[causedBy:exception.Span.Start], c.theme.StatusWarn.Bold(true))) // "Caused by: "
+			result.WriteString(c.applySearchHighlighting(exception.Value, c.theme.StatusError.Bold(true)))                    // ExceptionClass
+			result.WriteString(c.colorizeExceptionMessage(line, exception, entry.Message))
 			return result.String()
 		}
 		return c.applySearchHighlighting(line, c.theme.StatusWarn.Bold(true))
 	}
 
 	// Handle stack trace lines (	at com.example.MyClass.method(MyClass.java:10))
-	stackTraceRegex := regexp.MustCompile(`^(\s+at\s+)([^(]+)(\()([^:]+):(\d+)(\))(.*)`)
-	matches := stackTraceRegex.FindStringSubmatch(line)
-	if len(matches) == 8 {
+	if function, ok := entry.Attribute("function"); ok {
 		result := strings.Builder{}
-		result.WriteString(c.applySearchHighlighting(matches[1], c.theme.Bracket)) // "	at "
-		result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Service)) // method path
-		result.WriteString(c.applySearchHighlighting(matches[3], c.theme.Bracket)) // "("
-		// File name with prominent styling - bright cyan, bold
-		fileStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0066CC", Dark: "#66CCFF"}).Bold(true)
-		result.WriteString(c.applySearchHighlighting(matches[4], fileStyle)) // filename
-		result.WriteString(c.applySearchHighlighting(":", c.theme.Equals))   // ":"
-		// Line number with prominent styling - bright magenta, bold
-		lineStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#CC0066", Dark: "#FF66CC"}).Bold(true)
-		result.WriteString(c.applySearchHighlighting(matches[5], lineStyle))       // line number
-		result.WriteString(c.applySearchHighlighting(matches[6], c.theme.Bracket)) // ")"
-		if matches[7] != "" {
-			result.WriteString(c.applySearchHighlighting(matches[7], c.theme.JSONValue)) // any trailing text
-		}
+		result.WriteString(c.applySearchHighlighting(line[:function.Span.Start], c.theme.Bracket))                    // "	at "
+		result.WriteString(c.applySearchHighlighting(function.Value, c.theme.Service))                                // method path
+		result.WriteString(c.applySearchHighlighting(line[function.Span.End:entry.File.Span.Start], c.theme.Bracket)) // "("
+		result.WriteString(c.colorizeFileLocation(line, entry))
 		return result.String()
 	}
 
@@ -1130,33 +1289,34 @@ func (c *Colorizer) colorizePythonException(line string) string {
 		return c.applySearchHighlighting(line, c.theme.StatusError.Bold(true))
 	}
 
+	entry := parser.ParseAs(line, parser.PythonExceptionFormat)
+
 	// Handle File lines (  File "example_trace.py", line 21, in <module>)
-	fileLineRegex := regexp.MustCompile(`^(\s*)(File\s+")([^"]+)(",\s+line\s+)(\d+)(,\s+in\s+)(.*)`)
-	matches := fileLineRegex.FindStringSubmatch(line)
-	if len(matches) == 8 {
+	if entry.File.Found() {
+		file, lineNum := entry.File.Span, entry.Line.Span
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
 		result := strings.Builder{}
-		result.WriteString(matches[1])                                             // leading whitespace
-		result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Bracket)) // "File "
+		result.WriteString(c.highlightPlain(line[:indent]))                                     // leading whitespace
+		result.WriteString(c.applySearchHighlighting(line[indent:file.Start], c.theme.Bracket)) // "File "
 		// File name with prominent styling - bright cyan, bold (same as Java)
-		fileStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0066CC", Dark: "#66CCFF"}).Bold(true)
-		result.WriteString(c.applySearchHighlighting(matches[3], fileStyle))       // filename
-		result.WriteString(c.applySearchHighlighting(matches[4], c.theme.Bracket)) // ", line "
+		result.WriteString(c.applySearchHighlighting(entry.File.Value, stackFileStyle))              // filename
+		result.WriteString(c.applySearchHighlighting(line[file.End:lineNum.Start], c.theme.Bracket)) // ", line "
 		// Line number with prominent styling - bright magenta, bold (same as Java)
-		lineStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#CC0066", Dark: "#FF66CC"}).Bold(true)
-		result.WriteString(c.applySearchHighlighting(matches[5], lineStyle))       // line number
-		result.WriteString(c.applySearchHighlighting(matches[6], c.theme.Bracket)) // ", in "
-		result.WriteString(c.applySearchHighlighting(matches[7], c.theme.Service)) // function name
+		result.WriteString(c.applySearchHighlighting(entry.Line.Value, stackLineStyle)) // line number
+		if function, ok := entry.Attribute("function"); ok {
+			result.WriteString(c.applySearchHighlighting(line[lineNum.End:function.Span.Start], c.theme.Bracket)) // ", in "
+			result.WriteString(c.applySearchHighlighting(function.Value, c.theme.Service))                        // function name
+		} else if lineNum.End < len(line) {
+			result.WriteString(c.applySearchHighlighting(line[lineNum.End:], c.theme.JSONValue))
+		}
 		return result.String()
 	}
 
 	// Handle exception name lines (ZeroDivisionError: division by zero)
-	exceptionRegex := regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*Error)(:?\s*)(.*)`)
-	matches = exceptionRegex.FindStringSubmatch(line)
-	if len(matches) == 4 {
+	if exception, ok := entry.Attribute("exception"); ok {
 		result := strings.Builder{}
-		result.WriteString(c.applySearchHighlighting(matches[1], c.theme.StatusError.Bold(true))) // Exception class
-		result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Equals))                 // ": "
-		result.WriteString(c.applySearchHighlighting(matches[3], c.theme.JSONString))             // message
+		result.WriteString(c.applySearchHighlighting(exception.Value, c.theme.StatusError.Bold(true))) // Exception class
+		result.WriteString(c.colorizeExceptionMessage(line, exception, entry.Message))
 		return result.String()
 	}
 
@@ -1173,14 +1333,12 @@ func (c *Colorizer) colorizePythonException(line string) string {
 // formatFilePathMatch formats file path matches for stack traces with consistent styling
 func (c *Colorizer) formatFilePathMatch(matches []string) string {
 	result := strings.Builder{}
-	result.WriteString(matches[1]) // leading whitespace
+	result.WriteString(c.highlightPlain(matches[1])) // leading whitespace
 	// File path with prominent styling - bright cyan, bold (consistent with Java/Python)
-	fileStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0066CC", Dark: "#66CCFF"}).Bold(true)
-	result.WriteString(c.applySearchHighlighting(matches[2], fileStyle)) // file path
-	result.WriteString(c.applySearchHighlighting(":", c.theme.Equals))   // ":"
+	result.WriteString(c.applySearchHighlighting(matches[2], stackFileStyle)) // file path
+	result.WriteString(c.applySearchHighlighting(":", c.theme.Equals))        // ":"
 	// Line number with prominent styling - bright magenta, bold (consistent with Java/Python)
-	lineStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#CC0066", Dark: "#FF66CC"}).Bold(true)
-	result.WriteString(c.applySearchHighlighting(matches[3], lineStyle)) // line number
+	result.WriteString(c.applySearchHighlighting(matches[3], stackLineStyle)) // line number
 	if matches[4] != "" {
 		result.WriteString(c.applySearchHighlighting(matches[4], c.theme.JSONValue)) // offset (optional)
 	}
@@ -1189,63 +1347,38 @@ func (c *Colorizer) formatFilePathMatch(matches []string) string {
 
 // colorizeGoroutineStackTrace colorizes Go goroutine stack traces with prominent file/line highlighting
 func (c *Colorizer) colorizeGoroutineStackTrace(line string) string {
+	entry := parser.ParseAs(line, parser.GoroutineStackTraceFormat)
+
 	// Handle goroutine header lines (goroutine 1 [running]:)
-	goroutineHeaderRegex := regexp.MustCompile(`^(goroutine\s+)(\d+)(\s+\[)([^\]]+)(\]:\s*)(.*)`)
-	matches := goroutineHeaderRegex.FindStringSubmatch(line)
-	if len(matches) == 7 {
+	if goroutine, ok := entry.Attribute("goroutine"); ok {
+		state, _ := entry.Attribute("state")
+		rest := strings.TrimLeft(line[state.Span.End+len("]:"):], " \t")
+		restStart := len(line) - len(rest)
 		result := strings.Builder{}
-		result.WriteString(c.applySearchHighlighting(matches[1], c.theme.Info.Bold(true)))       // "goroutine "
-		result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Service.Bold(true)))    // goroutine number
-		result.WriteString(c.applySearchHighlighting(matches[3], c.theme.Bracket))               // " ["
-		result.WriteString(c.applySearchHighlighting(matches[4], c.theme.StatusWarn.Bold(true))) // status (running/runnable)
-		result.WriteString(c.applySearchHighlighting(matches[5], c.theme.Bracket))               // "]:"
-		if matches[6] != "" {
-			result.WriteString(c.applySearchHighlighting(matches[6], c.theme.JSONValue))
+		result.WriteString(c.applySearchHighlighting(line[:goroutine.Span.Start], c.theme.Info.Bold(true)))       // "goroutine "
+		result.WriteString(c.applySearchHighlighting(goroutine.Value, c.theme.Service.Bold(true)))                // goroutine number
+		result.WriteString(c.applySearchHighlighting(line[goroutine.Span.End:state.Span.Start], c.theme.Bracket)) // " ["
+		result.WriteString(c.applySearchHighlighting(state.Value, c.theme.StatusWarn.Bold(true)))                 // status (running/runnable)
+		result.WriteString(c.applySearchHighlighting(line[state.Span.End:restStart], c.theme.Bracket))            // "]:"
+		if rest != "" {
+			result.WriteString(c.applySearchHighlighting(rest, c.theme.JSONValue))
 		}
 		return result.String()
 	}
 
-	// Pattern 1: Lines starting directly with whitespace and file path
+	// Handle indented file path lines
 	// Examples:         /Users/bill/go/src/runtime/asm_amd64.s:2232 +0x1
 	//          OR:      /Users/bill/go/src/runtime/proc.go:90
-	filePathOnlyRegex := regexp.MustCompile(`^(\s+)([^\s:]+):(\d+)(.*)`)
-	matches = filePathOnlyRegex.FindStringSubmatch(line)
-	if len(matches) == 5 {
-		return c.formatFilePathMatch(matches)
+	if entry.File.Found() {
+		return c.highlightPlain(line[:entry.File.Span.Start]) + c.colorizeFileLocation(line, entry)
 	}
 
-	// Pattern 2: Function name followed by whitespace and file path (UNUSED - this pattern doesn't match real stack traces)
-	// Examples: main.Example(0x2080c3f50, 0x2, 0x4, 0x425c0, 0x5, 0xa)
-	//           /Users/bill/Spaces/Go/Projects/src/github.com/goinaction/code/temp/main.go:9 +0x64
-	// This pattern is commented out because it doesn't occur in real Go stack traces
-	/*
-		stackTraceRegex := regexp.MustCompile(`^(\s+)([^/\s]+)(\s+)([^\s:]+):(\d+)\s+(.*)`)
-		matches = stackTraceRegex.FindStringSubmatch(line)
-		if len(matches) == 7 {
-			result := strings.Builder{}
-			result.WriteString(matches[1])                                             // leading whitespace
-			result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Service)) // function name
-			result.WriteString(matches[3])                                             // whitespace
-			// File path with prominent styling - bright cyan, bold (consistent with Java/Python)
-			fileStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0066CC", Dark: "#66CCFF"}).Bold(true)
-			result.WriteString(c.applySearchHighlighting(matches[4], fileStyle)) // file path
-			result.WriteString(c.applySearchHighlighting(":", c.theme.Equals))   // ":"
-			// Line number with prominent styling - bright magenta, bold (consistent with Java/Python)
-			lineStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#CC0066", Dark: "#FF66CC"}).Bold(true)
-			result.WriteString(c.applySearchHighlighting(matches[5], lineStyle)) // line number
-			result.WriteString(" ")
-			result.WriteString(c.applySearchHighlighting(matches[6], c.theme.JSONValue)) // offset (+0x64)
-			return result.String()
-		}
-	*/
-
 	// Handle function call lines with parameters
 	// Examples: main.Example(0x2080c3f50, 0x2, 0x4, 0x425c0, 0x5, 0xa) or main.main()
-	functionCallRegex := regexp.MustCompile(`^(\s*)([a-zA-Z_][a-zA-Z0-9_]*\.[a-zA-Z_][a-zA-Z0-9_]*)(\()([^)]*)(\))(.*)`)
-	matches = functionCallRegex.FindStringSubmatch(line)
+	matches := goroutineFunctionCallRegex.FindStringSubmatch(line)
 	if len(matches) == 7 {
 		result := strings.Builder{}
-		result.WriteString(matches[1])                                               // leading whitespace (optional)
+		result.WriteString(c.highlightPlain(matches[1]))                             // leading whitespace (optional)
 		result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Service))   // function name (main.Example)
 		result.WriteString(c.applySearchHighlighting(matches[3], c.theme.Bracket))   // "("
 		result.WriteString(c.applySearchHighlighting(matches[4], c.theme.JSONValue)) // parameters (can be empty)
@@ -1256,24 +1389,21 @@ func (c *Colorizer) colorizeGoroutineStackTrace(line string) string {
 		return result.String()
 	}
 
-	// Pattern 3: Filename patterns that may not have full paths
+	// Handle filename lines without an indent, which the parser doesn't split
 	// Examples: temp/main.go:9 +0x64 OR main.go:42
-	filenameOnlyRegex := regexp.MustCompile(`^(\s*)([^\s:]*\.go):(\d+)(.*)`)
-	matches = filenameOnlyRegex.FindStringSubmatch(line)
+	matches = goroutineFilenameRegex.FindStringSubmatch(line)
 	if len(matches) == 5 {
 		return c.formatFilePathMatch(matches)
 	}
 
-	// Pattern 4: Filepath fragments without line numbers (multiline stack traces)
+	// Handle filepath fragments without line numbers (multiline stack traces)
 	// Examples:         /Users/bill/Spaces/Go/Projects/src/github.com/goinaction/code/
-	filepathFragmentRegex := regexp.MustCompile(`^(\s+)(/[^\s]*/)$`)
-	matches = filepathFragmentRegex.FindStringSubmatch(line)
+	matches = goroutineFragmentRegex.FindStringSubmatch(line)
 	if len(matches) == 3 {
 		result := strings.Builder{}
-		result.WriteString(matches[1]) // leading whitespace
+		result.WriteString(c.highlightPlain(matches[1])) // leading whitespace
 		// File path with prominent styling - bright cyan, bold (consistent with Java/Python)
-		fileStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0066CC", Dark: "#66CCFF"}).Bold(true)
-		result.WriteString(c.applySearchHighlighting(matches[2], fileStyle)) // filepath fragment
+		result.WriteString(c.applySearchHighlighting(matches[2], stackFileStyle)) // filepath fragment
 		return result.String()
 	}
 
@@ -1293,74 +1423,48 @@ func (c *Colorizer) colorizeGoroutineStackTrace(line string) string {
 
 // colorizeJavaScriptException colorizes JavaScript exception traces with prominent file/line highlighting
 func (c *Colorizer) colorizeJavaScriptException(line string) string {
-	// Handle exception header lines (Error, TypeError:, Trace:, etc.)
-	exceptionHeaderRegex := regexp.MustCompile(`^(Error$|Trace:.*|TypeError:.*|ReferenceError:.*|SyntaxError:.*|RangeError:.*|EvalError:.*|URIError:.*|InternalError:.*|[A-Z][a-zA-Z]*Exception:.*)`)
-	matches := exceptionHeaderRegex.FindStringSubmatch(line)
-	if len(matches) >= 2 {
+	entry := parser.ParseAs(line, parser.JavaScriptExceptionFormat)
+
+	// Handle exception header lines (TypeError: message, ValidationError: message),
+	// and the bare "Error" and "Trace: message" headers console.trace prints
+	exception, isException := entry.Attribute("exception")
+	if isException || line == "Error" || strings.HasPrefix(line, "Trace:") {
+		typeEnd := len(line)
+		if isException {
+			typeEnd = exception.Span.End + len(":")
+		} else if i := strings.IndexByte(line, ':'); i >= 0 {
+			typeEnd = i + 1
+		}
 		result := strings.Builder{}
-		// Split the match to handle the error type and message separately
-		parts := strings.SplitN(matches[1], ":", 2)
-		if len(parts) >= 2 {
-			// Error type with message
-			result.WriteString(c.applySearchHighlighting(parts[0]+":", c.theme.StatusError.Bold(true)))
-			if len(parts[1]) > 0 {
-				result.WriteString(c.applySearchHighlighting(parts[1], c.theme.JSONString)) // error message
-			}
-		} else {
-			// Simple "Error" or "Trace:" without message
-			result.WriteString(c.applySearchHighlighting(matches[1], c.theme.StatusError.Bold(true)))
+		result.WriteString(c.applySearchHighlighting(line[:typeEnd], c.theme.StatusError.Bold(true)))
+		if typeEnd < len(line) {
+			result.WriteString(c.applySearchHighlighting(line[typeEnd:], c.theme.JSONString)) // error message
 		}
 		return result.String()
 	}
 
-	// Handle stack trace lines (    at sum (/home/dev/Documents/trace.js:2:17))
-	stackTraceRegex := regexp.MustCompile(`^(\s+at\s+)([^(]*)\s*(\()([^:)]+):(\d+):(\d+)(\))(.*)`)
-	matches = stackTraceRegex.FindStringSubmatch(line)
-	if len(matches) == 9 {
+	// Handle stack trace lines (    at sum (/home/dev/Documents/trace.js:2:17)),
+	// with or without a function name
+	if entry.File.Found() {
 		result := strings.Builder{}
-		result.WriteString(c.applySearchHighlighting(matches[1], c.theme.Bracket)) // "    at "
-		result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Service)) // function name
-		result.WriteString(c.applySearchHighlighting(matches[3], c.theme.Bracket)) // " ("
-		// File path with prominent styling - bright cyan, bold (consistent with other stack traces)
-		fileStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0066CC", Dark: "#66CCFF"}).Bold(true)
-		result.WriteString(c.applySearchHighlighting(matches[4], fileStyle)) // file path
-		result.WriteString(c.applySearchHighlighting(":", c.theme.Equals))   // ":"
-		// Line number with prominent styling - bright magenta, bold (consistent with other stack traces)
-		lineStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#CC0066", Dark: "#FF66CC"}).Bold(true)
-		result.WriteString(c.applySearchHighlighting(matches[5], lineStyle)) // line number
-		result.WriteString(c.applySearchHighlighting(":", c.theme.Equals))   // ":"
-		// Column number with line number styling
-		result.WriteString(c.applySearchHighlighting(matches[6], lineStyle))       // column number
-		result.WriteString(c.applySearchHighlighting(matches[7], c.theme.Bracket)) // ")"
-		if matches[8] != "" {
-			result.WriteString(c.applySearchHighlighting(matches[8], c.theme.JSONValue)) // any trailing text
+		if function, ok := entry.Attribute("function"); ok {
+			result.WriteString(c.applySearchHighlighting(line[:function.Span.Start], c.theme.Bracket))                    // "    at "
+			result.WriteString(c.applySearchHighlighting(function.Value, c.theme.Service))                                // function name
+			result.WriteString(c.applySearchHighlighting(line[function.Span.End:entry.File.Span.Start], c.theme.Bracket)) // " ("
+		} else {
+			result.WriteString(c.applySearchHighlighting(line[:entry.File.Span.Start], c.theme.Bracket)) // "    at "
 		}
+		result.WriteString(c.colorizeFileLocation(line, entry))
 		return result.String()
 	}
 
-	// Handle stack trace lines without file info (    at internal/main/run_main_module.js:17:11)
-	simpleStackRegex := regexp.MustCompile(`^(\s+at\s+)(.*)`)
-	matches = simpleStackRegex.FindStringSubmatch(line)
-	if len(matches) == 3 {
+	// Handle stack trace lines without file info (    at new Promise (<anonymous>))
+	if trimmed := strings.TrimLeft(line, " \t"); len(trimmed) < len(line) && strings.HasPrefix(trimmed, "at ") {
+		location := strings.TrimLeft(trimmed[len("at"):], " \t")
+		prefixEnd := len(line) - len(location)
 		result := strings.Builder{}
-		result.WriteString(c.applySearchHighlighting(matches[1], c.theme.Bracket)) // "    at "
-
-		// Try to extract file path and line info from the rest
-		fileInfoRegex := regexp.MustCompile(`^([^:]+):(\d+):(\d+)$`)
-		fileMatches := fileInfoRegex.FindStringSubmatch(matches[2])
-		if len(fileMatches) == 4 {
-			// File path with line:column
-			fileStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0066CC", Dark: "#66CCFF"}).Bold(true)
-			result.WriteString(c.applySearchHighlighting(fileMatches[1], fileStyle)) // file path
-			result.WriteString(c.applySearchHighlighting(":", c.theme.Equals))       // ":"
-			lineStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#CC0066", Dark: "#FF66CC"}).Bold(true)
-			result.WriteString(c.applySearchHighlighting(fileMatches[2], lineStyle)) // line number
-			result.WriteString(c.applySearchHighlighting(":", c.theme.Equals))       // ":"
-			result.WriteString(c.applySearchHighlighting(fileMatches[3], lineStyle)) // column number
-		} else {
-			// Generic function/location info
-			result.WriteString(c.applySearchHighlighting(matches[2], c.theme.Service))
-		}
+		result.WriteString(c.applySearchHighlighting(line[:prefixEnd], c.theme.Bracket)) // "    at "
+		result.WriteString(c.applySearchHighlighting(location, c.theme.Service))
 		return result.String()
 	}
 
@@ -894,6 +894,12 @@ func TestColorizer(t *testing.T) {
 			format:   parser.SyslogFormat,
 			contains: []string{"Jan 19 10:30:00", "hostname", "myapp", "1234", "ERROR"},
 		},
+		{
+			name:     "RFC 5424 with structured data",
+			line:     `<134>1 2025-01-19T10:30:00.123Z web01 billing 4321 PAY001 [origin@32473 ip="10.0.0.5"] Payment settled`,
+			format:   parser.RFC5424Format,
+			contains: []string{"134", "2025-01-19T10:30:00.123Z", "web01", "billing", "PAY001", "origin@32473", "10.0.0.5", "Payment settled"},
+		},
 		{
 			name:     "Docker with ERROR level",
 			line:     `2025-01-19T10:30:00.123456789Z ERROR Database connection failed`,
@@ -906,6 +912,12 @@ func TestColorizer(t *testing.T) {
 			format:   parser.KubernetesFormat,
 			contains: []string{"2025-01-19T10:30:00", "main.go", "42", "ERROR"},
 		},
+		{
+			name:     "Klog with structured pairs",
+			line:     `E0119 10:30:00.123456    4521 reflector.go:138] "Failed to watch" err="connection refused"`,
+			format:   parser.KlogFormat,
+			contains: []string{"0119 10:30:00.123456", "4521", "reflector.go", "138", "Failed to watch", "err", "connection refused"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1888,3 +1900,331 @@ func TestGoroutineStackTraceRegressionTests(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONColorizationIsLossless(t *testing.T) {
+	// Stripping ANSI codes from colorized JSON must give back the input byte for byte:
+	// key order, number text, escapes and whitespace are all preserved
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	lines := []string{
+		`{"zeta":1,"alpha":2,"mid":3,"level":"ERROR","message":"ordered"}`,
+		`{"id":1737282600123456789,"ts":1.7372826e+09,"ratio":0.10,"neg":-0,"exp":1E5}`,
+		`{"msg":"tab\there \"quoted\" é \\ slash\/"}`,
+		`{ "level" : "INFO" ,  "data":{ "a" :[ 1 , 2 ,3 ] } }`,
+		`{"events":[{"type":"start","ok":true},{"type":"end","ok":false,"err":null}],"empty":{},"none":[]}`,
+		`  {"padded":true}  `,
+		`[1,"two",{"three":3}]`,
+	}
+
+	c := NewColorizer()
+	for _, line := range lines {
+		for _, search := range []string{"", "e"} {
+			c.SetSearchString(search)
+			result := c.ColorizeLog(line, parser.JSONFormat)
+			if !ansiRegex.MatchString(result) {
+				t.Errorf("Expected colorized output for %q, got: %q", line, result)
+			}
+			if stripped := ansiRegex.ReplaceAllString(result, ""); stripped != line {
+				t.Errorf("JSON round trip changed the line (search %q)\nOriginal: %s\nStripped: %s", search, line, stripped)
+			}
+		}
+	}
+}
+
+func TestRFC5424Colorization(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	lines := []string{
+		`<134>1 2025-01-19T10:30:00.123Z web01 billing 4321 PAY001 [origin@32473 ip="10.0.0.5" region="eu"] Payment settled`,
+		`<11>1 - - - - - - disk full`,
+		`<165>1 2025-01-19T10:30:00Z host app - ID47 [a@1 q="say \"hi\" \]"][b@2] \ufeffhello`,
+		`<13>1 2025-01-19T10:30:00Z host app - - [bad`,
+	}
+
+	c := NewColorizer()
+	for _, line := range lines {
+		for _, search := range []string{"", "e"} {
+			c.SetSearchString(search)
+			result := c.ColorizeLog(line, parser.RFC5424Format)
+			if stripped := ansiRegex.ReplaceAllString(result, ""); stripped != line {
+				t.Errorf("colorizing changed the line (search %q)\nOriginal: %s\nStripped: %s", search, line, stripped)
+			}
+		}
+	}
+
+	// The PRI takes the color of its severity
+	c.SetSearchString("")
+	result := c.ColorizeLog(lines[1], parser.RFC5424Format)
+	if want := c.theme.GetLogLevelStyle("ERROR").Render("11"); !strings.Contains(result, want) {
+		t.Errorf("Expected the PRI of an err message in the error style %q, got: %q", want, result)
+	}
+	result = c.ColorizeLog(lines[0], parser.RFC5424Format)
+	if want := c.theme.LogfmtKey.Render("region"); !strings.Contains(result, want) {
+		t.Errorf("Expected structured data param names in the key style %q, got: %q", want, result)
+	}
+}
+
+func TestSyslogVariantsColorization(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	lines := []string{
+		`Jan 19 10:30:00 hostname myapp[1234]: ERROR: Database connection failed`,
+		`Jan  9 10:30:00 web01 kernel: usb 1-1: new high-speed USB device`,
+		`<11>Jan 19 10:30:00 web01 cron[42]: job failed`,
+		`2025-01-19T10:30:00.123456+01:00 web01 sshd[812]: Accepted publickey for deploy`,
+		`Jan 19 10:30:00 web01 kernel:`,
+	}
+
+	c := NewColorizer()
+	for _, line := range lines {
+		for _, search := range []string{"", "e"} {
+			c.SetSearchString(search)
+			result := c.ColorizeLog(line, parser.SyslogFormat)
+			if stripped := ansiRegex.ReplaceAllString(result, ""); stripped != line {
+				t.Errorf("colorizing changed the line (search %q)\nOriginal: %s\nStripped: %s", search, line, stripped)
+			}
+		}
+	}
+
+	c.SetSearchString("")
+	result := c.ColorizeLog(lines[2], parser.SyslogFormat)
+	if want := c.theme.GetLogLevelStyle("ERROR").Render("11"); !strings.Contains(result, want) {
+		t.Errorf("Expected the PRI of an err message in the error style %q, got: %q", want, result)
+	}
+	result = c.ColorizeLog(lines[1], parser.SyslogFormat)
+	if want := c.theme.Service.Render("kernel"); !strings.Contains(result, want) {
+		t.Errorf("Expected a tag without a pid in the service style %q, got: %q", want, result)
+	}
+}
+
+func TestJournalColorization(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	tests := []struct {
+		line   string
+		format parser.LogFormat
+	}{
+		{`{"__REALTIME_TIMESTAMP":"1737282600123456","PRIORITY":"3","_HOSTNAME":"web01","_SYSTEMD_UNIT":"billing.service","_PID":"4321","MESSAGE":"Payment failed"}`, parser.JournalJSONFormat},
+		{`{"__REALTIME_TIMESTAMP":"1737282600123456","MESSAGE":[80,97,121],"TAGS":{"a":"b"},"PRIORITY":"x"}`, parser.JournalJSONFormat},
+		{`__CURSOR=s=6f1c;i=1a2b;b=0c9e;m=2d4a1;t=62c0ca0413c40;x=4e5d`, parser.JournalExportFormat},
+		{`MESSAGE=Payment failed: gateway timeout`, parser.JournalExportFormat},
+		{`PRIORITY=3`, parser.JournalExportFormat},
+		{`COREDUMP`, parser.JournalExportFormat},
+	}
+
+	c := NewColorizer()
+	for _, tt := range tests {
+		for _, search := range []string{"", "e"} {
+			c.SetSearchString(search)
+			result := c.ColorizeLog(tt.line, tt.format)
+			if stripped := ansiRegex.ReplaceAllString(result, ""); stripped != tt.line {
+				t.Errorf("colorizing changed the line (search %q)\nOriginal: %s\nStripped: %s", search, tt.line, stripped)
+			}
+		}
+	}
+
+	c.SetSearchString("")
+	result := c.ColorizeLog(tests[0].line, parser.JournalJSONFormat)
+	for _, want := range []string{
+		c.theme.Message.Render("Payment failed"),
+		c.theme.GetLogLevelStyle("ERROR").Render("3"),
+		c.theme.Service.Render("billing.service"),
+		c.theme.Bracket.Render("_HOSTNAME"),
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %q in colorized journal record, got: %q", want, result)
+		}
+	}
+	result = c.ColorizeLog(tests[3].line, parser.JournalExportFormat)
+	if want := c.theme.Message.Render("Payment failed: gateway timeout"); !strings.Contains(result, want) {
+		t.Errorf("Expected the export MESSAGE to stand out as %q, got: %q", want, result)
+	}
+}
+
+func TestKlogColorization(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	lines := []string{
+		`I0119 10:30:00.123456       1 main.go:42] "Pod status updated" pod="kube-system/coredns" status="ready"`,
+		`E0119 10:30:01.000001    4521 reflector.go:138] "Failed to watch \"pods\"" err="connection refused"`,
+		`W0119 10:30:02.000000       7 cache.go:9] Unexpected "key=value" in message`,
+		`F0119 10:30:03.000000       1 server.go:77] "unable to bind port"`,
+		`I0119 10:30:04.000000       1 main.go:42] `,
+	}
+
+	c := NewColorizer()
+	for _, line := range lines {
+		for _, search := range []string{"", "e"} {
+			c.SetSearchString(search)
+			result := c.ColorizeLog(line, parser.KlogFormat)
+			if stripped := ansiRegex.ReplaceAllString(result, ""); stripped != line {
+				t.Errorf("colorizing changed the line (search %q)\nOriginal: %s\nStripped: %s", search, line, stripped)
+			}
+		}
+	}
+
+	c.SetSearchString("")
+	result := c.ColorizeLog(lines[1], parser.KlogFormat)
+	for _, want := range []string{
+		c.theme.GetLogLevelStyle("ERROR").Render("E"),
+		c.theme.PID.Render("4521"),
+		c.theme.Filename.Render("reflector.go"),
+		c.theme.LineNum.Render("138"),
+		c.theme.LogfmtKey.Render("err"),
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %q in colorized klog line, got: %q", want, result)
+		}
+	}
+	for i, level := range []string{"INFO", "ERROR", "WARN", "FATAL"} {
+		result := c.ColorizeLog(lines[i], parser.KlogFormat)
+		if want := c.theme.GetLogLevelStyle(level).Render(lines[i][:1]); !strings.HasPrefix(result, want) {
+			t.Errorf("Expected %q to start with the %s style %q, got: %q", lines[i][:1], level, want, result)
+		}
+	}
+}
+
+func TestStackTraceHeaderColorization(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	c := NewColorizer()
+	errorStyle := c.theme.StatusError.Bold(true)
+
+	tests := []struct {
+		name   string
+		line   string
+		format parser.LogFormat
+		want   []string
+	}{
+		{
+			name:   "Python exception without a message",
+			line:   "KeyError",
+			format: parser.PythonExceptionFormat,
+			want:   []string{errorStyle.Render("KeyError")},
+		},
+		{
+			name:   "Python frame without a function",
+			line:   `  File "app.py", line 21`,
+			format: parser.PythonExceptionFormat,
+			want:   []string{stackFileStyle.Render("app.py"), stackLineStyle.Render("21")},
+		},
+		{
+			name:   "Java header without a message",
+			line:   `Exception in thread "main" java.lang.IllegalStateException`,
+			format: parser.JavaExceptionFormat,
+			want:   []string{c.theme.Service.Bold(true).Render("main"), errorStyle.Render("java.lang.IllegalStateException")},
+		},
+		{
+			name:   "Java cause without a message",
+			line:   "Caused by: java.io.EOFException",
+			format: parser.JavaExceptionFormat,
+			want:   []string{errorStyle.Render("java.io.EOFException")},
+		},
+		{
+			name:   "JavaScript custom error",
+			line:   "ValidationError: name is required",
+			format: parser.JavaScriptExceptionFormat,
+			want:   []string{errorStyle.Render("ValidationError:"), c.theme.JSONString.Render(" name is required")},
+		},
+		{
+			name:   "Go package result with coverage",
+			line:   "ok  \tgithub.com/example/project\t0.123s\tcoverage: 80.0% of statements",
+			format: parser.GoTestFormat,
+			want:   []string{c.theme.Service.Bold(true).Render("github.com/example/project")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := c.ColorizeLog(tt.line, tt.format)
+			if stripped := ansiRegex.ReplaceAllString(result, ""); stripped != tt.line {
+				t.Errorf("colorizing changed the line\nOriginal: %q\nStripped: %q", tt.line, stripped)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result, want) {
+					t.Errorf("Expected %q in colorized line, got: %q", want, result)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvelopeColorization(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	tests := []struct {
+		line   string
+		format parser.LogFormat
+	}{
+		{`2025-01-19T10:30:00.123456789Z stdout F {"level":"error","msg":"Payment failed"}`, parser.JSONFormat},
+		{`2025-01-19T10:30:00.123456789Z stderr P level=warn msg="slow request"`, parser.LogfmtFormat},
+		{`2025-01-19T10:30:00.123456789Z stdout F`, parser.UnknownFormat},
+		{`[pod/web-7f9c6d8b5-2xk4q/app] 2025-01-19T10:30:00.123456789Z level=info msg=ok`, parser.LogfmtFormat},
+		{`[pod/web-7f9c6d8b5-2xk4q/app] Server listening on :8080`, parser.UnknownFormat},
+	}
+
+	c := NewColorizer()
+	for _, tt := range tests {
+		for _, search := range []string{"", "e"} {
+			c.SetSearchString(search)
+			result := c.ColorizeLog(tt.line, tt.format)
+			if stripped := ansiRegex.ReplaceAllString(result, ""); stripped != tt.line {
+				t.Errorf("colorizing changed the line (search %q)\nOriginal: %s\nStripped: %s", search, tt.line, stripped)
+			}
+		}
+	}
+
+	// The message inside the envelope is colored as it would be on its own
+	c.SetSearchString("")
+	result := c.ColorizeLog(tests[0].line, parser.JSONFormat)
+	message := `{"level":"error","msg":"Payment failed"}`
+	if want := c.ColorizeLog(message, parser.JSONFormat); !strings.HasSuffix(result, want) {
+		t.Errorf("Expected the CRI message colored as JSON %q, got: %q", want, result)
+	}
+	for _, want := range []string{
+		c.theme.Timestamp.Render("2025-01-19T10:30:00.123456789Z"),
+		c.theme.Service.Render("stdout"),
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %q in colorized CRI line, got: %q", want, result)
+		}
+	}
+
+	result = c.ColorizeLog(tests[3].line, parser.LogfmtFormat)
+	for _, want := range []string{
+		c.theme.Hostname.Render("web-7f9c6d8b5-2xk4q"),
+		c.theme.Service.Render("app"),
+		c.theme.Timestamp.Render("2025-01-19T10:30:00.123456789Z"),
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %q in colorized kubectl line, got: %q", want, result)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package colorizer
+
+import (
+	"regexp"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Patterns for the parts of go test output and stack traces the parser doesn't
+// split into fields. They are compiled once at package init so colorizing a
+// line never pays for regex compilation.
+var (
+	goTestNoFilesRegex         = regexp.MustCompile(`^(\? )([^[]+)(\[no test files\])`)
+	goroutineFunctionCallRegex = regexp.MustCompile(`^(\s*)([a-zA-Z_][a-zA-Z0-9_]*\.[a-zA-Z_][a-zA-Z0-9_]*)(\()([^)]*)(\))(.*)`)
+	goroutineFilenameRegex     = regexp.MustCompile(`^(\s*)([^\s:]*\.go):(\d+)(.*)`)
+	goroutineFragmentRegex     = regexp.MustCompile(`^(\s+)(/[^\s]*/)$`)
+
+	// timestampRegex matches any of the timestamp layouts that can be embedded in go test output:
+	// Go standard (2025/07/30 08:23:42), ISO (2025-07-30T08:23:42),
+	// Rails ([2025-07-30 08:23:42]) and Apache (30/Jul/2025:08:23:42)
+	timestampRegex = regexp.MustCompile(`\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}|\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}|\[\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\]|\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2}`)
+
+	// ansiRegex matches ANSI escape sequences: \033[...m or \x1b[...m
+	ansiRegex = regexp.MustCompile(`\033\[[0-9;]*m|\x1b\[[0-9;]*m`)
+)
+
+// Styles shared by every stack trace colorizer (Java, Python, JavaScript, goroutines)
+var (
+	// stackFileStyle renders file paths prominently - bright cyan, bold
+	stackFileStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0066CC", Dark: "#66CCFF"}).Bold(true)
+	// stackLineStyle renders line and column numbers prominently - bright magenta, bold
+	stackLineStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#CC0066", Dark: "#FF66CC"}).Bold(true)
+)
@@ -0,0 +1,63 @@
+package colorizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/joshi4/splash/parser"
+)
+
+var deployFormat = parser.RegisterFormat("Test Deploy")
+
+// deployDetector matches lines like "deploy api v1.2 ok"
+type deployDetector struct{}
+
+func (deployDetector) Detect(_ context.Context, line string) bool {
+	return strings.HasPrefix(line, "deploy ")
+}
+func (deployDetector) Format() parser.LogFormat { return deployFormat }
+func (deployDetector) Specificity() int         { return 90 }
+func (deployDetector) PatternLength() int       { return 0 }
+
+func init() {
+	parser.Register(deployDetector{}, func(line string) []parser.Segment {
+		fields := strings.Fields(line)
+		service := strings.Index(line, fields[1])
+		version := strings.LastIndex(line, fields[2])
+		// Out of order and overlapping segments are tolerated
+		return []parser.Segment{
+			{Span: parser.Span{Start: version, End: version + len(fields[2])}, Role: parser.RoleNumber},
+			{Span: parser.Span{Start: service, End: service + len(fields[1])}, Role: parser.RoleService},
+			{Span: parser.Span{Start: service, End: len(line)}, Role: parser.RoleString},
+		}
+	})
+}
+
+func TestColorizeRegisteredFormat(t *testing.T) {
+	tag := func(name string) lipgloss.Style {
+		return lipgloss.NewStyle().Transform(func(s string) string { return "<" + name + ":" + s + ">" })
+	}
+	theme := markerTheme(1)
+	theme.Service = tag("service")
+	theme.JSONNumber = tag("number")
+
+	c := NewColorizer()
+	c.SetTheme(theme)
+
+	line := "deploy api v1.2 ok"
+	format := parser.NewParser().DetectFormat(line)
+	if format != deployFormat {
+		t.Fatalf("DetectFormat() = %v, want %v", format, deployFormat)
+	}
+	if got, want := c.ColorizeLog(line, format), "deploy <service:api> <number:v1.2> ok"; got != want {
+		t.Errorf("ColorizeLog() = %q, want %q", got, want)
+	}
+
+	c.SetSearchString("v1")
+	if got, want := c.ColorizeLog(line, format), "deploy <service:api> ⟦0:v1⟧<number:.2> ok"; got != want {
+		t.Errorf("ColorizeLog() with search = %q, want %q", got, want)
+	}
+}
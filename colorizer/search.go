@@ -0,0 +1,318 @@
+package colorizer
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SearchOptions control how every search pattern is matched
+type SearchOptions struct {
+	IgnoreCase bool // match regardless of case
+	SmartCase  bool // ignore case unless the pattern contains an uppercase letter
+	Word       bool // only match whole words
+}
+
+// searchPattern is one literal string or regular expression to highlight
+type searchPattern struct {
+	text    string // the pattern as given
+	isRegex bool
+	// regex matches the pattern with the search options applied. It is nil
+	// for a literal searched as is, which uses the faster strings.Index.
+	regex *regexp.Regexp
+}
+
+// newSearchPattern compiles a pattern with the given options
+func newSearchPattern(text string, isRegex bool, opts SearchOptions) (searchPattern, error) {
+	p := searchPattern{text: text, isRegex: isRegex}
+
+	ignoreCase := opts.IgnoreCase || (opts.SmartCase && !hasUppercase(text, isRegex))
+	if !isRegex && !ignoreCase && !opts.Word {
+		return p, nil
+	}
+
+	expr := text
+	if !isRegex {
+		expr = regexp.QuoteMeta(text)
+	}
+	if opts.Word {
+		expr = wordEdges(expr)
+	}
+	if ignoreCase {
+		expr = `(?i)` + expr
+	}
+
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return p, err
+	}
+	p.regex = regex
+	return p, nil
+}
+
+// wordEdges makes expr only match whole words, as grep -w does: the text
+// before and after a match must not be word characters. An edge that is a
+// word character gets \b and one that isn't gets \B, so "-v" matches in
+// "run -v" but not in "x-v". An edge without a fixed character gets \b.
+func wordEdges(expr string) string {
+	before, after := `\b`, `\b`
+	if re, err := syntax.Parse(expr, syntax.Perl); err == nil {
+		if r, ok := edgeRune(re, true); ok && !isWordRune(r) {
+			before = `\B`
+		}
+		if r, ok := edgeRune(re, false); ok && !isWordRune(r) {
+			after = `\B`
+		}
+	}
+	return before + `(?:` + expr + `)` + after
+}
+
+// edgeRune returns the character every match of re starts with, or ends
+// with when first is false, if the expression fixes one
+func edgeRune(re *syntax.Regexp, first bool) (rune, bool) {
+	for {
+		switch re.Op {
+		case syntax.OpLiteral:
+			if first {
+				return re.Rune[0], true
+			}
+			return re.Rune[len(re.Rune)-1], true
+		case syntax.OpCapture, syntax.OpPlus:
+			re = re.Sub[0]
+		case syntax.OpConcat:
+			if len(re.Sub) == 0 {
+				return 0, false
+			}
+			if first {
+				re = re.Sub[0]
+			} else {
+				re = re.Sub[len(re.Sub)-1]
+			}
+		default:
+			return 0, false
+		}
+	}
+}
+
+// isWordRune reports whether r is a character \b treats as part of a word
+func isWordRune(r rune) bool {
+	return r == '_' || r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// hasUppercase reports whether a pattern contains an uppercase letter. In a
+// regex the letter after a backslash is an escape such as \S, not text.
+func hasUppercase(text string, isRegex bool) bool {
+	escaped := false
+	for _, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case isRegex && r == '\\':
+			escaped = true
+		case unicode.IsUpper(r):
+			return true
+		}
+	}
+	return false
+}
+
+// appendMatches appends the non-overlapping matches of the pattern in text to dst
+func (p searchPattern) appendMatches(text string, index int, dst []SearchMatch) []SearchMatch {
+	if p.regex != nil {
+		// Handle regex search
+		for _, match := range p.regex.FindAllStringIndex(text, -1) {
+			if match[0] == match[1] {
+				continue
+			}
+			dst = append(dst, SearchMatch{
+				start:   match[0],
+				end:     match[1],
+				text:    text[match[0]:match[1]],
+				pattern: index,
+			})
+		}
+		return dst
+	}
+
+	// Handle string search (case-sensitive)
+	searchLen := len(p.text)
+	startPos := 0
+
+	for {
+		pos := strings.Index(text[startPos:], p.text)
+		if pos == -1 {
+			break
+		}
+
+		actualPos := startPos + pos
+		dst = append(dst, SearchMatch{
+			start:   actualPos,
+			end:     actualPos + searchLen,
+			text:    p.text,
+			pattern: index,
+		})
+		startPos = actualPos + searchLen
+	}
+	return dst
+}
+
+// String returns the pattern as shown in the search legend, with regular
+// expressions between slashes
+func (p searchPattern) String() string {
+	if p.isRegex {
+		return "/" + p.text + "/"
+	}
+	return p.text
+}
+
+// SearchMatch represents a found search match with its position
+type SearchMatch struct {
+	start   int
+	end     int
+	text    string
+	pattern int // index of the pattern that matched
+}
+
+// SetSearchString sets a literal string to search for and highlight,
+// replacing any patterns set before
+func (c *Colorizer) SetSearchString(pattern string) {
+	c.patterns = nil
+	c.AddSearchString(pattern)
+}
+
+// SetSearchRegex sets a regular expression to search for and highlight,
+// replacing any patterns set before
+func (c *Colorizer) SetSearchRegex(pattern string) error {
+	p, err := newSearchPattern(pattern, true, c.searchOptions)
+	if err != nil {
+		return err
+	}
+	c.patterns = []searchPattern{p}
+	return nil
+}
+
+// AddSearchString adds a literal string to the patterns to highlight. Each
+// pattern is highlighted with its own color from the theme's search palette.
+func (c *Colorizer) AddSearchString(pattern string) {
+	if pattern == "" {
+		return
+	}
+	// A quoted literal always compiles
+	p, _ := newSearchPattern(pattern, false, c.searchOptions)
+	c.patterns = append(c.patterns, p)
+}
+
+// AddSearchRegex adds a regular expression to the patterns to highlight
+func (c *Colorizer) AddSearchRegex(pattern string) error {
+	p, err := newSearchPattern(pattern, true, c.searchOptions)
+	if err != nil {
+		return err
+	}
+	c.patterns = append(c.patterns, p)
+	return nil
+}
+
+// SetSearchOptions changes how patterns are matched, including patterns
+// that were already set
+func (c *Colorizer) SetSearchOptions(opts SearchOptions) error {
+	c.searchOptions = opts
+	for i, p := range c.patterns {
+		compiled, err := newSearchPattern(p.text, p.isRegex, opts)
+		if err != nil {
+			return err
+		}
+		c.patterns[i] = compiled
+	}
+	return nil
+}
+
+// HasSearch reports whether any search string or regex is set
+func (c *Colorizer) HasSearch() bool {
+	return len(c.patterns) > 0
+}
+
+// MatchesSearch reports whether the plain text of a line matches any of the
+// search patterns. It returns false when no search is set.
+func (c *Colorizer) MatchesSearch(line string) bool {
+	for _, p := range c.patterns {
+		if p.regex != nil {
+			if p.regex.MatchString(line) {
+				return true
+			}
+		} else if strings.Contains(line, p.text) {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchLegend shows each search pattern in its highlight color, so output
+// searched for several patterns can be read back. It is empty unless more
+// than one pattern is set.
+func (c *Colorizer) SearchLegend() string {
+	if len(c.patterns) < 2 {
+		return ""
+	}
+
+	legend := strings.Builder{}
+	legend.WriteString("search:")
+	for i, p := range c.patterns {
+		legend.WriteString(" ")
+		legend.WriteString(c.searchStyle(i).Render(p.String()))
+	}
+	return legend.String()
+}
+
+// searchStyle returns the highlight for the pattern at index, cycling
+// through the theme's search palette
+func (c *Colorizer) searchStyle(index int) lipgloss.Style {
+	if len(c.theme.SearchPalette) == 0 {
+		return c.theme.UnifiedSearchHighlight
+	}
+	return c.theme.SearchPalette[index%len(c.theme.SearchPalette)]
+}
+
+// findMatches appends the search matches in text to dst in order. Where
+// matches of different patterns overlap, each byte goes to the pattern that
+// was added first.
+func (c *Colorizer) findMatches(text string, dst []SearchMatch) []SearchMatch {
+	if len(c.patterns) == 1 {
+		return c.patterns[0].appendMatches(text, 0, dst)
+	}
+
+	// Mark every byte with the lowest pattern index that matches it
+	owners := c.ownerBuf[:0]
+	for range len(text) {
+		owners = append(owners, -1)
+	}
+	defer func() { c.ownerBuf = owners[:0] }()
+
+	found := dst
+	for i := len(c.patterns) - 1; i >= 0; i-- {
+		found = c.patterns[i].appendMatches(text, i, found[:len(dst)])
+		for _, match := range found[len(dst):] {
+			for pos := match.start; pos < match.end; pos++ {
+				owners[pos] = i
+			}
+		}
+	}
+	dst = found[:len(dst)]
+
+	// Turn runs of bytes owned by the same pattern back into matches
+	for start := 0; start < len(owners); {
+		owner := owners[start]
+		end := start + 1
+		for end < len(owners) && owners[end] == owner {
+			end++
+		}
+		if owner >= 0 {
+			dst = append(dst, SearchMatch{start: start, end: end, text: text[start:end], pattern: owner})
+		}
+		start = end
+	}
+	return dst
+}
@@ -3,9 +3,13 @@ package colorizer
 import (
 	"encoding/json"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
 	"github.com/joshi4/splash/parser"
 )
 
@@ -89,7 +93,7 @@ func TestSearchFunctionality(t *testing.T) {
 			}
 
 			// Test if line matches
-			matches := c.matchesSearch(tt.line)
+			matches := c.MatchesSearch(tt.line)
 			if matches != tt.shouldMatch {
 				t.Errorf("Expected match=%v, got match=%v. %s", tt.shouldMatch, matches, tt.description)
 			}
@@ -169,7 +173,7 @@ func TestNoSearchPattern(t *testing.T) {
 	line := `{"level":"ERROR","message":"test"}`
 
 	// Should not match when no search pattern is set
-	if c.matchesSearch(line) {
+	if c.MatchesSearch(line) {
 		t.Error("Expected no match when no search pattern is set")
 	}
 
@@ -325,7 +329,7 @@ func TestSearchPatternSwitching(t *testing.T) {
 
 	// Set string search
 	c.SetSearchString("ERROR")
-	if !c.matchesSearch(line) {
+	if !c.MatchesSearch(line) {
 		t.Error("Expected match with string search")
 	}
 
@@ -335,13 +339,373 @@ func TestSearchPatternSwitching(t *testing.T) {
 		t.Fatalf("Failed to set regexp: %v", err)
 	}
 
-	if !c.matchesSearch(line) {
+	if !c.MatchesSearch(line) {
 		t.Error("Expected match with regexp search")
 	}
 
 	// Switch back to string search - should clear regexp
 	c.SetSearchString("connection")
-	if !c.matchesSearch(line) {
+	if !c.MatchesSearch(line) {
 		t.Error("Expected match with new string search")
 	}
 }
+
+func TestSearchHighlightsMatchesAcrossSegments(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	tests := []struct {
+		format  parser.LogFormat
+		line    string
+		pattern string
+	}{
+		{parser.UnknownFormat, `2025-01-19 08:30:00 something happened ERROR while processing`, "happened ERROR while"},
+		{parser.JSONFormat, `{"level":"ERROR", "msg":"Database connection failed"}`, `ERROR", "msg`},
+		{parser.LogfmtFormat, `level=error msg="Database connection failed" service=api`, `failed" service=api`},
+		{parser.ApacheCommonFormat, `127.0.0.1 - - [19/Jan/2025:08:30:00 +0000] "GET /api/users HTTP/1.1" 500 1234`, `HTTP/1.1" 500`},
+		{parser.NginxFormat, `127.0.0.1 - - [19/Jan/2025:08:30:00 +0000] "GET /api/users HTTP/1.1" 500 1234 "-" "Mozilla/5.0"`, `1234 "-" "Mozilla`},
+		{parser.SyslogFormat, `Jan 19 10:30:00 hostname myapp[1234]: ERROR: Database connection failed`, "10:30:00 hostname"},
+		{parser.SyslogFormat, `2025-01-19T08:30:00+0000 server01 billing[4321]: Database connection failed`, "server01 billing[4321]: Database"},
+		{parser.SyslogFormat, `Jan 19 08:30:00.123456 server01 billing[4321]: Database connection failed`, "08:30:00.123456 server01"},
+		{parser.RsyslogFormat, `Jan 19 08:30:00 server01 rsyslogd[1234]: [origin software="rsyslogd"] start`, "rsyslogd[1234]: [origin"},
+		{parser.GoStandardFormat, `2025/01/19 08:30:00 ERROR: database connection failed`, "08:30:00 ERROR: database"},
+		{parser.RailsFormat, `[2025-01-19T08:30:00.123456 #1234] ERROR -- : Database connection failed`, "ERROR -- : Database"},
+		{parser.DockerFormat, `2025-01-19T08:30:00.123456789Z ERROR Database connection failed`, "ERROR Database"},
+		{parser.KubernetesFormat, `2025-01-19T08:30:00.123456Z 1 main.go:42] Database connection failed`, "main.go:42] Database"},
+		{parser.HerokuFormat, `2025-01-19T08:30:00+00:00 app[web.1]: Database connection failed`, "app[web.1]: Database"},
+		{parser.GoTestFormat, `--- FAIL: TestDatabaseConnection (0.05s)`, "FAIL: TestDatabase"},
+		{parser.JavaExceptionFormat, `    at com.example.db.ConnectionPool.acquire(ConnectionPool.java:142)`, "acquire(ConnectionPool.java:142"},
+		{parser.PythonExceptionFormat, `  File "/app/db/pool.py", line 142, in acquire`, `pool.py", line 142`},
+		{parser.JavaScriptExceptionFormat, `    at Pool.acquire (/app/db/pool.js:142:17)`, "acquire (/app/db/pool.js:142"},
+		{parser.GoroutineStackTraceFormat, `        /usr/local/go/src/net/http/server.go:3142 +0x2c5`, "server.go:3142 +0x2c5"},
+		{parser.RFC5424Format, `<131>1 2025-01-19T08:30:00.123Z server01 billing 4321 DB001 [origin@32473 ip="10.0.0.5" region="eu"] Database connection failed`, `region="eu"] Database`},
+		{parser.JournalJSONFormat, `{"PRIORITY":"3","_SYSTEMD_UNIT":"billing.service","MESSAGE":"Database connection failed"}`, `billing.service","MESSAGE":"Database`},
+		{parser.JournalExportFormat, `MESSAGE=Database connection failed`, "MESSAGE=Database"},
+		{parser.KlogFormat, `E0119 08:30:00.123456       1 main.go:42] "Database connection failed" service="api"`, `main.go:42] "Database`},
+	}
+
+	covered := make(map[parser.LogFormat]bool)
+	for _, tt := range tests {
+		covered[tt.format] = true
+	}
+	for _, format := range parser.BuiltinFormats() {
+		if !covered[format] {
+			t.Errorf("no line of %v to search across segments", format)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			c := NewColorizer()
+			theme := NewAdaptiveTheme()
+			theme.SearchPalette = []lipgloss.Style{lipgloss.NewStyle().Transform(func(s string) string {
+				return "⟦" + s + "⟧"
+			})}
+			c.SetTheme(theme)
+			c.SetSearchString(tt.pattern)
+
+			got := stripTestAnsiCodes(c.ColorizeLog(tt.line, tt.format))
+			// A match split across segments is highlighted piece by piece
+			got = strings.ReplaceAll(got, "⟧⟦", "")
+
+			if !strings.Contains(got, "⟦"+tt.pattern+"⟧") {
+				t.Errorf("ColorizeLog() = %q, want %q highlighted as one match", got, tt.pattern)
+			}
+			if unmarked := strings.NewReplacer("⟦", "", "⟧", "").Replace(got); unmarked != strings.TrimSpace(tt.line) && unmarked != tt.line {
+				t.Errorf("ColorizeLog() text = %q, want %q", unmarked, tt.line)
+			}
+		})
+	}
+}
+
+func TestSearchRegexAnchorsToRawLine(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	c := NewColorizer()
+	if err := c.SetSearchRegex(`^Jan 19`); err != nil {
+		t.Fatal(err)
+	}
+
+	line := `Jan 19 10:30:00 hostname myapp[1234]: Jan 19 was a good day`
+	got := c.ColorizeLog(line, parser.SyslogFormat)
+	highlight := c.theme.UnifiedSearchHighlight.Render("Jan 19")
+	if n := strings.Count(got, highlight); n != 1 {
+		t.Errorf("highlighted %d matches of an anchored pattern, want 1 in %q", n, got)
+	}
+}
+
+// markerTheme tags each search palette entry's matches with its index, e.g. "⟦1:match⟧"
+func markerTheme(n int) *ColorTheme {
+	theme := NewAdaptiveTheme()
+	theme.SearchPalette = nil
+	for i := range n {
+		tag := strconv.Itoa(i)
+		theme.SearchPalette = append(theme.SearchPalette, lipgloss.NewStyle().Transform(func(s string) string {
+			return "⟦" + tag + ":" + s + "⟧"
+		}))
+	}
+	return theme
+}
+
+var markedRunRegex = regexp.MustCompile(`⟦(\d+):([^⟧]*)⟧`)
+
+// joinMarkedRuns merges adjacent pieces of a match with the same tag, which
+// appear when one match is split across styled segments
+func joinMarkedRuns(s string) string {
+	result := strings.Builder{}
+	lastEnd, openTag := 0, ""
+	for _, m := range markedRunRegex.FindAllStringSubmatchIndex(s, -1) {
+		tag, text := s[m[2]:m[3]], s[m[4]:m[5]]
+		if m[0] == lastEnd && tag == openTag {
+			result.WriteString(text)
+		} else {
+			if openTag != "" {
+				result.WriteString("⟧")
+			}
+			result.WriteString(s[lastEnd:m[0]])
+			result.WriteString("⟦" + tag + ":" + text)
+		}
+		lastEnd, openTag = m[1], tag
+	}
+	if openTag != "" {
+		result.WriteString("⟧")
+	}
+	result.WriteString(s[lastEnd:])
+	return result.String()
+}
+
+func TestMultipleSearchPatterns(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	tests := []struct {
+		name     string
+		line     string
+		format   parser.LogFormat
+		patterns []string // literal unless wrapped in slashes
+		want     string
+	}{
+		{
+			name:     "each pattern gets its own color",
+			line:     `level=error user=user_42 msg="deadline exceeded" peer=10.0.0.5`,
+			format:   parser.LogfmtFormat,
+			patterns: []string{"user_42", "/timeout|deadline/", "10.0.0.5"},
+			want:     `level=error user=⟦0:user_42⟧ msg="⟦1:deadline⟧ exceeded" peer=⟦2:10.0.0.5⟧`,
+		},
+		{
+			name:     "earlier pattern wins an overlap",
+			line:     "2025-01-19T08:30:00.123Z ERROR request timeout after 30s",
+			format:   parser.DockerFormat,
+			patterns: []string{"timeout", "request time"},
+			want:     "2025-01-19T08:30:00.123Z ERROR ⟦1:request ⟧⟦0:timeout⟧ after 30s",
+		},
+		{
+			name:     "later pattern keeps the part it alone matches",
+			line:     "2025-01-19T08:30:00.123Z ERROR request timeout after 30s",
+			format:   parser.DockerFormat,
+			patterns: []string{"/time(out)?/", "out after"},
+			want:     "2025-01-19T08:30:00.123Z ERROR request ⟦0:timeout⟧⟦1: after⟧ 30s",
+		},
+		{
+			name:     "palette cycles when there are more patterns than colors",
+			line:     "a b c d",
+			format:   parser.UnknownFormat,
+			patterns: []string{"a", "b", "c", "d"},
+			want:     "⟦0:a⟧ ⟦1:b⟧ ⟦2:c⟧ ⟦0:d⟧",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewColorizer()
+			c.SetTheme(markerTheme(3))
+			for _, p := range tt.patterns {
+				if strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+					if err := c.AddSearchRegex(strings.Trim(p, "/")); err != nil {
+						t.Fatal(err)
+					}
+				} else {
+					c.AddSearchString(p)
+				}
+			}
+
+			got := joinMarkedRuns(stripTestAnsiCodes(c.ColorizeLog(tt.line, tt.format)))
+			if got != tt.want {
+				t.Errorf("ColorizeLog() = %q, want %q", got, tt.want)
+			}
+			if !c.MatchesSearch(tt.line) {
+				t.Errorf("MatchesSearch(%q) = false, want true", tt.line)
+			}
+		})
+	}
+}
+
+func TestSearchLegend(t *testing.T) {
+	c := NewColorizer()
+	c.SetTheme(markerTheme(5))
+
+	c.AddSearchString("user_42")
+	if legend := c.SearchLegend(); legend != "" {
+		t.Errorf("SearchLegend() with one pattern = %q, want empty", legend)
+	}
+
+	if err := c.AddSearchRegex("timeout|deadline"); err != nil {
+		t.Fatal(err)
+	}
+	want := "search: ⟦0:user_42⟧ ⟦1:/timeout|deadline/⟧"
+	if legend := stripTestAnsiCodes(c.SearchLegend()); legend != want {
+		t.Errorf("SearchLegend() = %q, want %q", legend, want)
+	}
+
+	c.SetSearchString("reset")
+	if legend := c.SearchLegend(); legend != "" {
+		t.Errorf("SearchLegend() after SetSearchString = %q, want empty", legend)
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	originalProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(originalProfile)
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	tests := []struct {
+		name    string
+		line    string
+		format  parser.LogFormat
+		pattern string // literal unless wrapped in slashes
+		opts    SearchOptions
+		want    string
+	}{
+		{
+			name:    "ignore case literal in JSON key and value",
+			line:    `{"Error":"database ERROR"}`,
+			format:  parser.JSONFormat,
+			pattern: "error",
+			opts:    SearchOptions{IgnoreCase: true},
+			want:    `{"⟦0:Error⟧":"database ⟦0:ERROR⟧"}`,
+		},
+		{
+			name:    "ignore case regex",
+			line:    "Jan 19 10:30:00 host app[1]: Connection TIMEOUT",
+			format:  parser.SyslogFormat,
+			pattern: "/time(out)?/",
+			opts:    SearchOptions{IgnoreCase: true},
+			want:    "Jan 19 10:30:00 host app[1]: Connection ⟦0:TIMEOUT⟧",
+		},
+		{
+			name:    "smart case without uppercase ignores case",
+			line:    `level=ERROR msg="error"`,
+			format:  parser.LogfmtFormat,
+			pattern: "error",
+			opts:    SearchOptions{SmartCase: true},
+			want:    `level=⟦0:ERROR⟧ msg="⟦0:error⟧"`,
+		},
+		{
+			name:    "smart case with uppercase is case sensitive",
+			line:    `level=ERROR msg="Error"`,
+			format:  parser.LogfmtFormat,
+			pattern: "Error",
+			opts:    SearchOptions{SmartCase: true},
+			want:    `level=ERROR msg="⟦0:Error⟧"`,
+		},
+		{
+			name:    "smart case ignores regex escapes",
+			line:    "2025/01/19 10:30:00 ERROR: disk full",
+			format:  parser.GoStandardFormat,
+			pattern: `/error:\S*/`,
+			opts:    SearchOptions{SmartCase: true},
+			want:    "2025/01/19 10:30:00 ⟦0:ERROR:⟧ disk full",
+		},
+		{
+			name:    "word literal",
+			line:    "2025-01-19T10:30:00Z INFO userid=7 id=42",
+			format:  parser.DockerFormat,
+			pattern: "id",
+			opts:    SearchOptions{Word: true},
+			want:    "2025-01-19T10:30:00Z INFO userid=7 ⟦0:id⟧=42",
+		},
+		{
+			name:    "word regex alternation",
+			line:    `127.0.0.1 - - [19/Jan/2025:08:30:00 +0000] "GET /api/users HTTP/1.1" 500 1234`,
+			format:  parser.ApacheCommonFormat,
+			pattern: "/api|user/",
+			opts:    SearchOptions{Word: true},
+			want:    `127.0.0.1 - - [19/Jan/2025:08:30:00 +0000] "GET /⟦0:api⟧/users HTTP/1.1" 500 1234`,
+		},
+		{
+			name:    "word and ignore case together",
+			line:    `{"msg":"Fail failed FAIL"}`,
+			format:  parser.JSONFormat,
+			pattern: "fail",
+			opts:    SearchOptions{Word: true, IgnoreCase: true},
+			want:    `{"msg":"⟦0:Fail⟧ failed ⟦0:FAIL⟧"}`,
+		},
+		{
+			name:    "word starting with a non-word character",
+			line:    "running make -v x-v -v",
+			format:  parser.UnknownFormat,
+			pattern: "-v",
+			opts:    SearchOptions{Word: true},
+			want:    "running make ⟦0:-v⟧ x-v ⟦0:-v⟧",
+		},
+		{
+			name:    "word ending with a non-word character",
+			line:    "called foo) and foo)bar",
+			format:  parser.UnknownFormat,
+			pattern: "foo)",
+			opts:    SearchOptions{Word: true},
+			want:    "called ⟦0:foo)⟧ and foo)bar",
+		},
+		{
+			name:    "word regex with non-word edges",
+			line:    "flags -v2 x-v2 -v2x",
+			format:  parser.UnknownFormat,
+			pattern: `/-v\d/`,
+			opts:    SearchOptions{Word: true},
+			want:    "flags ⟦0:-v2⟧ x-v2 -v2x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewColorizer()
+			c.SetTheme(markerTheme(1))
+			if err := c.SetSearchOptions(tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(tt.pattern, "/") && strings.HasSuffix(tt.pattern, "/") {
+				if err := c.AddSearchRegex(strings.Trim(tt.pattern, "/")); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				c.AddSearchString(tt.pattern)
+			}
+
+			got := joinMarkedRuns(stripTestAnsiCodes(c.ColorizeLog(tt.line, tt.format)))
+			if got != tt.want {
+				t.Errorf("ColorizeLog() = %q, want %q", got, tt.want)
+			}
+			if wantMatch := strings.Contains(tt.want, "⟦"); c.MatchesSearch(tt.line) != wantMatch {
+				t.Errorf("MatchesSearch(%q) = %v, want %v", tt.line, !wantMatch, wantMatch)
+			}
+		})
+	}
+}
+
+func TestSetSearchOptionsRecompilesExistingPatterns(t *testing.T) {
+	c := NewColorizer()
+	c.SetSearchString("error")
+	if c.MatchesSearch("ERROR") {
+		t.Fatal("MatchesSearch() before --ignore-case = true, want false")
+	}
+	if err := c.SetSearchOptions(SearchOptions{IgnoreCase: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.MatchesSearch("ERROR") {
+		t.Error("MatchesSearch() after --ignore-case = false, want true")
+	}
+}
@@ -1,7 +1,11 @@
 package colorizer
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/joshi4/splash/parser"
 )
 
 // ColorTheme defines the color scheme for different log components
@@ -22,6 +26,7 @@ type ColorTheme struct {
 	IP        lipgloss.Style
 	URL       lipgloss.Style
 	Method    lipgloss.Style
+	Message   lipgloss.Style // the message among a record's many fields, as in journal exports
 
 	// JSON/structured data
 	JSONKey    lipgloss.Style
@@ -51,6 +56,19 @@ type ColorTheme struct {
 	SearchHighlight        lipgloss.Style // Deprecated - use UnifiedSearchHighlight
 	JSONSearchHighlight    lipgloss.Style // Deprecated - use UnifiedSearchHighlight
 	UnifiedSearchHighlight lipgloss.Style // Bright Orange + Adaptive - used for all search highlighting
+
+	// Search palette - one highlight per search pattern, in the order the
+	// patterns were given. The first entry matches UnifiedSearchHighlight.
+	SearchPalette []lipgloss.Style
+
+	// Source labels - cycled through when prefixing lines with their input name
+	SourceLabels []lipgloss.Style
+
+	// Gutter marking lines a wrapped command wrote to stderr
+	StderrGutter lipgloss.Style
+
+	// Gutter marking lines that satisfy a --where query
+	MatchGutter lipgloss.Style
 }
 
 // NewAdaptiveTheme creates a color theme that adapts to the terminal
@@ -72,6 +90,7 @@ func NewAdaptiveTheme() *ColorTheme {
 		IP:        lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "4", Dark: "12"}).Bold(true),      // Blue/Bright blue
 		URL:       lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "6", Dark: "14"}).Underline(true), // Cyan/Bright cyan
 		Method:    lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "5", Dark: "13"}).Bold(true),      // Magenta/Bright magenta
+		Message:   lipgloss.NewStyle().Bold(true),                                                                 // Default color, bold
 
 		// JSON/structured data - ANSI colors for better compatibility
 		JSONKey:    lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "5", Dark: "13"}).Bold(true), // Magenta/Bright magenta
@@ -111,6 +130,30 @@ func NewAdaptiveTheme() *ColorTheme {
 			Background(lipgloss.AdaptiveColor{Light: "3", Dark: "208"}). // Yellow for light, orange for dark
 			Foreground(lipgloss.AdaptiveColor{Light: "1", Dark: "0"}).   // Red text for light, black for dark
 			Bold(true),
+
+		// Search palette
+		SearchPalette: []lipgloss.Style{
+			lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "3", Dark: "208"}).Foreground(lipgloss.AdaptiveColor{Light: "1", Dark: "0"}).Bold(true),  // Yellow/Orange
+			lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "6", Dark: "45"}).Foreground(lipgloss.AdaptiveColor{Light: "0", Dark: "0"}).Bold(true),   // Cyan
+			lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "5", Dark: "213"}).Foreground(lipgloss.AdaptiveColor{Light: "15", Dark: "0"}).Bold(true), // Magenta/Pink
+			lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "2", Dark: "120"}).Foreground(lipgloss.AdaptiveColor{Light: "0", Dark: "0"}).Bold(true),  // Green
+			lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "4", Dark: "75"}).Foreground(lipgloss.AdaptiveColor{Light: "15", Dark: "0"}).Bold(true),  // Blue
+		},
+
+		// Source labels
+		SourceLabels: []lipgloss.Style{
+			lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "6", Dark: "14"}), // Cyan/Bright cyan
+			lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "5", Dark: "13"}), // Magenta/Bright magenta
+			lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "2", Dark: "10"}), // Green/Bright green
+			lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "4", Dark: "12"}), // Blue/Bright blue
+			lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "3", Dark: "11"}), // Yellow/Bright yellow
+		},
+
+		// Stderr gutter - muted red so it marks the line without competing with it
+		StderrGutter: lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "217", Dark: "88"}), // Pale red/Dark red
+
+		// Match gutter - the search highlight color, since it marks a match
+		MatchGutter: lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "3", Dark: "208"}), // Yellow/Orange
 	}
 }
 
@@ -133,6 +176,7 @@ func NewLightTheme() *ColorTheme {
 		IP:        lipgloss.NewStyle().Foreground(lipgloss.Color("4")).Bold(true),      // ANSI blue
 		URL:       lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Underline(true), // ANSI cyan underlined
 		Method:    lipgloss.NewStyle().Foreground(lipgloss.Color("5")).Bold(true),      // ANSI magenta
+		Message:   lipgloss.NewStyle().Bold(true),                                      // Default color, bold
 
 		// JSON/structured data
 		JSONKey:    lipgloss.NewStyle().Foreground(lipgloss.Color("5")).Bold(true), // ANSI magenta
@@ -166,6 +210,30 @@ func NewLightTheme() *ColorTheme {
 			Background(lipgloss.Color("3")). // ANSI yellow background
 			Foreground(lipgloss.Color("1")). // ANSI red text
 			Bold(true),
+
+		// Search palette
+		SearchPalette: []lipgloss.Style{
+			lipgloss.NewStyle().Background(lipgloss.Color("3")).Foreground(lipgloss.Color("1")).Bold(true),  // Yellow background, red text
+			lipgloss.NewStyle().Background(lipgloss.Color("6")).Foreground(lipgloss.Color("0")).Bold(true),  // Cyan background, black text
+			lipgloss.NewStyle().Background(lipgloss.Color("5")).Foreground(lipgloss.Color("15")).Bold(true), // Magenta background, white text
+			lipgloss.NewStyle().Background(lipgloss.Color("2")).Foreground(lipgloss.Color("0")).Bold(true),  // Green background, black text
+			lipgloss.NewStyle().Background(lipgloss.Color("4")).Foreground(lipgloss.Color("15")).Bold(true), // Blue background, white text
+		},
+
+		// Source labels
+		SourceLabels: []lipgloss.Style{
+			lipgloss.NewStyle().Foreground(lipgloss.Color("6")), // ANSI cyan
+			lipgloss.NewStyle().Foreground(lipgloss.Color("5")), // ANSI magenta
+			lipgloss.NewStyle().Foreground(lipgloss.Color("2")), // ANSI green
+			lipgloss.NewStyle().Foreground(lipgloss.Color("4")), // ANSI blue
+			lipgloss.NewStyle().Foreground(lipgloss.Color("3")), // ANSI yellow
+		},
+
+		// Stderr gutter
+		StderrGutter: lipgloss.NewStyle().Foreground(lipgloss.Color("217")), // ANSI pale red
+
+		// Match gutter
+		MatchGutter: lipgloss.NewStyle().Foreground(lipgloss.Color("3")), // ANSI yellow
 	}
 }
 
@@ -188,6 +256,7 @@ func NewDarkTheme() *ColorTheme {
 		IP:        lipgloss.NewStyle().Foreground(lipgloss.Color("#74B9FF")),                 // Light blue
 		URL:       lipgloss.NewStyle().Foreground(lipgloss.Color("#81ECEC")).Underline(true), // Light cyan underlined
 		Method:    lipgloss.NewStyle().Foreground(lipgloss.Color("#FD79A8")).Bold(true),      // Light pink
+		Message:   lipgloss.NewStyle().Foreground(lipgloss.Color("#F5F6FA")).Bold(true),      // Off-white
 
 		// JSON/structured data
 		JSONKey:    lipgloss.NewStyle().Foreground(lipgloss.Color("#DDA0DD")),        // Light plum
@@ -221,6 +290,30 @@ func NewDarkTheme() *ColorTheme {
 			Background(lipgloss.Color("214")). // ANSI bright orange background
 			Foreground(lipgloss.Color("0")).   // ANSI black text
 			Bold(true),
+
+		// Search palette
+		SearchPalette: []lipgloss.Style{
+			lipgloss.NewStyle().Background(lipgloss.Color("214")).Foreground(lipgloss.Color("0")).Bold(true), // Bright orange
+			lipgloss.NewStyle().Background(lipgloss.Color("45")).Foreground(lipgloss.Color("0")).Bold(true),  // Bright cyan
+			lipgloss.NewStyle().Background(lipgloss.Color("213")).Foreground(lipgloss.Color("0")).Bold(true), // Pink
+			lipgloss.NewStyle().Background(lipgloss.Color("120")).Foreground(lipgloss.Color("0")).Bold(true), // Light green
+			lipgloss.NewStyle().Background(lipgloss.Color("75")).Foreground(lipgloss.Color("0")).Bold(true),  // Light blue
+		},
+
+		// Source labels
+		SourceLabels: []lipgloss.Style{
+			lipgloss.NewStyle().Foreground(lipgloss.Color("#81ECEC")), // Light cyan
+			lipgloss.NewStyle().Foreground(lipgloss.Color("#DDA0DD")), // Light plum
+			lipgloss.NewStyle().Foreground(lipgloss.Color("#98FB98")), // Light green
+			lipgloss.NewStyle().Foreground(lipgloss.Color("#74B9FF")), // Light blue
+			lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB347")), // Light orange
+		},
+
+		// Stderr gutter
+		StderrGutter: lipgloss.NewStyle().Foreground(lipgloss.Color("#8B3A3A")), // Muted red
+
+		// Match gutter
+		MatchGutter: lipgloss.NewStyle().Foreground(lipgloss.Color("214")), // Bright orange
 	}
 }
 
@@ -258,3 +351,44 @@ func (t *ColorTheme) GetHTTPStatusStyle(statusCode string) lipgloss.Style {
 	}
 	return lipgloss.NewStyle()
 }
+
+// GetRoleStyle returns the style for a span of a line playing the given role.
+// Levels and status codes are styled by their text.
+func (t *ColorTheme) GetRoleStyle(role parser.Role, text string) lipgloss.Style {
+	switch role {
+	case parser.RoleTimestamp:
+		return t.Timestamp
+	case parser.RoleLevel:
+		return t.GetLogLevelStyle(strings.ToUpper(text))
+	case parser.RoleHostname:
+		return t.Hostname
+	case parser.RoleService:
+		return t.Service
+	case parser.RolePID:
+		return t.PID
+	case parser.RoleIP:
+		return t.IP
+	case parser.RoleMethod:
+		return t.Method
+	case parser.RoleURL:
+		return t.URL
+	case parser.RoleStatus:
+		return t.GetHTTPStatusStyle(text)
+	case parser.RoleFilename:
+		return t.Filename
+	case parser.RoleLineNum:
+		return t.LineNum
+	case parser.RoleKey:
+		return t.JSONKey
+	case parser.RoleValue:
+		return t.JSONValue
+	case parser.RoleString:
+		return t.JSONString
+	case parser.RoleNumber:
+		return t.JSONNumber
+	case parser.RolePunctuation:
+		return t.Bracket
+	default:
+		return lipgloss.NewStyle()
+	}
+}
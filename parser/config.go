@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatConfig is a file of user-defined formats:
+//
+//	formats:
+//	  - name: Payments
+//	    pattern: '^(?P<ts>\S+ \S+) \[(?P<level>\w+)\] (?P<service>[\w-]+): (?P<message>.*)'
+//	    specificity: 60
+//	    continuation: '^\s+'
+//	    roles:
+//	      ts: timestamp
+//	    sample: '2025-01-19 10:30:00 [ERROR] billing-api: card declined'
+type FormatConfig struct {
+	Formats []FormatDefinition `yaml:"formats"`
+}
+
+// FormatDefinition describes a format by a regular expression with named
+// capture groups
+type FormatDefinition struct {
+	Name string `yaml:"name"`
+	// Pattern detects the format's lines and splits them into fields named
+	// after its capture groups
+	Pattern string `yaml:"pattern"`
+	// Specificity ranks the format among the others, 50 by default like the
+	// built-in regex based formats. Higher wins.
+	Specificity int `yaml:"specificity"`
+	// Continuation, when set, matches the lines that continue a multi-line
+	// entry started by a line matching Pattern
+	Continuation string `yaml:"continuation"`
+	// Roles maps capture group names to the role their text plays, which
+	// picks its color. Groups named after a role, such as "timestamp" or
+	// "level", have that role without being listed.
+	Roles map[string]Role `yaml:"roles"`
+	// Sample is a typical line, shown by `splash formats`
+	Sample string `yaml:"sample"`
+}
+
+// defaultConfigSpecificity ranks config formats with the built-in regex based ones
+const defaultConfigSpecificity = 50
+
+// LoadFormatConfig reads and validates a format config file
+func LoadFormatConfig(path string) (*FormatConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ParseFormatConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ParseFormatConfig parses and validates the YAML of a format config
+func ParseFormatConfig(data []byte) (*FormatConfig, error) {
+	var config FormatConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i, def := range config.Formats {
+		if _, err := def.compile(); err != nil {
+			return nil, fmt.Errorf("format %d (%q): %w", i+1, def.Name, err)
+		}
+		key := formatKey(def.Name)
+		if seen[key] {
+			return nil, fmt.Errorf("format %d (%q): name used twice", i+1, def.Name)
+		}
+		seen[key] = true
+	}
+	return &config, nil
+}
+
+// Register adds every format of the config to the registry, so parsers
+// created afterwards detect them and the colorizer renders them
+func (c *FormatConfig) Register() ([]LogFormat, error) {
+	formats := make([]LogFormat, 0, len(c.Formats))
+	for _, def := range c.Formats {
+		format, err := def.Register()
+		if err != nil {
+			return formats, err
+		}
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// Register adds the format to the registry
+func (d FormatDefinition) Register() (LogFormat, error) {
+	detector, err := d.compile()
+	if err != nil {
+		return UnknownFormat, fmt.Errorf("format %q: %w", d.Name, err)
+	}
+	if _, taken := FormatByName(d.Name); taken {
+		return UnknownFormat, fmt.Errorf("format %q: name already in use", d.Name)
+	}
+
+	detector.format = RegisterFormat(d.Name)
+	registerLayout(detector.format, detector.pattern, detector.roles)
+	if detector.continuation != nil {
+		Register(&statefulPatternDetector{detector}, detector.segments)
+	} else {
+		Register(detector, detector.segments)
+	}
+	return detector.format, nil
+}
+
+// compile validates the definition and builds its detector, without a format yet
+func (d FormatDefinition) compile() (*patternDetector, error) {
+	if strings.TrimSpace(d.Name) == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+	if d.Pattern == "" {
+		return nil, fmt.Errorf("missing pattern")
+	}
+	pattern, err := regexp.Compile(d.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	detector := &patternDetector{
+		pattern:     pattern,
+		specificity: d.Specificity,
+		sample:      d.Sample,
+		roles:       make(map[string]Role),
+	}
+	if detector.specificity == 0 {
+		detector.specificity = defaultConfigSpecificity
+	}
+	if d.Continuation != "" {
+		detector.continuation, err = regexp.Compile(d.Continuation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid continuation: %w", err)
+		}
+	}
+
+	groups := make(map[string]bool)
+	for _, name := range pattern.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		groups[name] = true
+		if isRole(Role(name)) {
+			detector.roles[name] = Role(name)
+		}
+	}
+	for group, role := range d.Roles {
+		if !groups[group] {
+			return nil, fmt.Errorf("roles: pattern has no capture group %q", group)
+		}
+		if !isRole(role) {
+			return nil, fmt.Errorf("roles: unknown role %q for %q, want one of %s", role, group, roleList())
+		}
+		detector.roles[group] = role
+	}
+	return detector, nil
+}
+
+// patternDetector detects a format defined in a config file
+type patternDetector struct {
+	format       LogFormat
+	pattern      *regexp.Regexp
+	continuation *regexp.Regexp
+	specificity  int
+	sample       string
+	roles        map[string]Role // capture group name -> role
+}
+
+func (d *patternDetector) Detect(_ context.Context, line string) bool {
+	return d.pattern.MatchString(line)
+}
+
+func (d *patternDetector) Format() LogFormat {
+	return d.format
+}
+
+func (d *patternDetector) Specificity() int {
+	return d.specificity
+}
+
+func (d *patternDetector) PatternLength() int {
+	return len(d.pattern.String())
+}
+
+func (d *patternDetector) Pattern() string {
+	if d.continuation != nil {
+		return d.pattern.String() + "\n" + d.continuation.String()
+	}
+	return d.pattern.String()
+}
+
+func (d *patternDetector) Sample() string {
+	return d.sample
+}
+
+// segments marks each capture group that has a role
+func (d *patternDetector) segments(line string) []Segment {
+	loc := d.pattern.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return nil
+	}
+	var segments []Segment
+	for i, name := range d.pattern.SubexpNames() {
+		role, ok := d.roles[name]
+		if !ok || loc[2*i] < 0 {
+			continue
+		}
+		segments = append(segments, Segment{Span: Span{loc[2*i], loc[2*i+1]}, Role: role})
+	}
+	return segments
+}
+
+// statefulPatternDetector is a patternDetector whose entries span lines
+type statefulPatternDetector struct {
+	*patternDetector
+}
+
+func (d *statefulPatternDetector) DetectStart(ctx context.Context, line string) bool {
+	return d.Detect(ctx, line)
+}
+
+func (d *statefulPatternDetector) DetectContinuation(_ context.Context, line string) bool {
+	return d.continuation.MatchString(line)
+}
+
+func (d *statefulPatternDetector) DetectEnd(_ context.Context, _ string) bool {
+	return false
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFormatConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "missing pattern",
+			yaml: "formats:\n  - name: a\n",
+			want: `format 1 ("a"): missing pattern`,
+		},
+		{
+			name: "invalid pattern",
+			yaml: "formats:\n  - name: a\n    pattern: '(unclosed'\n",
+			want: `format 1 ("a"): invalid pattern`,
+		},
+		{
+			name: "role for a missing group",
+			yaml: "formats:\n  - name: a\n    pattern: '^(?P<ts>\\S+)'\n    roles:\n      when: timestamp\n",
+			want: `pattern has no capture group "when"`,
+		},
+		{
+			name: "unknown role",
+			yaml: "formats:\n  - name: a\n    pattern: '^(?P<ts>\\S+)'\n    roles:\n      ts: clock\n",
+			want: `unknown role "clock" for "ts"`,
+		},
+		{
+			name: "duplicate name",
+			yaml: "formats:\n  - name: a\n    pattern: x\n  - name: A\n    pattern: y\n",
+			want: `format 2 ("A"): name used twice`,
+		},
+		{
+			name: "unknown key",
+			yaml: "formats:\n  - name: a\n    patern: x\n",
+			want: "field patern not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFormatConfig([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseFormatConfig() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	if config, err := ParseFormatConfig(nil); err != nil || len(config.Formats) != 0 {
+		t.Errorf("ParseFormatConfig(empty) = %v, %v; want no formats", config, err)
+	}
+}
+
+func TestConfigFormat(t *testing.T) {
+	config, err := ParseFormatConfig([]byte(`
+formats:
+  - name: Test Payments
+    pattern: '^(?P<ts>\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) \[(?P<level>\w+)\] (?P<service>[\w-]+): (?P<message>.*)'
+    specificity: 60
+    continuation: '^\s+\|'
+    roles:
+      ts: timestamp
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	formats, err := config.Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+	format := formats[0]
+
+	if _, err := config.Register(); err == nil {
+		t.Error("registering the same config twice succeeded")
+	}
+	if got, ok := FormatByName("test payments"); !ok || got != format {
+		t.Errorf("FormatByName() = %v, %v; want %v", got, ok, format)
+	}
+
+	line := "2025-01-19 10:30:00 [WARN] billing-api: card declined"
+	p := NewParser()
+	if got := p.DetectFormat(line); got != format {
+		t.Fatalf("DetectFormat() = %v, want %v", got, format)
+	}
+	if got, continued := p.DetectEntry("    | retrying"); got != format || !continued {
+		t.Errorf("DetectEntry(continuation) = %v, %v; want %v, true", got, continued, format)
+	}
+
+	entry := ParseAs(line, format)
+	if entry.Level != LevelWarn || entry.Process.Value != "billing-api" || entry.Message.Value != "card declined" {
+		t.Errorf("ParseAs() level %v, process %q, message %q", entry.Level, entry.Process.Value, entry.Message.Value)
+	}
+	if want := time.Date(2025, 1, 19, 10, 30, 0, 0, time.Local); !entry.Timestamp.Time.Equal(want) {
+		t.Errorf("ParseAs() time = %v, want %v", entry.Timestamp.Time, want)
+	}
+	if ts, ok := entry.Attribute("ts"); !ok || ts.Value != "2025-01-19 10:30:00" {
+		t.Errorf("Attribute(ts) = %q, %v", ts.Value, ok)
+	}
+
+	var roles []string
+	for _, segment := range FormatColorizer(format)(line) {
+		roles = append(roles, string(segment.Role)+"="+line[segment.Span.Start:segment.Span.End])
+	}
+	want := "timestamp=2025-01-19 10:30:00,level=WARN,service=billing-api,message=card declined"
+	if got := strings.Join(roles, ","); got != want {
+		t.Errorf("segments = %s, want %s", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -25,55 +26,248 @@ type DetectionResult struct {
 	PatternLength int
 }
 
-// Parser handles log format detection with optimization for repeated formats
+// FirstByteFilter is an optional interface for detectors that can only match
+// lines starting with one of a known set of bytes. The parser uses it to
+// dispatch each line to the handful of detectors that could possibly match.
+type FirstByteFilter interface {
+	FirstBytes() string
+}
+
+// Prefilter is an optional interface for detectors that have a cheap check
+// (a byte comparison or substring test) which rules lines out before the
+// full, usually regex based, detection runs.
+type Prefilter interface {
+	MayMatch(line string) bool
+}
+
+// ConfidenceScorer is an optional interface for detectors that can tell a
+// weak match from a strong one. Confidence returns how likely the line is in
+// the detector's format, from 0 when Detect would reject it to 1 for a
+// certain match. Detectors without it score 1 for every line they detect.
+// The parser prefers the highest scoring detector, adding a bonus for the
+// previous line's format, and falls back to Specificity and PatternLength
+// only to break ties.
+type ConfidenceScorer interface {
+	Confidence(ctx context.Context, line string) float64
+}
+
+// FormatDescriber is an optional interface for detectors that can show how
+// they recognize lines, for listings such as `splash formats`
+type FormatDescriber interface {
+	// Pattern shows what the detector matches, usually as a regular
+	// expression. Detectors that match several kinds of line put each on its
+	// own line.
+	Pattern() string
+	// Sample returns a typical line of the format
+	Sample() string
+}
+
+// Byte sets shared by detectors implementing FirstByteFilter
+const (
+	whitespaceBytes = " \t\n\r\f\v"
+	digitBytes      = "0123456789"
+	upperBytes      = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	lowerBytes      = "abcdefghijklmnopqrstuvwxyz"
+	wordBytes       = upperBytes + lowerBytes + digitBytes + "_"
+)
+
+// emptyLineSlot is the dispatch slot used for empty lines
+const emptyLineSlot = 256
+
+// Parser handles log format detection with optimization for repeated formats.
+// Detection is synchronous and deterministic: detectors are ranked once at
+// construction and each line is checked against them in rank order.
 type Parser struct {
-	detectors              []FormatDetector
+	detectors              []FormatDetector      // ranked by specificity, then pattern length, then list order
+	dispatch               [257][]FormatDetector // ranked candidates by first byte, plus one slot for empty lines
 	previousFormat         LogFormat
 	previousDetector       FormatDetector
 	activeStatefulFormat   LogFormat        // Currently active multi-line format
 	activeStatefulDetector StatefulDetector // Currently active stateful detector
 	mu                     sync.RWMutex
+
+	// Every line is reported as forcedFormat with WithFormat
+	forced       bool
+	forcedFormat LogFormat
+
+	// Auto-lock state, see WithAutoLock
+	lockAfter      int
+	lockMisses     int
+	streak         int // consecutive lines detected by streakDetector
+	streakDetector FormatDetector
+	locked         FormatDetector // the pinned detector, or nil
+	missed         int            // consecutive lines the pinned detector rejected
 }
 
-// NewParser creates a new optimized parser with all supported detectors
-func NewParser() *Parser {
-	return &Parser{
-		detectors: []FormatDetector{
-			&JSONDetector{},
-			&LogfmtDetector{},
-			&StatefulJavaExceptionDetector{},       // High priority for Java exception headers
-			&StatefulJavaScriptExceptionDetector{}, // High priority for JavaScript exception headers
-			&StatefulPythonExceptionDetector{},     // High priority for Python traceback headers
-			&StatefulGoroutineStackTraceDetector{}, // High priority for Go stack trace headers
-			&GoTestDetector{},                      // High priority for specific go test patterns
-			&KubernetesDetector{},                  // Must be before DockerDetector
-			&HerokuDetector{},
-			&StatefulRsyslogDetector{}, // Before generic Syslog to be more specific
-			&NginxDetector{},           // Must be before ApacheCommonDetector
-			&ApacheCommonDetector{},
-			&DockerDetector{},
-			&RailsDetector{},
-			&SyslogDetector{},
-			&GoStandardDetector{},
-		},
+// NewParser creates a new optimized parser with all supported detectors: the
+// built-in ones followed by any added with Register. Options change the set.
+func NewParser(opts ...Option) *Parser {
+	var o parserOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	detectors := o.detectors
+	if detectors == nil {
+		detectors = append(builtinDetectors(), registeredDetectors()...)
+	}
+	if len(o.without) > 0 {
+		kept := make([]FormatDetector, 0, len(detectors))
+		for _, detector := range detectors {
+			if !o.without[detector.Format()] {
+				kept = append(kept, detector)
+			}
+		}
+		detectors = kept
+	}
+	if o.forced {
+		kept := make([]FormatDetector, 0, 1)
+		for _, detector := range detectors {
+			if detector.Format() == o.format {
+				kept = append(kept, detector)
+			}
+		}
+		detectors = kept
+	}
+
+	p := &Parser{
+		detectors:            append([]FormatDetector(nil), detectors...),
 		previousFormat:       UnknownFormat,
 		activeStatefulFormat: UnknownFormat,
+		forced:               o.forced,
+		forcedFormat:         o.format,
+		lockAfter:            o.lockAfter,
+		lockMisses:           o.lockMisses,
+	}
+	p.buildDispatch()
+	return p
+}
+
+// Detectors returns the parser's detectors in the order lines are checked
+// against them, most specific first
+func (p *Parser) Detectors() []FormatDetector {
+	return append([]FormatDetector(nil), p.detectors...)
+}
+
+// builtinDetectors returns a detector for each built-in format
+func builtinDetectors() []FormatDetector {
+	return []FormatDetector{
+		&JournalJSONDetector{},
+		&JournalExportDetector{},
+		&JSONDetector{},
+		&LogfmtDetector{},
+		&StatefulJavaExceptionDetector{},       // High priority for Java exception headers
+		&StatefulJavaScriptExceptionDetector{}, // High priority for JavaScript exception headers
+		&StatefulPythonExceptionDetector{},     // High priority for Python traceback headers
+		&StatefulGoroutineStackTraceDetector{}, // High priority for Go stack trace headers
+		&GoTestDetector{},                      // High priority for specific go test patterns
+		&KubernetesDetector{},                  // Must be before DockerDetector
+		&KlogDetector{},
+		&HerokuDetector{},
+		&StatefulRsyslogDetector{}, // Before generic Syslog to be more specific
+		&NginxDetector{},           // Must be before ApacheCommonDetector
+		&ApacheCommonDetector{},
+		&DockerDetector{},
+		&RailsDetector{},
+		&SyslogDetector{},
+		&RFC5424Detector{},
+		&GoStandardDetector{},
+	}
+}
+
+// buildDispatch ranks the detectors and builds the first-byte dispatch table.
+// Because every line is checked in rank order, detection can usually stop at
+// the first certain match.
+func (p *Parser) buildDispatch() {
+	sort.SliceStable(p.detectors, func(i, j int) bool {
+		a, b := p.detectors[i], p.detectors[j]
+		if a.Specificity() != b.Specificity() {
+			return a.Specificity() > b.Specificity()
+		}
+		// Tie-breaker: longer regex pattern is more specific, then list order
+		return a.PatternLength() > b.PatternLength()
+	})
+
+	for _, detector := range p.detectors {
+		filter, ok := detector.(FirstByteFilter)
+		if !ok {
+			for slot := range p.dispatch {
+				p.dispatch[slot] = append(p.dispatch[slot], detector)
+			}
+			continue
+		}
+		firstBytes := filter.FirstBytes()
+		for i := 0; i < len(firstBytes); i++ {
+			slot := firstBytes[i]
+			p.dispatch[slot] = append(p.dispatch[slot], detector)
+		}
 	}
 }
 
+// candidates returns the ranked detectors that could match the line
+func (p *Parser) candidates(line string) []FormatDetector {
+	if line == "" {
+		return p.dispatch[emptyLineSlot]
+	}
+	return p.dispatch[line[0]]
+}
+
+// detectBest returns the highest scoring detector matching the line, or nil.
+// Ties go to the previous line's format and then to the most specific detector.
+func (p *Parser) detectBest(ctx context.Context, line string, lc lineContext) FormatDetector {
+	var best FormatDetector
+	bestScore := 0.0
+
+	// Nothing can beat a certain match, except a detector continuing the
+	// format of the entry this line broke
+	enough := fullConfidence
+	if lc.active != UnknownFormat {
+		enough += activeEntryBonus
+	}
+
+	for _, detector := range p.candidates(line) {
+		if prefilter, ok := detector.(Prefilter); ok && !prefilter.MayMatch(line) {
+			continue
+		}
+		_, score := lc.score(ctx, detector, line)
+		if score > bestScore || score > 0 && score == bestScore && detector.Format() == lc.previous {
+			best, bestScore = detector, score
+		}
+		if bestScore >= enough {
+			break
+		}
+	}
+	return best
+}
+
 // DetectFormat detects the log format for a given line with optimization
 func (p *Parser) DetectFormat(line string) LogFormat {
+	format, _ := p.DetectEntry(line)
+	return format
+}
+
+// DetectEntry detects the format of a line like DetectFormat and also reports
+// whether the line continues the multi-line entry (a stack trace, for example)
+// started by an earlier line. Lines that are not continued start a new entry.
+func (p *Parser) DetectEntry(line string) (format LogFormat, continued bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	format, continued, _ = p.detectEntry(Unwrap(line))
+	return format, continued
+}
+
+// detectEntry implements DetectEntry and reports how it decided. The caller
+// holds p.mu.
+func (p *Parser) detectEntry(line string) (LogFormat, bool, Decision) {
+	ctx := context.Background()
+	lc := p.currentContext()
+
 	// Check if we have an active stateful detector
 	if p.activeStatefulDetector != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
-		defer cancel()
-
 		// Check if this line continues the current multi-line format
 		if p.activeStatefulDetector.DetectContinuation(ctx, line) {
-			return p.activeStatefulFormat
+			return p.activeStatefulFormat, true, DecisionContinued
 		}
 
 		// Check if this line ends the current multi-line format
@@ -82,7 +276,12 @@ func (p *Parser) DetectFormat(line string) LogFormat {
 			format := p.activeStatefulFormat
 			p.activeStatefulDetector = nil
 			p.activeStatefulFormat = UnknownFormat
-			return format
+			return format, true, DecisionEnded
+		}
+
+		// Lines such as "Caused by:" aren't continuations, but still belong to the entry
+		if joiner, ok := p.activeStatefulDetector.(EntryJoiner); ok && joiner.JoinsEntry(line) {
+			return p.activeStatefulFormat, true, DecisionJoined
 		}
 
 		// Line doesn't continue or end - check if it starts a new format
@@ -91,128 +290,158 @@ func (p *Parser) DetectFormat(line string) LogFormat {
 		p.activeStatefulFormat = UnknownFormat
 	}
 
-	// Try previous detector first if we have one (for non-stateful optimization)
-	previousDetector := p.previousDetector
-	if previousDetector != nil {
-		// Don't use previous detector if it was a stateful one that's now inactive
-		if stateful, ok := previousDetector.(StatefulDetector); ok {
-			if p.activeStatefulDetector != stateful {
-				previousDetector = nil
-			}
-		}
+	// With a forced format, detectors only track multi-line entries
+	if p.forced {
+		p.detectAllFormatsWithState(ctx, line, lc)
+		return p.forcedFormat, false, DecisionForced
 	}
 
-	if previousDetector != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
-		defer cancel()
+	if p.locked != nil {
+		if format, decision, ok := p.detectLocked(ctx, line, lc); ok {
+			return format, false, decision
+		}
+	}
 
-		if previousDetector.Detect(ctx, line) {
-			return previousDetector.Format()
+	// Try the previous line's detector first. With its bonus, a confident
+	// match can't be beaten, so the other detectors needn't be checked.
+	if lc.previous != UnknownFormat {
+		if _, score := lc.score(ctx, p.previousDetector, line); score >= fullConfidence {
+			p.countTowardsLock(p.previousDetector)
+			return p.previousDetector.Format(), false, DecisionPrevious
 		}
 	}
 
-	// Previous detector failed or doesn't exist, try all detectors concurrently
-	return p.detectAllFormatsWithState(line)
+	// Previous detector failed or doesn't exist, try the ranked candidates
+	format := p.detectAllFormatsWithState(ctx, line, lc)
+	if format == UnknownFormat {
+		p.countTowardsLock(nil)
+		return format, false, DecisionNone
+	}
+	p.countTowardsLock(p.previousDetector)
+	return format, false, DecisionRanked
 }
 
-// detectAllFormatsWithState runs all detectors concurrently and returns the most specific match
-// Also handles activation of stateful detectors
-func (p *Parser) detectAllFormatsWithState(line string) LogFormat {
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
-	defer cancel()
-
-	resultChan := make(chan DetectionResult, len(p.detectors))
-
-	// Start all detectors concurrently
-	for _, detector := range p.detectors {
-		go func(d FormatDetector) {
-			detected := d.Detect(ctx, line)
-			resultChan <- DetectionResult{
-				Format:        d.Format(),
-				Detected:      detected,
-				Specificity:   d.Specificity(),
-				PatternLength: d.PatternLength(),
-			}
-		}(detector)
+// detectLocked detects a line while the parser is pinned to a format. It
+// reports false once the pinned detector has rejected too many lines in a
+// row, releasing the lock so the line is detected as usual.
+func (p *Parser) detectLocked(ctx context.Context, line string, lc lineContext) (LogFormat, Decision, bool) {
+	if p.locked.Detect(ctx, line) {
+		p.missed = 0
+		return p.locked.Format(), DecisionLocked, true
 	}
 
-	// Collect all results and find the most specific match
-	var matches []DetectionResult
-LOOP:
-	for range len(p.detectors) {
-		select {
-		case result := <-resultChan:
-			if result.Detected {
-				matches = append(matches, result)
-			}
-		case <-ctx.Done():
-			break LOOP
+	// Stack traces and other multi-line entries still start while locked
+	if best := p.detectBest(ctx, line, lc); best != nil {
+		if stateful, ok := best.(StatefulDetector); ok && stateful.DetectStart(ctx, line) {
+			p.activeStatefulDetector = stateful
+			p.activeStatefulFormat = best.Format()
+			return best.Format(), DecisionRanked, true
 		}
 	}
 
-	if len(matches) == 0 {
-		return UnknownFormat
+	p.missed++
+	if p.missed < p.lockMisses {
+		return p.locked.Format(), DecisionLocked, true
 	}
+	p.locked = nil
+	p.missed = 0
+	return UnknownFormat, DecisionNone, false
+}
 
-	// Find the most specific match using specificity, then pattern length, then format order
-	bestMatch := matches[0]
-	for _, match := range matches[1:] {
-		if match.Specificity > bestMatch.Specificity {
-			bestMatch = match
-		} else if match.Specificity == bestMatch.Specificity {
-			// First tie-breaker: longer regex pattern is more specific
-			if match.PatternLength > bestMatch.PatternLength {
-				bestMatch = match
-			} else if match.PatternLength == bestMatch.PatternLength {
-				// Final tie-breaker: use format enum order (lower enum value = higher priority)
-				if match.Format < bestMatch.Format {
-					bestMatch = match
-				}
-			}
-		}
+// countTowardsLock records the detector of a line, or nil when none matched,
+// and pins it once it has matched enough lines in a row
+func (p *Parser) countTowardsLock(detector FormatDetector) {
+	if p.lockAfter == 0 {
+		return
+	}
+	if _, stateful := detector.(StatefulDetector); detector == nil || stateful {
+		p.streak, p.streakDetector = 0, nil
+		return
+	}
+	if detector != p.streakDetector {
+		p.streak, p.streakDetector = 0, detector
+	}
+	p.streak++
+	if p.streak >= p.lockAfter {
+		p.locked, p.missed = detector, 0
+		p.streak, p.streakDetector = 0, nil
+	}
+}
+
+// detectAllFormatsWithState returns the best match for the line.
+// Also handles activation of stateful detectors
+func (p *Parser) detectAllFormatsWithState(ctx context.Context, line string, lc lineContext) LogFormat {
+	best := p.detectBest(ctx, line, lc)
+	if best == nil {
+		return UnknownFormat
 	}
 
 	// Update previous format and detector
 	// Note: We're already holding the mutex from DetectFormat
-	p.previousFormat = bestMatch.Format
-	for _, detector := range p.detectors {
-		if detector.Format() == bestMatch.Format {
-			p.previousDetector = detector
+	p.previousFormat = best.Format()
+	p.previousDetector = best
 
-			// If this is a stateful detector that starts multi-line entries, activate it
-			if statefulDetector, ok := detector.(StatefulDetector); ok {
-				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
-				defer cancel()
-
-				if statefulDetector.DetectStart(ctx, line) {
-					p.activeStatefulDetector = statefulDetector
-					p.activeStatefulFormat = bestMatch.Format
-				}
-			}
-			break
-		}
+	// If this is a stateful detector that starts multi-line entries, activate it
+	if statefulDetector, ok := best.(StatefulDetector); ok && statefulDetector.DetectStart(ctx, line) {
+		p.activeStatefulDetector = statefulDetector
+		p.activeStatefulFormat = best.Format()
 	}
 
-	return bestMatch.Format
+	return best.Format()
 }
 
 // Individual detector implementations
 
 type JSONDetector struct{}
 
-func (d *JSONDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		var js json.RawMessage
-		done <- json.Unmarshal([]byte(line), &js) == nil
-	}()
-
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
+func (d *JSONDetector) Detect(_ context.Context, line string) bool {
+	return json.Valid([]byte(line))
+}
+
+// Confidence is high for objects, the usual shape of a JSON log line, and
+// lower for arrays and lone values such as a number, which may well be
+// plain output. It falls short of full for objects too, so that detectors
+// which recognize the keys of one logger's objects win over it.
+func (d *JSONDetector) Confidence(ctx context.Context, line string) float64 {
+	if !d.Detect(ctx, line) {
+		return 0
+	}
+	switch strings.TrimSpace(line)[0] {
+	case '{':
+		return 0.9
+	case '[':
+		return 0.75
+	default:
+		return 0.25
+	}
+}
+
+// FirstBytes lists the bytes a JSON value (optionally preceded by whitespace) can start with
+func (d *JSONDetector) FirstBytes() string {
+	return `{["-tfn"` + digitBytes + whitespaceBytes
+}
+
+// MayMatch checks that the first and last non-space bytes can delimit one JSON value
+func (d *JSONDetector) MayMatch(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
 		return false
 	}
+	first, last := line[0], line[len(line)-1]
+	switch {
+	case first == '{':
+		return last == '}'
+	case first == '[':
+		return last == ']'
+	case first == '"':
+		return last == '"'
+	case first == 't' || first == 'f':
+		return last == 'e'
+	case first == 'n':
+		return last == 'l'
+	default:
+		return last >= '0' && last <= '9'
+	}
 }
 
 func (d *JSONDetector) Format() LogFormat {
@@ -227,87 +456,115 @@ func (d *JSONDetector) PatternLength() int {
 	return 0 // Non-regex based detection
 }
 
+func (d *JSONDetector) Pattern() string {
+	return "a single JSON value"
+}
+
+func (d *JSONDetector) Sample() string {
+	return `{"timestamp":"2025-01-19T10:30:00Z","level":"ERROR","message":"DB failed","service":"api"}`
+}
+
 type LogfmtDetector struct{}
 
 func (d *LogfmtDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		line = strings.TrimSpace(line)
-		if len(line) == 0 {
-			done <- false
-			return
+	return d.Confidence(ctx, line) > 0
+}
+
+// Confidence is the share of the line's words that are key=value pairs, or
+// 0 when they aren't the majority. A lone pair only gets half of it, as
+// "KEY=value" on its own is as likely a shell assignment or a journal export
+// field.
+func (d *LogfmtDetector) Confidence(_ context.Context, line string) float64 {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 {
+		return 0
+	}
+
+	kvPairs := 0
+	totalTokens := 0
+
+	// Parse the line character by character to handle quoted values
+	i := 0
+	for i < len(line) {
+		// Skip whitespace
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		if i >= len(line) {
+			break
 		}
 
-		kvPairs := 0
-		totalTokens := 0
+		keyStart := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
 
-		// Parse the line character by character to handle quoted values
-		i := 0
-		for i < len(line) {
-			// Skip whitespace
-			for i < len(line) && line[i] == ' ' {
+		if i >= len(line) || line[i] != '=' || !isLogfmtKey(line[keyStart:i]) {
+			// Not a key=value pair, skip to next whitespace
+			for i < len(line) && line[i] != ' ' {
 				i++
 			}
-			if i >= len(line) {
-				break
-			}
+			totalTokens++
+			continue
+		}
 
-			for i < len(line) && line[i] != '=' && line[i] != ' ' {
-				i++
-			}
+		i++ // skip the '='
+
+		if i >= len(line) {
+			// Key with no value (key=)
+			kvPairs++
+			totalTokens++
+			break
+		}
 
-			if i >= len(line) || line[i] != '=' {
-				// Not a key=value pair, skip to next whitespace
-				for i < len(line) && line[i] != ' ' {
+		if line[i] == '"' {
+			// Quoted value
+			i++ // skip opening quote
+			for i < len(line) && line[i] != '"' {
+				if line[i] == '\\' && i+1 < len(line) {
+					i += 2 // skip escaped character
+				} else {
 					i++
 				}
-				totalTokens++
-				continue
 			}
-
-			i++ // skip the '='
-
-			if i >= len(line) {
-				// Key with no value (key=)
-				kvPairs++
-				totalTokens++
-				break
+			if i < len(line) {
+				i++ // skip closing quote
 			}
-
-			if line[i] == '"' {
-				// Quoted value
-				i++ // skip opening quote
-				for i < len(line) && line[i] != '"' {
-					if line[i] == '\\' && i+1 < len(line) {
-						i += 2 // skip escaped character
-					} else {
-						i++
-					}
-				}
-				if i < len(line) {
-					i++ // skip closing quote
-				}
-			} else {
-				// Unquoted value - read until whitespace
-				for i < len(line) && line[i] != ' ' {
-					i++
-				}
+		} else {
+			// Unquoted value - read until whitespace
+			for i < len(line) && line[i] != ' ' {
+				i++
 			}
-
-			kvPairs++
-			totalTokens++
 		}
-		done <- kvPairs > 0 && totalTokens > 0 && float64(kvPairs)/float64(totalTokens) > 0.5
-	}()
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+		kvPairs++
+		totalTokens++
+	}
+	if kvPairs == 0 || totalTokens == 0 {
+		return 0
+	}
+	share := float64(kvPairs) / float64(totalTokens)
+	switch {
+	case share <= 0.5:
+		return 0
+	case kvPairs == 1:
+		return share / 2
+	default:
+		return share
 	}
 }
 
+// isLogfmtKey reports whether a word's text before its '=' can be a logfmt
+// key, ruling out the likes of `{"url":"/?a` in JSON
+func isLogfmtKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, `"{}[]`)
+}
+
+// MayMatch rules out lines without a single key=value separator
+func (d *LogfmtDetector) MayMatch(line string) bool {
+	return strings.IndexByte(line, '=') >= 0
+}
+
 func (d *LogfmtDetector) Format() LogFormat {
 	return LogfmtFormat
 }
@@ -320,24 +577,32 @@ func (d *LogfmtDetector) PatternLength() int {
 	return 0 // Non-regex based detection
 }
 
+func (d *LogfmtDetector) Pattern() string {
+	return "key=value pairs, with values optionally quoted"
+}
+
+func (d *LogfmtDetector) Sample() string {
+	return `timestamp=2025-01-19T10:30:00Z level=error msg="DB failed" service=api`
+}
+
+// ApacheCommonDetector matches the Common Log Format. Its pattern spans the
+// whole line, so a match is certain.
 type ApacheCommonDetector struct{}
 
 const apacheCommonPattern = `^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3} - - \[\d{2}\/\w{3}\/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "[A-Z]+ .* HTTP\/\d\.\d" \d{3} \d+$`
 
 var apacheCommonRegex = regexp.MustCompile(apacheCommonPattern)
 
-func (d *ApacheCommonDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- apacheCommonRegex.MatchString(line)
-	}()
+func (d *ApacheCommonDetector) Detect(_ context.Context, line string) bool {
+	return apacheCommonRegex.MatchString(line)
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
-	}
+func (d *ApacheCommonDetector) FirstBytes() string {
+	return digitBytes
+}
+
+func (d *ApacheCommonDetector) MayMatch(line string) bool {
+	return strings.Contains(line, " HTTP/")
 }
 
 func (d *ApacheCommonDetector) Format() LogFormat {
@@ -352,24 +617,32 @@ func (d *ApacheCommonDetector) PatternLength() int {
 	return len(apacheCommonPattern)
 }
 
+func (d *ApacheCommonDetector) Pattern() string {
+	return apacheCommonPattern
+}
+
+func (d *ApacheCommonDetector) Sample() string {
+	return `127.0.0.1 - - [19/Jan/2025:10:30:00 +0000] "GET /api HTTP/1.1" 200 1234`
+}
+
+// NginxDetector matches the Combined Log Format nginx writes by default. Its
+// pattern spans the whole line, so a match is certain.
 type NginxDetector struct{}
 
 const nginxPattern = `^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3} - - \[\d{2}\/\w{3}\/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "[A-Z]+ .* HTTP\/\d\.\d" \d{3} \d+ ".*" ".*"$`
 
 var nginxRegex = regexp.MustCompile(nginxPattern)
 
-func (d *NginxDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- nginxRegex.MatchString(line)
-	}()
+func (d *NginxDetector) Detect(_ context.Context, line string) bool {
+	return nginxRegex.MatchString(line)
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
-	}
+func (d *NginxDetector) FirstBytes() string {
+	return digitBytes
+}
+
+func (d *NginxDetector) MayMatch(line string) bool {
+	return strings.Contains(line, " HTTP/") && strings.HasSuffix(line, `"`)
 }
 
 func (d *NginxDetector) Format() LogFormat {
@@ -384,24 +657,65 @@ func (d *NginxDetector) PatternLength() int {
 	return len(nginxPattern)
 }
 
+func (d *NginxDetector) Pattern() string {
+	return nginxPattern
+}
+
+func (d *NginxDetector) Sample() string {
+	return `127.0.0.1 - - [19/Jan/2025:10:30:00 +0000] "GET /api HTTP/1.1" 200 1234 "-" "Mozilla/5.0"`
+}
+
+// SyslogDetector matches BSD syslog lines in their common variants: an
+// optional <PRI> prefix, a "Jan  2 15:04:05" or RFC 3339 timestamp, and a tag
+// with or without a [pid], as in "kernel:". This also covers journalctl's
+// short-iso and short-precise output.
 type SyslogDetector struct{}
 
-const syslogPattern = `^\w{3} \d{1,2} \d{2}:\d{2}:\d{2} \S+ \S+\[\d+\]:`
+const syslogPattern = `^(?:<\d{1,3}>)?(?:\w{3} +\d{1,2} \d{2}:\d{2}:\d{2}(?:\.\d+)?|\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})) [^\s\[\]]+ [^\s\[:]+(?:\[\d+\])?:(?: |$)`
 
 var syslogRegex = regexp.MustCompile(syslogPattern)
 
-func (d *SyslogDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- syslogRegex.MatchString(line)
-	}()
+// Detect rejects lines whose host is a log level, which are Docker lines
+// with a colon after the first word of the message. It also leaves syslogd's
+// own lines to the Rsyslog detector, which follows their continuation lines.
+func (d *SyslogDetector) Detect(_ context.Context, line string) bool {
+	_, ok := syslogHeader(line)
+	return ok
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+// Confidence rises with the optional parts of the header the line has: a
+// <PRI> prefix and a [pid] after the tag
+func (d *SyslogDetector) Confidence(_ context.Context, line string) float64 {
+	header, ok := syslogHeader(line)
+	if !ok {
+		return 0
+	}
+	return evidenceConfidence(0.8, header[0] == '<', strings.HasSuffix(strings.TrimSuffix(header, " "), "]:"))
+}
+
+// syslogHeader returns the header of a syslog line, up to the colon after
+// the tag, if the Syslog detector accepts the line
+func syslogHeader(line string) (string, bool) {
+	loc := syslogRegex.FindStringIndex(line)
+	if loc == nil {
+		return "", false
 	}
+	// The matched header ends with the host and the tag
+	header := line[:loc[1]]
+	fields := strings.Fields(header)
+	host := fields[len(fields)-2]
+	if ParseLevel(host) != LevelUnknown || rsyslogStartRegex.MatchString(line) {
+		return "", false
+	}
+	return header, true
+}
+
+func (d *SyslogDetector) FirstBytes() string {
+	return wordBytes + "<"
+}
+
+func (d *SyslogDetector) MayMatch(line string) bool {
+	return strings.Contains(line, ": ") || strings.HasSuffix(line, ":")
 }
 
 func (d *SyslogDetector) Format() LogFormat {
@@ -416,24 +730,87 @@ func (d *SyslogDetector) PatternLength() int {
 	return len(syslogPattern)
 }
 
+func (d *SyslogDetector) Pattern() string {
+	return syslogPattern
+}
+
+func (d *SyslogDetector) Sample() string {
+	return `Jan 19 10:30:00 hostname myapp[1234]: ERROR: Database connection failed`
+}
+
+type RFC5424Detector struct{}
+
+const rfc5424Pattern = `^<\d{1,3}>\d{1,2} (?:-|\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})) \S+ \S+ \S+ \S+ (?:-|\[)`
+
+var rfc5424Regex = regexp.MustCompile(rfc5424Pattern)
+
+func (d *RFC5424Detector) Detect(_ context.Context, line string) bool {
+	return rfc5424Regex.MatchString(line)
+}
+
+// Confidence rises with a timestamp in place of the "-" for none, and with
+// structured data that is well formed rather than merely opening a bracket
+func (d *RFC5424Detector) Confidence(_ context.Context, line string) float64 {
+	loc := rfc5424Regex.FindStringIndex(line)
+	if loc == nil {
+		return 0
+	}
+	timestamp := line[strings.IndexByte(line, ' ')+1] != '-'
+	_, _, structuredData := ParseStructuredData(line, loc[1]-1)
+	return evidenceConfidence(0.8, timestamp, structuredData)
+}
+
+func (d *RFC5424Detector) FirstBytes() string {
+	return "<"
+}
+
+func (d *RFC5424Detector) Format() LogFormat {
+	return RFC5424Format
+}
+
+func (d *RFC5424Detector) Specificity() int {
+	return 50 // Tier 2: Regex-based formats
+}
+
+func (d *RFC5424Detector) PatternLength() int {
+	return len(rfc5424Pattern)
+}
+
+func (d *RFC5424Detector) Pattern() string {
+	return rfc5424Pattern
+}
+
+func (d *RFC5424Detector) Sample() string {
+	return `<134>1 2025-01-19T10:30:00.123Z web01 billing 4321 PAY001 [origin@32473 ip="10.0.0.5" region="eu"] Payment settled`
+}
+
 type GoStandardDetector struct{}
 
-const goStandardPattern = `^\d{4}\/\d{2}\/\d{2} \d{2}:\d{2}:\d{2}`
+const goStandardPattern = `^\d{4}\/\d{2}\/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)?( [^\s:]+\.go:\d+:)?`
 
 var goStandardRegex = regexp.MustCompile(goStandardPattern)
 
-func (d *GoStandardDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- goStandardRegex.MatchString(line)
-	}()
+func (d *GoStandardDetector) Detect(_ context.Context, line string) bool {
+	return goStandardRegex.MatchString(line)
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+// Confidence is high for the slashed date alone, which few loggers but Go's
+// write, and full with the fractional seconds or file:line that the
+// package's flags add
+func (d *GoStandardDetector) Confidence(_ context.Context, line string) float64 {
+	loc := goStandardRegex.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return 0
 	}
+	return evidenceConfidence(0.9, loc[2] >= 0, loc[4] >= 0)
+}
+
+func (d *GoStandardDetector) FirstBytes() string {
+	return digitBytes
+}
+
+func (d *GoStandardDetector) MayMatch(line string) bool {
+	return len(line) >= 19 && line[4] == '/' && line[7] == '/'
 }
 
 func (d *GoStandardDetector) Format() LogFormat {
@@ -448,24 +825,40 @@ func (d *GoStandardDetector) PatternLength() int {
 	return len(goStandardPattern)
 }
 
+func (d *GoStandardDetector) Pattern() string {
+	return goStandardPattern
+}
+
+func (d *GoStandardDetector) Sample() string {
+	return `2025/01/19 10:30:00 ERROR: Database connection failed`
+}
+
 type RailsDetector struct{}
 
 const railsPattern = `^\[\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\] \w+( --|  \w)`
 
 var railsRegex = regexp.MustCompile(railsPattern)
 
-func (d *RailsDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- railsRegex.MatchString(line)
-	}()
+func (d *RailsDetector) Detect(_ context.Context, line string) bool {
+	return railsRegex.MatchString(line)
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+// Confidence is full when the level is followed by the " -- " of Ruby's
+// Logger::Formatter, and lower when only spaces follow it
+func (d *RailsDetector) Confidence(_ context.Context, line string) float64 {
+	loc := railsRegex.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return 0
 	}
+	return evidenceConfidence(0.8, line[loc[2]:loc[3]] == " --")
+}
+
+func (d *RailsDetector) FirstBytes() string {
+	return "["
+}
+
+func (d *RailsDetector) MayMatch(line string) bool {
+	return len(line) > 21 && line[5] == '-' && line[20] == ']'
 }
 
 func (d *RailsDetector) Format() LogFormat {
@@ -480,24 +873,69 @@ func (d *RailsDetector) PatternLength() int {
 	return len(railsPattern)
 }
 
+func (d *RailsDetector) Pattern() string {
+	return railsPattern
+}
+
+func (d *RailsDetector) Sample() string {
+	return `[2025-01-19 10:30:00] ERROR -- : Database connection failed`
+}
+
+// isDigits reports whether text is a non-empty run of ASCII digits
+func isDigits(text string) bool {
+	if text == "" {
+		return false
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] < '0' || text[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isDuration reports whether text is a duration such as "0.02s"
+func isDuration(text string) bool {
+	_, err := time.ParseDuration(text)
+	return err == nil
+}
+
+// hasISODate reports whether the line starts with a YYYY-MM-DDT date
+func hasISODate(line string) bool {
+	return len(line) > 20 && line[4] == '-' && line[7] == '-' && line[10] == 'T'
+}
+
 type DockerDetector struct{}
 
 const dockerPattern = `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d+Z\s+[A-Z]+`
 
 var dockerRegex = regexp.MustCompile(dockerPattern)
 
-func (d *DockerDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- dockerRegex.MatchString(line)
-	}()
+func (d *DockerDetector) Detect(_ context.Context, line string) bool {
+	return dockerRegex.MatchString(line)
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+// Confidence is full when a log level follows the timestamp, and lower when
+// the message merely starts with a capital letter
+func (d *DockerDetector) Confidence(_ context.Context, line string) float64 {
+	loc := dockerRegex.FindStringIndex(line)
+	if loc == nil {
+		return 0
 	}
+	// The match ends inside the word after the timestamp
+	word := line[strings.LastIndexAny(line[:loc[1]], " \t")+1:]
+	if end := strings.IndexAny(word, " \t"); end >= 0 {
+		word = word[:end]
+	}
+	return evidenceConfidence(0.75, ParseLevel(strings.TrimSuffix(word, ":")) != LevelUnknown)
+}
+
+func (d *DockerDetector) FirstBytes() string {
+	return digitBytes
+}
+
+func (d *DockerDetector) MayMatch(line string) bool {
+	return hasISODate(line) && line[19] == '.'
 }
 
 func (d *DockerDetector) Format() LogFormat {
@@ -512,24 +950,40 @@ func (d *DockerDetector) PatternLength() int {
 	return len(dockerPattern)
 }
 
+func (d *DockerDetector) Pattern() string {
+	return dockerPattern
+}
+
+func (d *DockerDetector) Sample() string {
+	return `2025-01-19T10:30:00.123456789Z ERROR Database connection failed`
+}
+
 type KubernetesDetector struct{}
 
 const kubernetesPattern = `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d+Z \d+ \S+:\d+\] `
 
 var kubernetesRegex = regexp.MustCompile(kubernetesPattern)
 
-func (d *KubernetesDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- kubernetesRegex.MatchString(line)
-	}()
+func (d *KubernetesDetector) Detect(_ context.Context, line string) bool {
+	return kubernetesRegex.MatchString(line)
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+// Confidence is full when the file that logged the line is a Go source file,
+// as it is for Kubernetes components, and lower for any other file:line
+func (d *KubernetesDetector) Confidence(_ context.Context, line string) float64 {
+	loc := kubernetesRegex.FindStringIndex(line)
+	if loc == nil {
+		return 0
 	}
+	return evidenceConfidence(0.8, strings.Contains(line[:loc[1]], ".go:"))
+}
+
+func (d *KubernetesDetector) FirstBytes() string {
+	return digitBytes
+}
+
+func (d *KubernetesDetector) MayMatch(line string) bool {
+	return hasISODate(line) && line[19] == '.' && strings.Contains(line, "] ")
 }
 
 func (d *KubernetesDetector) Format() LogFormat {
@@ -544,24 +998,42 @@ func (d *KubernetesDetector) PatternLength() int {
 	return len(kubernetesPattern)
 }
 
+func (d *KubernetesDetector) Pattern() string {
+	return kubernetesPattern
+}
+
+func (d *KubernetesDetector) Sample() string {
+	return `2025-01-19T10:30:00.123Z 1 main.go:42] ERROR Database connection failed`
+}
+
 type HerokuDetector struct{}
 
 const herokuPattern = `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}[+-]\d{2}:\d{2} app\[\S+\]:`
 
 var herokuRegex = regexp.MustCompile(herokuPattern)
 
-func (d *HerokuDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- herokuRegex.MatchString(line)
-	}()
+func (d *HerokuDetector) Detect(_ context.Context, line string) bool {
+	return herokuRegex.MatchString(line)
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+// Confidence is full when the dyno is named like Heroku's, a process type
+// and a number such as "web.1", and lower for any other name in the brackets
+func (d *HerokuDetector) Confidence(_ context.Context, line string) float64 {
+	loc := herokuRegex.FindStringIndex(line)
+	if loc == nil {
+		return 0
 	}
+	start := strings.Index(line, " app[") + len(" app[")
+	processType, number, ok := strings.Cut(line[start:loc[1]-len("]:")], ".")
+	return evidenceConfidence(0.8, ok && processType != "" && isDigits(number))
+}
+
+func (d *HerokuDetector) FirstBytes() string {
+	return digitBytes
+}
+
+func (d *HerokuDetector) MayMatch(line string) bool {
+	return hasISODate(line) && strings.Contains(line, " app[")
 }
 
 func (d *HerokuDetector) Format() LogFormat {
@@ -576,24 +1048,41 @@ func (d *HerokuDetector) PatternLength() int {
 	return len(herokuPattern)
 }
 
+func (d *HerokuDetector) Pattern() string {
+	return herokuPattern
+}
+
+func (d *HerokuDetector) Sample() string {
+	return `2025-01-19T10:30:00+00:00 app[web.1]: ERROR Database connection failed`
+}
+
 type GoTestDetector struct{}
 
 const goTestPattern = `^(=== RUN|--- PASS:|--- FAIL:|--- SKIP:|=== NAME|=== CONT|\? .* \[no test files\]|PASS$|FAIL$|ok .* [\d\.]+[a-z]*$|FAIL .*)`
 
 var goTestRegex = regexp.MustCompile(goTestPattern)
 
-func (d *GoTestDetector) Detect(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- goTestRegex.MatchString(line)
-	}()
+func (d *GoTestDetector) Detect(_ context.Context, line string) bool {
+	return goTestRegex.MatchString(line)
+}
 
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+// Confidence is full for the markers only go test writes. A "FAIL " line
+// is as likely a message starting with the word, unless it goes on like a
+// package result, with the package and the time its tests took.
+func (d *GoTestDetector) Confidence(ctx context.Context, line string) float64 {
+	if !d.Detect(ctx, line) {
+		return 0
+	}
+	if !strings.HasPrefix(line, "FAIL ") {
+		return fullConfidence
 	}
+	fields := strings.Fields(line)
+	packageResult := len(fields) >= 3 && (isDuration(fields[2]) || strings.HasPrefix(fields[2], "["))
+	return evidenceConfidence(0.5, packageResult)
+}
+
+func (d *GoTestDetector) FirstBytes() string {
+	return "=-?PFo"
 }
 
 func (d *GoTestDetector) Format() LogFormat {
@@ -607,3 +1096,11 @@ func (d *GoTestDetector) Specificity() int {
 func (d *GoTestDetector) PatternLength() int {
 	return len(goTestPattern)
 }
+
+func (d *GoTestDetector) Pattern() string {
+	return goTestPattern
+}
+
+func (d *GoTestDetector) Sample() string {
+	return `--- FAIL: TestReconcile (0.02s)`
+}
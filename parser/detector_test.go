@@ -54,6 +54,86 @@ func TestOptimizedParser(t *testing.T) {
 			line:     `Jan 19 10:30:00 hostname myapp[1234]: ERROR: Database connection failed`,
 			expected: SyslogFormat,
 		},
+		{
+			name:     "Syslog format without a pid",
+			line:     `Jan  9 10:30:00 web01 kernel: usb 1-1: new high-speed USB device`,
+			expected: SyslogFormat,
+		},
+		{
+			name:     "Syslog format with a priority",
+			line:     `<13>Jan 19 10:30:00 web01 cron[42]: job started`,
+			expected: SyslogFormat,
+		},
+		{
+			name:     "Syslog format with a high-precision timestamp",
+			line:     `2025-01-19T10:30:00.123456+01:00 web01 sshd[812]: Accepted publickey for deploy`,
+			expected: SyslogFormat,
+		},
+		{
+			name:     "journalctl short-iso",
+			line:     `2025-01-19T10:30:00+0100 web01 billing[4321]: Payment settled`,
+			expected: SyslogFormat,
+		},
+		{
+			name:     "journalctl short-precise",
+			line:     `Jan 19 10:30:04.000048 web01 sshd[812]: Accepted publickey for deploy`,
+			expected: SyslogFormat,
+		},
+		{
+			name:     "journalctl JSON",
+			line:     `{"__CURSOR":"s=6f1c;i=1a2b","__REALTIME_TIMESTAMP":"1737282600123456","PRIORITY":"6","_HOSTNAME":"web01","MESSAGE":"Payment settled"}`,
+			expected: JournalJSONFormat,
+		},
+		{
+			name:     "journalctl export",
+			line:     `__CURSOR=s=6f1c;i=1a2b;b=0c9e;m=2d4a1;t=62c0ca0413c40;x=4e5d`,
+			expected: JournalExportFormat,
+		},
+		{
+			name:     "klog structured line",
+			line:     `I0119 10:30:00.123456       1 main.go:42] "Pod status updated" pod="kube-system/coredns" status="ready"`,
+			expected: KlogFormat,
+		},
+		{
+			name:     "klog error line",
+			line:     `E0119 10:30:01.000001    4521 reflector.go:138] Failed to watch *v1.Pod: unknown`,
+			expected: KlogFormat,
+		},
+		{
+			name:     "klog line with a millisecond timestamp",
+			line:     `W0119 10:30:02.123       7 cache.go:9] Cache is stale`,
+			expected: KlogFormat,
+		},
+		{
+			name:     "JSON in a CRI envelope",
+			line:     `2025-01-19T10:30:00.123456789Z stdout F {"level":"error","msg":"Payment failed"}`,
+			expected: JSONFormat,
+		},
+		{
+			name:     "logfmt from kubectl logs --prefix --timestamps",
+			line:     `[pod/web-7f9c6d8b5-2xk4q/app] 2025-01-19T10:30:00.123456789Z level=warn msg="slow request"`,
+			expected: LogfmtFormat,
+		},
+		{
+			name:     "Docker format with a colon in the message",
+			line:     `2025-01-19T10:30:00.123456789Z ERROR Database: connection failed`,
+			expected: DockerFormat,
+		},
+		{
+			name:     "Heroku format with a level and a colon",
+			line:     `2025-01-19T10:30:00+00:00 app[web.1]: ERROR: Database connection failed`,
+			expected: HerokuFormat,
+		},
+		{
+			name:     "RFC 5424 format",
+			line:     `<134>1 2025-01-19T10:30:00.123Z web01 billing 4321 PAY001 [origin@32473 ip="10.0.0.5"] Payment settled`,
+			expected: RFC5424Format,
+		},
+		{
+			name:     "RFC 5424 format with nil fields",
+			line:     `<11>1 - - - - - - disk full`,
+			expected: RFC5424Format,
+		},
 		{
 			name:     "Go standard format",
 			line:     `2025/01/19 10:30:00 ERROR: Database connection failed`,
@@ -0,0 +1,386 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Span is a byte range of a raw log line, Start inclusive and End exclusive
+type Span struct {
+	Start int
+	End   int
+}
+
+// Field is a value found in a log line along with where its raw text sits
+type Field struct {
+	Value string // the value, with any quoting or escaping decoded
+	Span  Span   // the raw text of the value, inside any quotes
+	found bool
+}
+
+// Found reports whether the line had the field
+func (f Field) Found() bool {
+	return f.found
+}
+
+// newField returns the field at [start, end) of line
+func newField(line string, start, end int) Field {
+	return Field{Value: line[start:end], Span: Span{start, end}, found: true}
+}
+
+// Timestamp is the timestamp field of an entry. Time is the zero time when
+// the text couldn't be understood.
+type Timestamp struct {
+	Field
+	Time time.Time
+}
+
+// Attribute is a named field without a dedicated place in LogEntry, such as
+// a JSON key or logfmt pair. Nested JSON keys are joined with dots.
+type Attribute struct {
+	Key     string
+	KeySpan Span
+	Value   Field
+}
+
+// LogEntry is a log line split into typed fields. Fields the line doesn't
+// have are left unset; see Field.Found.
+type LogEntry struct {
+	Raw    string
+	Format LogFormat
+	// Continued is set by Parser.Parse when the line continues the entry
+	// before it, like a stack frame
+	Continued bool
+
+	Timestamp Timestamp
+	Level     Level // normalized from LevelText
+	LevelText Field
+	Message   Field
+
+	Host    Field
+	Process Field
+	PID     Field
+
+	// HTTP request fields of access logs
+	Method Field
+	Path   Field
+	Status Field
+	Size   Field
+
+	// Source location, from stack frames and loggers that record it
+	File Field
+	Line Field
+
+	Attributes []Attribute
+}
+
+// Attribute returns the value of the attribute with the given key
+func (e *LogEntry) Attribute(key string) (Field, bool) {
+	for _, attr := range e.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return Field{}, false
+}
+
+// typedFields lists the dedicated fields of an entry under the names they
+// have in Fields and in line format patterns
+func (e *LogEntry) typedFields() []struct {
+	name  string
+	field *Field
+} {
+	return []struct {
+		name  string
+		field *Field
+	}{
+		{"timestamp", &e.Timestamp.Field},
+		{"level", &e.LevelText},
+		{"message", &e.Message},
+		{"host", &e.Host},
+		{"process", &e.Process},
+		{"pid", &e.PID},
+		{"method", &e.Method},
+		{"path", &e.Path},
+		{"status", &e.Status},
+		{"size", &e.Size},
+		{"file", &e.File},
+		{"line", &e.Line},
+	}
+}
+
+// Fields returns the entry's fields by name: the dedicated fields under
+// their lowercase names, then every attribute under its key. An attribute
+// with the same name as a dedicated field replaces it. Fields returns nil
+// when the entry has no fields.
+func (e *LogEntry) Fields() map[string]string {
+	var fields map[string]string
+	set := func(name, value string) {
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		fields[name] = value
+	}
+
+	for _, typed := range e.typedFields() {
+		if typed.field.Found() {
+			set(typed.name, typed.field.Value)
+		}
+	}
+	for _, attr := range e.Attributes {
+		set(attr.Key, attr.Value.Value)
+	}
+	return fields
+}
+
+// setField stores a field under its name, as a dedicated field or an attribute
+func (e *LogEntry) setField(name string, keySpan Span, field Field) {
+	for _, typed := range e.typedFields() {
+		if typed.name == name {
+			*typed.field = field
+			return
+		}
+	}
+	e.Attributes = append(e.Attributes, Attribute{Key: name, KeySpan: keySpan, Value: field})
+}
+
+// Parse detects the format of the next line of the stream and splits it into fields
+func (p *Parser) Parse(line string) LogEntry {
+	format, continued := p.DetectEntry(line)
+	entry := ParseAs(line, format)
+	entry.Continued = continued
+	return entry
+}
+
+// ParseAs splits a line already known to be in format into fields
+func ParseAs(line string, format LogFormat) LogEntry {
+	if envelope, ok := ParseEnvelope(line); ok {
+		entry := ParseAs(line[envelope.Span.End:], format)
+		entry.unwrap(line, envelope)
+		return entry
+	}
+
+	entry := LogEntry{Raw: line, Format: format}
+
+	switch format {
+	case JSONFormat:
+		entry.Attributes = jsonAttributes(line)
+		entry.promoteAttributes()
+	case LogfmtFormat:
+		entry.Attributes = logfmtAttributes(line)
+		entry.promoteAttributes()
+	case RFC5424Format:
+		entry.parseRFC5424()
+	case KlogFormat:
+		entry.parseKlog()
+	case JournalJSONFormat:
+		entry.Attributes = jsonAttributes(line)
+		entry.promoteJournalFields()
+	case JournalExportFormat:
+		entry.Attributes = exportAttributes(line)
+		entry.promoteJournalFields()
+	default:
+		entry.matchLayout()
+	}
+
+	if pri, ok := entry.Attribute("pri"); ok {
+		entry.decodePriority(pri)
+	}
+	if !entry.LevelText.Found() && entry.Message.Found() {
+		entry.LevelText = messageLevel(line, entry.Message)
+	}
+	entry.Level = ParseLevel(entry.LevelText.Value)
+	entry.Timestamp.Time = entryTime(entry.Timestamp.Field, format)
+	return entry
+}
+
+// layoutRegexes split the formats that have a fixed layout into fields. The
+// first pattern that matches wins. Group names are field names: those of
+// LogEntry's dedicated fields fill them, any other becomes an attribute.
+var layoutRegexes = map[LogFormat][]*regexp.Regexp{
+	ApacheCommonFormat: {
+		regexp.MustCompile(`^(?P<ip>\S+) (?P<ident>\S+) (?P<user>\S+) \[(?P<timestamp>[^\]]+)\] "(?P<method>[A-Z]+) (?P<path>[^"]*) (?P<protocol>[^"]*)" (?P<status>\d+) (?P<size>\S+)`),
+	},
+	NginxFormat: {
+		regexp.MustCompile(`^(?P<ip>\S+) (?P<ident>\S+) (?P<user>\S+) \[(?P<timestamp>[^\]]+)\] "(?P<method>[A-Z]+) (?P<path>[^"]*) (?P<protocol>[^"]*)" (?P<status>\d+) (?P<size>\S+) "(?P<referer>[^"]*)" "(?P<user_agent>[^"]*)"`),
+	},
+	SyslogFormat: {
+		regexp.MustCompile(`^(?:<(?P<pri>\d{1,3})>)?(?P<timestamp>\w{3}\s+\d{1,2} \d{2}:\d{2}:\d{2}(?:\.\d+)?|\d{4}-\d{2}-\d{2}T\S+) (?P<host>\S+) (?P<process>[^\s\[:]+)(?:\[(?P<pid>\d+)\])?: ?(?P<message>.*)`),
+	},
+	RsyslogFormat: {
+		regexp.MustCompile(`^(?P<timestamp>\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2})\s+(?P<host>\S+)\s+(?P<process>[^\s\[]+)\[(?P<pid>\d+)\]:\s*(?P<message>.*)`),
+	},
+	GoStandardFormat: {
+		regexp.MustCompile(`^(?P<timestamp>\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?) (?:(?P<file>[^\s:]+\.go):(?P<line>\d+): )?(?P<message>.*)`),
+	},
+	RailsFormat: {
+		regexp.MustCompile(`^\[(?P<timestamp>[^\]#]+?)(?: #(?P<pid>\d+))?\] (?P<level>\w+)\s+(?:-- : )?(?P<message>.*)`),
+	},
+	DockerFormat: {
+		regexp.MustCompile(`^(?P<timestamp>\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d+Z)\s+(?P<level>[A-Z]+)\s+(?P<message>.*)`),
+	},
+	KubernetesFormat: {
+		regexp.MustCompile(`^(?P<timestamp>\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d+Z) (?P<thread>\d+) (?P<file>[^:]+):(?P<line>\d+)\] (?P<message>.*)`),
+	},
+	HerokuFormat: {
+		regexp.MustCompile(`^(?P<timestamp>\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}[+-]\d{2}:\d{2}) app\[(?P<dyno>[^\]]+)\]: (?P<message>.*)`),
+	},
+	GoTestFormat: {
+		regexp.MustCompile(`^--- (?P<result>PASS|FAIL|SKIP): (?P<test>\S+)(?: \((?P<duration>[^)]*)\))?`),
+		regexp.MustCompile(`^=== (?P<action>RUN|NAME|CONT|PAUSE)\s+(?P<test>\S+)`),
+		regexp.MustCompile(`^(?P<result>ok|FAIL)\s+(?P<package>\S+)\s+(?P<duration>\S+)`),
+	},
+	JavaExceptionFormat: {
+		regexp.MustCompile(`^\s+at (?P<function>[^(]+)\((?P<file>[^:)]+):(?P<line>\d+)\)`),
+		regexp.MustCompile(`^Exception in thread "(?P<thread>[^"]+)" (?P<exception>[^\s:]+)(?::\s*(?P<message>.*))?`),
+		regexp.MustCompile(`^\s*Caused by: (?P<exception>[^\s:]+)(?::\s*(?P<message>.*))?`),
+	},
+	PythonExceptionFormat: {
+		regexp.MustCompile(`^\s*File "(?P<file>[^"]+)", line (?P<line>\d+)(?:, in (?P<function>.+))?`),
+		regexp.MustCompile(`^(?P<exception>[A-Za-z][\w.]*(?:Error|Exception))(?::\s*(?P<message>.*)|$)`),
+	},
+	JavaScriptExceptionFormat: {
+		regexp.MustCompile(`^\s+at (?:(?P<function>[^(]*?) \()?(?P<file>[^()\s]+):(?P<line>\d+):(?P<column>\d+)\)?`),
+		regexp.MustCompile(`^(?P<exception>\w*Error|\w+Exception):\s*(?P<message>.*)`),
+	},
+	GoroutineStackTraceFormat: {
+		regexp.MustCompile(`^goroutine (?P<goroutine>\d+) \[(?P<state>[^\]]+)\]:`),
+		regexp.MustCompile(`^\s+(?P<file>[^\s:]+\.\w+):(?P<line>\d+)`),
+	},
+}
+
+// matchLayout fills the entry from the first layout pattern of its format
+// that matches. For formats defined in a config file, groups whose role has a
+// dedicated field fill it as well as the attribute named after the group.
+func (e *LogEntry) matchLayout() {
+	regexes := layoutRegexes[e.Format]
+	var roles map[string]Role
+	if l, ok := registeredLayout(e.Format); ok {
+		regexes, roles = []*regexp.Regexp{l.pattern}, l.roles
+	}
+
+	for _, re := range regexes {
+		loc := re.FindStringSubmatchIndex(e.Raw)
+		if loc == nil {
+			continue
+		}
+		for i, name := range re.SubexpNames() {
+			start, end := loc[2*i], loc[2*i+1]
+			if name == "" || start < 0 {
+				continue
+			}
+			field := newField(e.Raw, start, end)
+			if typed, ok := roleFields[roles[name]]; ok && typed != name {
+				e.setField(typed, Span{}, field)
+			}
+			e.setField(name, Span{}, field)
+		}
+		return
+	}
+}
+
+// roleFields names the dedicated field that holds text of each role
+var roleFields = map[Role]string{
+	RoleTimestamp: "timestamp",
+	RoleLevel:     "level",
+	RoleMessage:   "message",
+	RoleHostname:  "host",
+	RoleService:   "process",
+	RolePID:       "pid",
+	RoleMethod:    "method",
+	RoleURL:       "path",
+	RoleStatus:    "status",
+	RoleFilename:  "file",
+	RoleLineNum:   "line",
+}
+
+// promotedKeys are the structured-log keys, matched without regard to case,
+// that fill each dedicated field. Attributes keep every key as well.
+var promotedKeys = map[string][]string{
+	"timestamp": timestampKeys,
+	"level":     {"level", "severity", "loglevel", "lvl"},
+	"message":   {"msg", "message"},
+	"host":      {"host", "hostname"},
+	"process":   {"process", "proc"},
+	"pid":       {"pid"},
+	"method":    {"method", "http.method", "http.request.method"},
+	"path":      {"path", "url", "http.path", "http.url", "http.request.path"},
+	"status":    {"status", "status_code", "http.status", "http.status_code", "http.response.status_code"},
+	"size":      {"size", "bytes", "http.response.size"},
+	"file":      {"file", "source.file"},
+	"line":      {"line", "source.line"},
+}
+
+// promoteAttributes fills the dedicated fields from well-known attribute keys
+func (e *LogEntry) promoteAttributes() {
+	for _, typed := range e.typedFields() {
+		for _, key := range promotedKeys[typed.name] {
+			if field, ok := e.attributeFold(key); ok {
+				*typed.field = field
+				break
+			}
+		}
+	}
+}
+
+// attributeFold returns the first attribute whose key matches regardless of case
+func (e *LogEntry) attributeFold(key string) (Field, bool) {
+	for _, attr := range e.Attributes {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Value, true
+		}
+	}
+	return Field{}, false
+}
+
+// messageLevels are the words that, leading a message, name its log level
+var messageLevels = map[string]bool{
+	"TRACE": true, "DEBUG": true, "INFO": true, "WARN": true, "WARNING": true,
+	"ERROR": true, "FATAL": true, "CRITICAL": true, "PANIC": true,
+}
+
+// messageLevel finds the log level a message starts with, as in "ERROR: disk full"
+func messageLevel(line string, message Field) Field {
+	word, _, _ := strings.Cut(message.Value, " ")
+	word = strings.TrimRight(word, ":")
+	if !messageLevels[strings.ToUpper(word)] || message.Span.End-message.Span.Start != len(message.Value) {
+		return Field{}
+	}
+	start := message.Span.Start
+	return newField(line, start, start+len(word))
+}
+
+// entryTime parses the text of a timestamp field the way its format writes timestamps
+func entryTime(field Field, format LogFormat) time.Time {
+	if !field.Found() {
+		return time.Time{}
+	}
+
+	var t time.Time
+	var ok bool
+	switch format {
+	case ApacheCommonFormat, NginxFormat:
+		parsed, err := time.ParseInLocation("02/Jan/2006:15:04:05 -0700", field.Value, time.Local)
+		t, ok = parsed, err == nil
+	case SyslogFormat, RsyslogFormat:
+		// rsyslog's high-precision timestamps are RFC 3339
+		if t, ok = syslogTimestamp(field.Value, time.Now()); !ok {
+			t, ok = parseTimeValue(field.Value)
+		}
+	case KlogFormat:
+		t, ok = klogTimestamp(field.Value, time.Now())
+	case JSONFormat, LogfmtFormat, JournalJSONFormat, JournalExportFormat:
+		t, ok = parseTimeValue(field.Value)
+		if n, err := strconv.ParseFloat(field.Value, 64); !ok && err == nil {
+			t, ok = parseEpoch(n)
+		}
+	default:
+		t, ok = parseTimeValue(field.Value)
+	}
+	if !ok {
+		return time.Time{}
+	}
+	return t
+}
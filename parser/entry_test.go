@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAsSpans(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		format LogFormat
+		fields map[string]string // dedicated field name -> raw text at its span
+	}{
+		{
+			name:   "JSON",
+			line:   `{"ts":"2025-01-19T10:30:00Z","level":"warning","msg":"say \"hi\"","http":{"request":{"method":"POST"}},"status":503}`,
+			format: JSONFormat,
+			fields: map[string]string{
+				"timestamp": "2025-01-19T10:30:00Z", "level": "warning", "message": `say \"hi\"`,
+				"method": "POST", "status": "503",
+			},
+		},
+		{
+			name:   "logfmt",
+			line:   `time=2025-01-19T10:30:00Z level=err msg="disk full" host=db1`,
+			format: LogfmtFormat,
+			fields: map[string]string{"timestamp": "2025-01-19T10:30:00Z", "level": "err", "message": "disk full", "host": "db1"},
+		},
+		{
+			name:   "Nginx",
+			line:   `10.0.0.5 - - [19/Jan/2025:08:30:00 +0000] "POST /login HTTP/2.0" 302 0 "-" "curl/8.0"`,
+			format: NginxFormat,
+			fields: map[string]string{
+				"timestamp": "19/Jan/2025:08:30:00 +0000", "method": "POST", "path": "/login", "status": "302", "size": "0",
+			},
+		},
+		{
+			name:   "syslog",
+			line:   `Jan 19 10:30:00 web01 myapp[1234]: ERROR: Database connection failed`,
+			format: SyslogFormat,
+			fields: map[string]string{
+				"timestamp": "Jan 19 10:30:00", "host": "web01", "process": "myapp", "pid": "1234",
+				"level": "ERROR", "message": "ERROR: Database connection failed",
+			},
+		},
+		{
+			name:   "Rails with pid",
+			line:   `[2025-01-19 10:30:00 #4242] INFO -- : Started GET "/"`,
+			format: RailsFormat,
+			fields: map[string]string{"timestamp": "2025-01-19 10:30:00", "pid": "4242", "level": "INFO", "message": `Started GET "/"`},
+		},
+		{
+			name:   "Go standard with file",
+			line:   `2025/01/19 10:30:00 main.go:42: starting`,
+			format: GoStandardFormat,
+			fields: map[string]string{"timestamp": "2025/01/19 10:30:00", "file": "main.go", "line": "42", "message": "starting"},
+		},
+		{
+			name:   "Python frame",
+			line:   `  File "app.py", line 21, in <module>`,
+			format: PythonExceptionFormat,
+			fields: map[string]string{"file": "app.py", "line": "21"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseAs(tt.line, tt.format)
+			for _, typed := range entry.typedFields() {
+				want, ok := tt.fields[typed.name]
+				if !ok {
+					if typed.field.Found() {
+						t.Errorf("%s = %q, want unset", typed.name, typed.field.Value)
+					}
+					continue
+				}
+				if !typed.field.Found() {
+					t.Errorf("%s not found, want %q", typed.name, want)
+					continue
+				}
+				if got := tt.line[typed.field.Span.Start:typed.field.Span.End]; got != want {
+					t.Errorf("%s span covers %q, want %q", typed.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAsAttributes(t *testing.T) {
+	line := `{"user":{"id":42,"name":"aé"},"tags":["x","y"]}`
+	entry := ParseAs(line, JSONFormat)
+
+	tests := []struct {
+		key     string
+		value   string
+		raw     string
+		keyText string
+	}{
+		{key: "user.id", value: "42", raw: "42", keyText: "id"},
+		{key: "user.name", value: "aé", raw: `aé`, keyText: "name"},
+		{key: "tags.1", value: "y", raw: "y", keyText: "tags"},
+		{key: "tags", value: `["x","y"]`, raw: `["x","y"]`, keyText: "tags"},
+	}
+	for _, tt := range tests {
+		field, ok := entry.Attribute(tt.key)
+		if !ok {
+			t.Errorf("Attribute(%q) not found", tt.key)
+			continue
+		}
+		if field.Value != tt.value {
+			t.Errorf("Attribute(%q) = %q, want %q", tt.key, field.Value, tt.value)
+		}
+		if got := line[field.Span.Start:field.Span.End]; got != tt.raw {
+			t.Errorf("Attribute(%q) span covers %q, want %q", tt.key, got, tt.raw)
+		}
+		for _, attr := range entry.Attributes {
+			if attr.Key == tt.key {
+				if got := line[attr.KeySpan.Start:attr.KeySpan.End]; got != tt.keyText {
+					t.Errorf("Attribute(%q) key span covers %q, want %q", tt.key, got, tt.keyText)
+				}
+				break
+			}
+		}
+	}
+}
+
+func TestParseAsLevelAndTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		format LogFormat
+		level  Level
+		time   time.Time
+	}{
+		{
+			name:   "JSON warning",
+			line:   `{"time":"2025-01-19T10:30:00Z","severity":"Warning","msg":"slow"}`,
+			format: JSONFormat,
+			level:  LevelWarn,
+			time:   time.Date(2025, 1, 19, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:   "JSON epoch seconds",
+			line:   `{"ts":1737282600,"level":"crit"}`,
+			format: JSONFormat,
+			level:  LevelFatal,
+			time:   time.Unix(1737282600, 0),
+		},
+		{
+			name:   "logfmt",
+			line:   `ts=2025-01-19T10:30:00Z level=err msg=boom`,
+			format: LogfmtFormat,
+			level:  LevelError,
+			time:   time.Date(2025, 1, 19, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:   "Apache",
+			line:   `127.0.0.1 - - [19/Jan/2025:10:30:00 +0000] "GET / HTTP/1.1" 200 1`,
+			format: ApacheCommonFormat,
+			level:  LevelUnknown,
+			time:   time.Date(2025, 1, 19, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:   "Docker",
+			line:   `2025-01-19T10:30:00.5Z DEBUG cache warm`,
+			format: DockerFormat,
+			level:  LevelDebug,
+			time:   time.Date(2025, 1, 19, 10, 30, 0, 500000000, time.UTC),
+		},
+		{
+			name:   "unparseable timestamp",
+			line:   `{"time":"yesterday","level":"info"}`,
+			format: JSONFormat,
+			level:  LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseAs(tt.line, tt.format)
+			if entry.Level != tt.level {
+				t.Errorf("Level = %v, want %v", entry.Level, tt.level)
+			}
+			if !entry.Timestamp.Time.Equal(tt.time) {
+				t.Errorf("Timestamp.Time = %v, want %v", entry.Timestamp.Time, tt.time)
+			}
+		})
+	}
+}
+
+func TestParserParseContinued(t *testing.T) {
+	p := NewParser()
+	lines := []struct {
+		line      string
+		format    LogFormat
+		continued bool
+	}{
+		{`Exception in thread "main" java.lang.IllegalStateException: boom`, JavaExceptionFormat, false},
+		{`	at com.example.Main.run(Main.java:42)`, JavaExceptionFormat, true},
+	}
+	for _, tt := range lines {
+		entry := p.Parse(tt.line)
+		if entry.Format != tt.format || entry.Continued != tt.continued {
+			t.Errorf("Parse(%q) = %v, continued %v; want %v, continued %v", tt.line, entry.Format, entry.Continued, tt.format, tt.continued)
+		}
+		if entry.Raw != tt.line {
+			t.Errorf("Parse(%q).Raw = %q", tt.line, entry.Raw)
+		}
+	}
+	if entry := p.Parse(`	at com.example.Main.run(Main.java:42)`); entry.File.Value != "Main.java" || entry.Line.Value != "42" {
+		t.Errorf("stack frame File:Line = %q:%q, want Main.java:42", entry.File.Value, entry.Line.Value)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{
+		"TRACE": LevelTrace, "debug": LevelDebug, "Info": LevelInfo, "WARNING": LevelWarn, "warn": LevelWarn,
+		"err": LevelError, "ERROR": LevelError, "CRITICAL": LevelFatal, "panic": LevelFatal, "": LevelUnknown, "verbose": LevelUnknown,
+	}
+	for text, want := range tests {
+		if got := ParseLevel(text); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// EnvelopeKind is the kind of wrapper a log collector put around a line
+type EnvelopeKind int
+
+const (
+	// NoEnvelope means the line is the app's own output
+	NoEnvelope EnvelopeKind = iota
+	// CRIEnvelope is the CRI log format container runtimes write to
+	// /var/log/containers: "2025-01-19T10:30:00.123456789Z stdout F message"
+	CRIEnvelope
+	// KubectlEnvelope is the prefix of kubectl logs --prefix, with the
+	// timestamp of --timestamps when given: "[pod/web-7f9c/app] 2025-01-19T10:30:00.123456789Z message"
+	KubectlEnvelope
+)
+
+// String returns the name of the envelope kind
+func (k EnvelopeKind) String() string {
+	switch k {
+	case CRIEnvelope:
+		return "CRI"
+	case KubectlEnvelope:
+		return "kubectl"
+	default:
+		return "none"
+	}
+}
+
+// Envelope is the wrapper a log collector put around the line an app wrote.
+// The line's format is that of the message inside: the parser detects it
+// with the envelope stripped, and ParseAs, ParseTimestamp and the colorizer
+// look through the envelope the same way.
+type Envelope struct {
+	Kind EnvelopeKind
+	Span Span // the envelope, from the start of the line to the message
+
+	Timestamp Timestamp
+	Stream    Field // stdout or stderr, for CRI
+	Tag       Field // P for a partial line and F for a full one, for CRI
+	Pod       Field // for kubectl
+	Container Field // for kubectl
+}
+
+// Partial reports whether the line is a partial CRI line, which the runtime
+// split off a longer line. The message continues on the stream's next line.
+func (e Envelope) Partial() bool {
+	return e.Tag.Value == "P"
+}
+
+// ParseEnvelope reads the envelope at the start of line, if it has one
+func ParseEnvelope(line string) (Envelope, bool) {
+	if line == "" {
+		return Envelope{}, false
+	}
+	switch c := line[0]; {
+	case c == '[':
+		return parseKubectlEnvelope(line)
+	case c >= '0' && c <= '9':
+		return parseCRIEnvelope(line)
+	default:
+		return Envelope{}, false
+	}
+}
+
+// Unwrap returns the message inside line's envelope, or line itself when it
+// has none
+func Unwrap(line string) string {
+	if envelope, ok := ParseEnvelope(line); ok {
+		return line[envelope.Span.End:]
+	}
+	return line
+}
+
+// parseCRIEnvelope reads "<RFC 3339 timestamp> <stream> <P|F> "
+func parseCRIEnvelope(line string) (Envelope, bool) {
+	timestamp, ok := envelopeTimestamp(line, 0)
+	if !ok {
+		return Envelope{}, false
+	}
+	pos := timestamp.Span.End + 1
+	stream := newField(line, pos, pos+len("stdout"))
+	if !strings.HasPrefix(line[pos:], "stdout ") && !strings.HasPrefix(line[pos:], "stderr ") {
+		return Envelope{}, false
+	}
+	pos = stream.Span.End + 1
+	if pos >= len(line) || (line[pos] != 'P' && line[pos] != 'F') {
+		return Envelope{}, false
+	}
+	tag := newField(line, pos, pos+1)
+	pos++
+	// An empty message may lose the space after the tag
+	if pos < len(line) {
+		if line[pos] != ' ' {
+			return Envelope{}, false
+		}
+		pos++
+	}
+	return Envelope{Kind: CRIEnvelope, Span: Span{0, pos}, Timestamp: timestamp, Stream: stream, Tag: tag}, true
+}
+
+// parseKubectlEnvelope reads "[pod/<name>/<container>] " and the timestamp
+// that may follow it
+func parseKubectlEnvelope(line string) (Envelope, bool) {
+	end := strings.IndexByte(line, ']')
+	if end < 0 || !strings.HasPrefix(line, "[pod/") {
+		return Envelope{}, false
+	}
+	source := line[len("[pod/"):end]
+	name, container, ok := strings.Cut(source, "/")
+	if !ok || name == "" || container == "" || strings.ContainsRune(container, '/') || strings.ContainsRune(source, ' ') {
+		return Envelope{}, false
+	}
+
+	podStart := len("[pod/")
+	envelope := Envelope{
+		Kind:      KubectlEnvelope,
+		Pod:       newField(line, podStart, podStart+len(name)),
+		Container: newField(line, podStart+len(name)+1, end),
+	}
+	pos := end + 1
+	if pos < len(line) {
+		if line[pos] != ' ' {
+			return Envelope{}, false
+		}
+		pos++
+	}
+	if timestamp, ok := envelopeTimestamp(line, pos); ok {
+		envelope.Timestamp = timestamp
+		pos = min(timestamp.Span.End+1, len(line))
+	}
+	envelope.Span = Span{0, pos}
+	return envelope, true
+}
+
+// envelopeTimestamp reads the RFC 3339 timestamp at offset start of line,
+// which must be followed by a space or the end of the line
+func envelopeTimestamp(line string, start int) (Timestamp, bool) {
+	end := strings.IndexByte(line[start:], ' ')
+	if end < 0 {
+		end = len(line)
+	} else {
+		end += start
+	}
+	// Every RFC 3339 timestamp has a 'T' at offset 10
+	if end-start < len("2006-01-02T15:04:05Z") || line[start+10] != 'T' {
+		return Timestamp{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, line[start:end])
+	if err != nil {
+		return Timestamp{}, false
+	}
+	return Timestamp{Field: newField(line, start, end), Time: t}, true
+}
+
+// unwrap turns an entry parsed from the message inside envelope back into an
+// entry of the whole line. The envelope's stream, pod and container become
+// attributes, and its timestamp stands in for a message without one.
+func (e *LogEntry) unwrap(line string, envelope Envelope) {
+	offset := envelope.Span.End
+	e.Raw = line
+	for _, typed := range e.typedFields() {
+		typed.field.shift(offset)
+	}
+	for i := range e.Attributes {
+		if e.Attributes[i].KeySpan != (Span{}) {
+			e.Attributes[i].KeySpan.Start += offset
+			e.Attributes[i].KeySpan.End += offset
+		}
+		e.Attributes[i].Value.shift(offset)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value Field
+	}{
+		{"stream", envelope.Stream},
+		{"pod", envelope.Pod},
+		{"container", envelope.Container},
+	} {
+		if field.value.Found() {
+			e.setField(field.name, Span{}, field.value)
+		}
+	}
+	if !e.Timestamp.Found() && envelope.Timestamp.Found() {
+		e.Timestamp = envelope.Timestamp
+	}
+}
+
+// shift moves the span of a found field by offset bytes
+func (f *Field) shift(offset int) {
+	if f.found {
+		f.Span.Start += offset
+		f.Span.End += offset
+	}
+}
+
+// LineScanner reads an input line by line like bufio.Scanner, and rejoins
+// the partial lines of CRI logs: the runtime splits a long line into lines
+// tagged P followed by one tagged F, each in its own envelope. The rejoined
+// line keeps the envelope of its first part, followed by the message of
+// every part.
+type LineScanner struct {
+	scanner *bufio.Scanner
+	pending []partialLine // lines still waiting for their F part, by stream
+	queue   []string      // complete lines not yet returned by Scan
+	text    string
+}
+
+// partialLine is a CRI line whose message continues on a later line of the stream
+type partialLine struct {
+	stream string
+	text   string
+}
+
+// NewLineScanner returns a scanner of the lines of r
+func NewLineScanner(r io.Reader) *LineScanner {
+	return &LineScanner{scanner: bufio.NewScanner(r)}
+}
+
+// Scan advances to the next line, which Text then returns. It returns false
+// at the end of the input or when reading it fails; Err tells which. Partial
+// lines whose F part never arrived are returned as they are at the end.
+func (s *LineScanner) Scan() bool {
+	for len(s.queue) == 0 {
+		if !s.scanner.Scan() {
+			s.flushPending()
+			if len(s.queue) == 0 {
+				return false
+			}
+			break
+		}
+		s.add(s.scanner.Text())
+	}
+	s.text, s.queue = s.queue[0], s.queue[1:]
+	return true
+}
+
+// Text returns the line read by the last call to Scan
+func (s *LineScanner) Text() string {
+	return s.text
+}
+
+// Err returns the error reading the input failed with, if any
+func (s *LineScanner) Err() error {
+	return s.scanner.Err()
+}
+
+// add queues line, or holds it until the rest of its message arrives
+func (s *LineScanner) add(line string) {
+	envelope, ok := ParseEnvelope(line)
+	if !ok || envelope.Kind != CRIEnvelope {
+		s.flushPending()
+		s.queue = append(s.queue, line)
+		return
+	}
+
+	stream := envelope.Stream.Value
+	for i, partial := range s.pending {
+		if partial.stream != stream {
+			continue
+		}
+		s.pending[i].text += line[envelope.Span.End:]
+		if !envelope.Partial() {
+			s.queue = append(s.queue, s.pending[i].text)
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+		}
+		return
+	}
+	if envelope.Partial() {
+		s.pending = append(s.pending, partialLine{stream: stream, text: line})
+		return
+	}
+	s.queue = append(s.queue, line)
+}
+
+// flushPending queues the partial lines still waiting for their F part
+func (s *LineScanner) flushPending() {
+	for _, partial := range s.pending {
+		s.queue = append(s.queue, partial.text)
+	}
+	s.pending = s.pending[:0]
+}
@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEnvelope(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		kind      EnvelopeKind
+		message   string
+		timestamp string
+		stream    string
+		tag       string
+		pod       string
+		container string
+	}{
+		{
+			name:      "CRI full line",
+			line:      `2025-01-19T10:30:00.123456789Z stdout F {"level":"info"}`,
+			kind:      CRIEnvelope,
+			message:   `{"level":"info"}`,
+			timestamp: "2025-01-19T10:30:00.123456789Z",
+			stream:    "stdout",
+			tag:       "F",
+		},
+		{
+			name:      "CRI partial line on stderr",
+			line:      `2025-01-19T10:30:00.123456789+01:00 stderr P first half`,
+			kind:      CRIEnvelope,
+			message:   "first half",
+			timestamp: "2025-01-19T10:30:00.123456789+01:00",
+			stream:    "stderr",
+			tag:       "P",
+		},
+		{
+			name:      "CRI empty message",
+			line:      `2025-01-19T10:30:00Z stdout F`,
+			kind:      CRIEnvelope,
+			timestamp: "2025-01-19T10:30:00Z",
+			stream:    "stdout",
+			tag:       "F",
+		},
+		{
+			name:      "kubectl prefix and timestamp",
+			line:      `[pod/web-7f9c6d8b5-2xk4q/app] 2025-01-19T10:30:00.123456789Z level=info msg=ok`,
+			kind:      KubectlEnvelope,
+			message:   "level=info msg=ok",
+			timestamp: "2025-01-19T10:30:00.123456789Z",
+			pod:       "web-7f9c6d8b5-2xk4q",
+			container: "app",
+		},
+		{
+			name:      "kubectl prefix alone",
+			line:      `[pod/web-7f9c6d8b5-2xk4q/app] Server listening`,
+			kind:      KubectlEnvelope,
+			message:   "Server listening",
+			pod:       "web-7f9c6d8b5-2xk4q",
+			container: "app",
+		},
+		{name: "Docker line", line: `2025-01-19T10:30:00.123456789Z ERROR Database connection failed`},
+		{name: "Kubernetes line", line: `2025-01-19T10:30:00.123Z 1 main.go:42] ERROR Database connection failed`},
+		{name: "CRI without a tag", line: `2025-01-19T10:30:00Z stdout message`},
+		{name: "CRI with a bad timestamp", line: `2025-01-19 10:30:00 stdout F message`},
+		{name: "Rails line", line: `[2025-01-19 10:30:00] INFO -- : Started GET "/"`},
+		{name: "kubectl prefix without a container", line: `[pod/web-7f9c6d8b5-2xk4q] Server listening`},
+		{name: "bracketed path", line: `[pod/a/b/c] message`},
+		{name: "empty line", line: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, ok := ParseEnvelope(tt.line)
+			if ok != (tt.kind != NoEnvelope) || envelope.Kind != tt.kind {
+				t.Fatalf("ParseEnvelope() = %v, %v; want %v", envelope.Kind, ok, tt.kind)
+			}
+			if !ok {
+				if got := Unwrap(tt.line); got != tt.line {
+					t.Errorf("Unwrap() = %q, want the line unchanged", got)
+				}
+				return
+			}
+
+			if got := Unwrap(tt.line); got != tt.message {
+				t.Errorf("Unwrap() = %q, want %q", got, tt.message)
+			}
+			got := map[string]string{
+				"timestamp": envelope.Timestamp.Value,
+				"stream":    envelope.Stream.Value,
+				"tag":       envelope.Tag.Value,
+				"pod":       envelope.Pod.Value,
+				"container": envelope.Container.Value,
+			}
+			want := map[string]string{
+				"timestamp": tt.timestamp,
+				"stream":    tt.stream,
+				"tag":       tt.tag,
+				"pod":       tt.pod,
+				"container": tt.container,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("envelope fields = %v, want %v", got, want)
+			}
+			if tt.timestamp != "" && envelope.Timestamp.Time.IsZero() {
+				t.Errorf("timestamp %q was not parsed", tt.timestamp)
+			}
+		})
+	}
+}
+
+func TestParseAsUnwrapsEnvelope(t *testing.T) {
+	line := `2025-01-19T10:30:00.5Z stderr F {"time":"2025-01-19T10:30:00Z","level":"error","msg":"Payment failed"}`
+	entry := ParseAs(line, JSONFormat)
+
+	if entry.Raw != line {
+		t.Errorf("Raw = %q, want the whole line", entry.Raw)
+	}
+	if entry.Level != LevelError {
+		t.Errorf("Level = %v, want %v", entry.Level, LevelError)
+	}
+	// Spans point into the whole line, past the envelope
+	if got := line[entry.Message.Span.Start:entry.Message.Span.End]; got != "Payment failed" {
+		t.Errorf("Message span covers %q, want %q", got, "Payment failed")
+	}
+	if want := time.Date(2025, 1, 19, 10, 30, 0, 0, time.UTC); !entry.Timestamp.Time.Equal(want) {
+		t.Errorf("Timestamp = %v, want the message's own %v", entry.Timestamp.Time, want)
+	}
+	if got := entry.Fields()["stream"]; got != "stderr" {
+		t.Errorf("stream = %q, want stderr", got)
+	}
+
+	entry = ParseAs(`[pod/web-1/app] 2025-01-19T10:30:00Z GET /healthz 200`, UnknownFormat)
+	fields := entry.Fields()
+	if fields["pod"] != "web-1" || fields["container"] != "app" {
+		t.Errorf("pod/container = %q/%q, want web-1/app", fields["pod"], fields["container"])
+	}
+	if want := time.Date(2025, 1, 19, 10, 30, 0, 0, time.UTC); !entry.Timestamp.Time.Equal(want) {
+		t.Errorf("Timestamp = %v, want the envelope's %v", entry.Timestamp.Time, want)
+	}
+}
+
+func TestLineScanner(t *testing.T) {
+	input := strings.Join([]string{
+		`2025-01-19T10:30:00Z stdout F whole`,
+		`2025-01-19T10:30:01Z stdout P {"msg":"a long`,
+		`2025-01-19T10:30:01Z stderr F interleaved`,
+		`2025-01-19T10:30:01Z stdout P  line split`,
+		`2025-01-19T10:30:01Z stdout F  in three"}`,
+		`2025-01-19T10:30:02Z stderr P cut off`,
+		`not an envelope`,
+		`2025-01-19T10:30:03Z stdout P never finished`,
+	}, "\n")
+
+	var got []string
+	scanner := NewLineScanner(strings.NewReader(input))
+	for scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	want := []string{
+		`2025-01-19T10:30:00Z stdout F whole`,
+		`2025-01-19T10:30:01Z stderr F interleaved`,
+		`2025-01-19T10:30:01Z stdout P {"msg":"a long line split in three"}`,
+		`2025-01-19T10:30:02Z stderr P cut off`,
+		`not an envelope`,
+		`2025-01-19T10:30:03Z stdout P never finished`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lines =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
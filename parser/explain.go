@@ -0,0 +1,90 @@
+package parser
+
+// Decision is how the parser settled on the format of a line
+type Decision int
+
+const (
+	// DecisionNone means no detector matched the line
+	DecisionNone Decision = iota
+	// DecisionRanked means the highest ranked matching detector won
+	DecisionRanked
+	// DecisionPrevious means the previous line's detector matched again and
+	// was kept without checking higher ranked detectors
+	DecisionPrevious
+	// DecisionContinued means the line continues the active multi-line entry
+	DecisionContinued
+	// DecisionJoined means the line belongs to the active multi-line entry
+	// without being a continuation, like the "Caused by:" of a Java exception
+	DecisionJoined
+	// DecisionEnded means the line ends the active multi-line entry
+	DecisionEnded
+	// DecisionForced means the parser was given the format with WithFormat
+	DecisionForced
+	// DecisionLocked means the parser is pinned to the format by WithAutoLock
+	DecisionLocked
+)
+
+// String describes the decision
+func (d Decision) String() string {
+	switch d {
+	case DecisionRanked:
+		return "highest ranked match"
+	case DecisionPrevious:
+		return "previous line's detector matched again"
+	case DecisionContinued:
+		return "continues the active entry"
+	case DecisionJoined:
+		return "joins the active entry"
+	case DecisionEnded:
+		return "ends the active entry"
+	case DecisionForced:
+		return "format given, detection skipped"
+	case DecisionLocked:
+		return "stream locked to the format"
+	default:
+		return "no detector matched"
+	}
+}
+
+// Explanation tells how the parser detected the format of a line
+type Explanation struct {
+	Format    LogFormat
+	Continued bool
+	Decision  Decision
+	// Active is the format of the multi-line entry in progress before the
+	// line, or UnknownFormat when there was none
+	Active LogFormat
+	// Locked is the format the parser was pinned to before the line, or
+	// UnknownFormat when it wasn't
+	Locked LogFormat
+	// Envelope is the kind of envelope stripped from the line before its
+	// message was detected
+	Envelope EnvelopeKind
+	// Candidates lists every detector that matches the line on its own, as
+	// Rank orders them. The winner isn't first when the line continues an
+	// entry or the parser is forced or locked to a format.
+	Candidates []Candidate
+}
+
+// Explain detects the format of the next line like DetectEntry, advancing
+// the parser's state the same way, and explains the decision
+func (p *Parser) Explain(line string) Explanation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	envelope, _ := ParseEnvelope(line)
+	line = line[envelope.Span.End:]
+
+	lc := p.currentContext()
+	e := Explanation{
+		Active:     lc.active,
+		Locked:     UnknownFormat,
+		Envelope:   envelope.Kind,
+		Candidates: p.rank(line, lc),
+	}
+	if p.locked != nil {
+		e.Locked = p.locked.Format()
+	}
+	e.Format, e.Continued, e.Decision = p.detectEntry(line)
+	return e
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// prefixDetector matches lines starting with prefix, standing in for
+// detectors whose patterns overlap
+type prefixDetector struct {
+	prefix      string
+	format      LogFormat
+	specificity int
+}
+
+func (d prefixDetector) Detect(_ context.Context, line string) bool {
+	return strings.HasPrefix(line, d.prefix)
+}
+func (d prefixDetector) Format() LogFormat  { return d.format }
+func (d prefixDetector) Specificity() int   { return d.specificity }
+func (d prefixDetector) PatternLength() int { return len(d.prefix) }
+
+func TestExplainPreviousDetector(t *testing.T) {
+	p := NewParser(WithDetectors(
+		prefixDetector{prefix: "[", format: GoStandardFormat, specificity: 50},
+		prefixDetector{prefix: "[rails]", format: RailsFormat, specificity: 60},
+	))
+
+	e := p.Explain("[go] started")
+	if e.Format != GoStandardFormat || e.Decision != DecisionRanked {
+		t.Errorf("Explain() = %v by %q, want %v by %q", e.Format, e.Decision, GoStandardFormat, DecisionRanked)
+	}
+
+	// The Rails detector ranks higher, but the previous line's format scores higher
+	e = p.Explain("[rails] Completed 200 OK")
+	want := Explanation{
+		Format:   GoStandardFormat,
+		Decision: DecisionPrevious,
+		Candidates: []Candidate{
+			{Format: GoStandardFormat, Specificity: 50, PatternLength: 1, Rank: 1, Confidence: 1, Score: 1.25},
+			{Format: RailsFormat, Specificity: 60, PatternLength: 7, Rank: 0, Confidence: 1, Score: 1},
+		},
+	}
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("Explain() = %+v, want %+v", e, want)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	p := NewParser()
+
+	e := p.Explain("TypeError: Cannot read properties of undefined (reading 'id')")
+	if e.Format != JavaScriptExceptionFormat || e.Decision != DecisionRanked {
+		t.Errorf("Explain() = %v by %q, want %v by %q", e.Format, e.Decision, JavaScriptExceptionFormat, DecisionRanked)
+	}
+	var formats []LogFormat
+	for _, c := range e.Candidates {
+		formats = append(formats, c.Format)
+	}
+	if want := []LogFormat{JavaScriptExceptionFormat, PythonExceptionFormat}; !reflect.DeepEqual(formats, want) {
+		t.Errorf("Candidates = %v, want %v", formats, want)
+	}
+
+	p = NewParser()
+	p.Explain(`Exception in thread "main" java.lang.IllegalStateException: boom`)
+	e = p.Explain("\tat com.example.Main.run(Main.java:42)")
+	if e.Format != JavaExceptionFormat || !e.Continued || e.Decision != DecisionContinued || e.Active != JavaExceptionFormat {
+		t.Errorf("Explain() of a stack frame = %+v, want a continued Java Exception", e)
+	}
+
+	e = p.Explain("plain text")
+	if e.Format != UnknownFormat || e.Decision != DecisionNone || len(e.Candidates) != 0 {
+		t.Errorf("Explain() of plain text = %+v, want no match", e)
+	}
+	if e.Active != JavaExceptionFormat {
+		t.Errorf("Active = %v, want %v", e.Active, JavaExceptionFormat)
+	}
+}
+
+func TestExplainMatchesDetectEntry(t *testing.T) {
+	lines := []string{
+		`{"level":"info","msg":"start"}`,
+		"Traceback (most recent call last):",
+		`  File "app.py", line 3, in <module>`,
+		"ValueError: bad value",
+		"2025/01/19 10:30:00 ERROR: Database connection failed",
+		"[2025-01-19 10:30:00] ERROR -- : Database connection failed",
+	}
+	detecting, explaining := NewParser(), NewParser()
+	for _, line := range lines {
+		format, continued := detecting.DetectEntry(line)
+		e := explaining.Explain(line)
+		if e.Format != format || e.Continued != continued {
+			t.Errorf("Explain(%q) = %v, %v, DetectEntry() = %v, %v", line, e.Format, e.Continued, format, continued)
+		}
+	}
+}
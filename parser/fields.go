@@ -0,0 +1,284 @@
+package parser
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Fields splits a line into the named fields its format defines, so they can
+// be queried without knowing how each format lays them out. It is shorthand
+// for ParseAs(line, format).Fields().
+//
+// JSON objects are flattened, with nested keys joined by dots
+// ("http.request.method") and array elements indexed ("tags.0"). Logfmt lines
+// yield their key=value pairs. Line formats with a fixed layout yield their
+// parts, such as ip, method, path and status for access logs or host,
+// process and pid for syslog, plus a level when the message starts with
+// one. Values are returned as text; JSON strings are unescaped and numbers
+// keep their original digits. Fields returns nil when the format has no
+// fields or the line doesn't match it.
+func Fields(line string, format LogFormat) map[string]string {
+	entry := ParseAs(line, format)
+	return entry.Fields()
+}
+
+// jsonAttributes flattens a JSON object into attributes keyed by dotted paths.
+// It returns nil unless the line is a valid JSON object.
+func jsonAttributes(line string) []Attribute {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") || !json.Valid([]byte(trimmed)) {
+		return nil
+	}
+
+	s := &jsonScanner{src: line}
+	s.skipSpace()
+	s.value("", "", Span{})
+	return s.attrs
+}
+
+// JSONToken is a key or a scalar value of a JSON line
+type JSONToken struct {
+	Span   Span   // the raw text, inside the quotes of a string
+	Value  string // the text with a string's escapes decoded
+	Key    bool   // whether the token is an object key rather than a value
+	String bool   // whether the token is quoted, as keys and string values are
+	Member string // for a value, the key it belongs to; empty in arrays
+	Depth  int    // the objects and arrays around the token
+}
+
+// ScanJSON calls visit with each key and scalar value of a JSON line, in
+// line order. What lies between the tokens is punctuation and whitespace.
+// ScanJSON reports false, without calling visit, unless the line is valid JSON.
+func ScanJSON(line string, visit func(JSONToken)) bool {
+	if !json.Valid([]byte(line)) {
+		return false
+	}
+	s := &jsonScanner{src: line, visit: visit}
+	s.skipSpace()
+	s.value("", "", Span{})
+	return true
+}
+
+// jsonScanner walks a line that is already known to be valid JSON, recording
+// where each value sits. With visit set it hands over the tokens instead.
+type jsonScanner struct {
+	src   string
+	pos   int
+	attrs []Attribute
+	visit func(JSONToken)
+	depth int
+}
+
+func (s *jsonScanner) peek() byte {
+	if s.pos < len(s.src) {
+		return s.src[s.pos]
+	}
+	return 0
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// str consumes a string and returns the span of its contents inside the quotes
+func (s *jsonScanner) str() Span {
+	s.pos++ // opening quote
+	start := s.pos
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return Span{start, s.pos - 1}
+		default:
+			s.pos++
+		}
+	}
+	return Span{start, len(s.src)}
+}
+
+// decode unescapes the contents of a JSON string
+func (s *jsonScanner) decode(span Span) string {
+	raw := s.src[span.Start:span.End]
+	if !strings.Contains(raw, `\`) {
+		return raw
+	}
+	var value string
+	if err := json.Unmarshal([]byte(`"`+raw+`"`), &value); err != nil {
+		return raw
+	}
+	return value
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// object consumes an object, adding an attribute for every value in it
+func (s *jsonScanner) object(path string) {
+	s.pos++ // '{'
+	s.depth++
+	s.skipSpace()
+	for s.peek() == '"' {
+		keySpan := s.str()
+		member := s.decode(keySpan)
+		s.token(JSONToken{Span: keySpan, Value: member, Key: true, String: true})
+		s.skipSpace()
+		s.pos++ // ':'
+		s.skipSpace()
+		s.value(joinPath(path, member), member, keySpan)
+		s.skipSpace()
+		if s.peek() != ',' {
+			break
+		}
+		s.pos++
+		s.skipSpace()
+	}
+	s.pos++ // '}'
+	s.depth--
+}
+
+// value consumes any value of the object member named member. Objects and
+// arrays are flattened; an array is also recorded whole, as its JSON text.
+func (s *jsonScanner) value(path, member string, keySpan Span) {
+	start := s.pos
+	switch s.peek() {
+	case '"':
+		span := s.str()
+		value := s.decode(span)
+		s.token(JSONToken{Span: span, Value: value, String: true, Member: member})
+		s.add(path, keySpan, Field{Value: value, Span: span, found: true})
+	case '{':
+		s.object(path)
+	case '[':
+		s.pos++
+		s.depth++
+		s.skipSpace()
+		for i := 0; s.peek() != ']' && s.pos < len(s.src); i++ {
+			s.value(joinPath(path, strconv.Itoa(i)), "", keySpan)
+			s.skipSpace()
+			if s.peek() != ',' {
+				break
+			}
+			s.pos++
+			s.skipSpace()
+		}
+		s.pos++ // ']'
+		s.depth--
+		s.add(path, keySpan, newField(s.src, start, s.pos))
+	default:
+		for s.pos < len(s.src) && !strings.ContainsRune(" \t\n\r,}]", rune(s.src[s.pos])) {
+			s.pos++
+		}
+		field := newField(s.src, start, s.pos)
+		s.token(JSONToken{Span: field.Span, Value: field.Value, Member: member})
+		if field.Value == "null" {
+			field.Value = ""
+		}
+		s.add(path, keySpan, field)
+	}
+}
+
+// token hands a token to visit, if set, at the current depth
+func (s *jsonScanner) token(tok JSONToken) {
+	if s.visit != nil {
+		tok.Depth = s.depth
+		s.visit(tok)
+	}
+}
+
+func (s *jsonScanner) add(key string, keySpan Span, value Field) {
+	if s.visit == nil {
+		s.attrs = append(s.attrs, Attribute{Key: key, KeySpan: keySpan, Value: value})
+	}
+}
+
+// logfmtAttributes reads the key=value pairs of a logfmt line. Bare words
+// are skipped. Quoted values are unescaped, with spans inside the quotes.
+func logfmtAttributes(line string) []Attribute {
+	return logfmtAttributesFrom(line, 0)
+}
+
+// logfmtAttributesFrom reads the key=value pairs of line from offset pos on
+func logfmtAttributesFrom(line string, pos int) []Attribute {
+	var attrs []Attribute
+	for pos < len(line) {
+		for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
+			pos++
+		}
+		eq := strings.IndexByte(line[pos:], '=')
+		if eq <= 0 {
+			break
+		}
+		keyStart, keyEnd := pos, pos+eq
+		// Skip bare words that aren't key=value pairs
+		if i := strings.LastIndexAny(line[keyStart:keyEnd], " \t"); i >= 0 {
+			keyStart += i + 1
+		}
+		pos = keyEnd + 1
+
+		var value Field
+		if pos < len(line) && line[pos] == '"' {
+			end := closingQuote(line, pos)
+			value = quotedField(line, pos, end)
+			pos = min(end+1, len(line))
+		} else {
+			end := strings.IndexAny(line[pos:], " \t")
+			if end < 0 {
+				end = len(line) - pos
+			}
+			value = newField(line, pos, pos+end)
+			pos += end
+		}
+
+		attrs = append(attrs, Attribute{Key: line[keyStart:keyEnd], KeySpan: Span{keyStart, keyEnd}, Value: value})
+	}
+	return attrs
+}
+
+// closingQuote finds the quote that closes the string opening at offset open,
+// skipping escaped quotes. It returns len(line) for an unterminated string.
+func closingQuote(line string, open int) int {
+	end := open + 1
+	for end < len(line) && line[end] != '"' {
+		if line[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	return min(end, len(line))
+}
+
+// quotedField is the string quoted from offset open to the quote at end,
+// unescaped, with its span inside the quotes
+func quotedField(line string, open, end int) Field {
+	field := newField(line, open+1, end)
+	if unquoted, err := strconv.Unquote(line[open:min(end+1, len(line))]); err == nil {
+		field.Value = unquoted
+	}
+	return field
+}
+
+// logfmtFields reads the key=value pairs of a logfmt line into a map. When a
+// key repeats its first value wins.
+func logfmtFields(line string) map[string]string {
+	values := make(map[string]string)
+	for _, attr := range logfmtAttributes(line) {
+		if _, seen := values[attr.Key]; !seen {
+			values[attr.Key] = attr.Value.Value
+		}
+	}
+	return values
+}
@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		format LogFormat
+		want   map[string]string
+	}{
+		{
+			name:   "JSON with nested paths",
+			line:   `{"level":"error","status":503,"id":12345678901234567890,"ok":false,"user":null,"http":{"request":{"method":"POST"}},"tags":["a","b"],"msg":"say \"hi\""}`,
+			format: JSONFormat,
+			want: map[string]string{
+				"level":               "error",
+				"status":              "503",
+				"id":                  "12345678901234567890",
+				"ok":                  "false",
+				"user":                "",
+				"http.request.method": "POST",
+				"tags":                `["a","b"]`,
+				"tags.0":              "a",
+				"tags.1":              "b",
+				"msg":                 `say "hi"`,
+				"message":             `say "hi"`,
+				"method":              "POST",
+			},
+		},
+		{
+			name:   "logfmt",
+			line:   `time=2025-01-19T08:30:00Z level=error msg="connection \"refused\"" status=500 bare`,
+			format: LogfmtFormat,
+			want: map[string]string{
+				"time":      "2025-01-19T08:30:00Z",
+				"level":     "error",
+				"msg":       `connection "refused"`,
+				"status":    "500",
+				"message":   `connection "refused"`,
+				"timestamp": "2025-01-19T08:30:00Z",
+			},
+		},
+		{
+			name:   "Apache common",
+			line:   `127.0.0.1 - frank [19/Jan/2025:08:30:00 +0000] "GET /api/users HTTP/1.1" 500 1234`,
+			format: ApacheCommonFormat,
+			want: map[string]string{
+				"ip": "127.0.0.1", "ident": "-", "user": "frank", "timestamp": "19/Jan/2025:08:30:00 +0000",
+				"method": "GET", "path": "/api/users", "protocol": "HTTP/1.1", "status": "500", "size": "1234",
+			},
+		},
+		{
+			name:   "Nginx",
+			line:   `10.0.0.5 - - [19/Jan/2025:08:30:00 +0000] "POST /login HTTP/2.0" 302 0 "-" "curl/8.0"`,
+			format: NginxFormat,
+			want: map[string]string{
+				"ip": "10.0.0.5", "ident": "-", "user": "-", "timestamp": "19/Jan/2025:08:30:00 +0000",
+				"method": "POST", "path": "/login", "protocol": "HTTP/2.0", "status": "302", "size": "0",
+				"referer": "-", "user_agent": "curl/8.0",
+			},
+		},
+		{
+			name:   "syslog with a level in the message",
+			line:   `Jan 19 10:30:00 web01 myapp[1234]: ERROR: Database connection failed`,
+			format: SyslogFormat,
+			want: map[string]string{
+				"timestamp": "Jan 19 10:30:00", "host": "web01", "process": "myapp", "pid": "1234",
+				"message": "ERROR: Database connection failed", "level": "ERROR",
+			},
+		},
+		{
+			name:   "syslog with a priority and no pid",
+			line:   `<11>Jan  9 10:30:00 web01 kernel: usb 1-1: new high-speed USB device`,
+			format: SyslogFormat,
+			want: map[string]string{
+				"pri": "11", "facility": "user", "severity": "err", "level": "err",
+				"timestamp": "Jan  9 10:30:00", "host": "web01", "process": "kernel",
+				"message": "usb 1-1: new high-speed USB device",
+			},
+		},
+		{
+			name:   "syslog with a high-precision timestamp",
+			line:   `2025-01-19T10:30:00.123456+01:00 web01 sshd[812]: Accepted publickey for deploy`,
+			format: SyslogFormat,
+			want: map[string]string{
+				"timestamp": "2025-01-19T10:30:00.123456+01:00", "host": "web01", "process": "sshd", "pid": "812",
+				"message": "Accepted publickey for deploy",
+			},
+		},
+		{
+			name:   "Go standard",
+			line:   `2025/01/19 10:30:00 WARN disk almost full`,
+			format: GoStandardFormat,
+			want:   map[string]string{"timestamp": "2025/01/19 10:30:00", "message": "WARN disk almost full", "level": "WARN"},
+		},
+		{
+			name:   "Rails",
+			line:   `[2025-01-19 10:30:00] ERROR -- : Database connection failed`,
+			format: RailsFormat,
+			want:   map[string]string{"timestamp": "2025-01-19 10:30:00", "level": "ERROR", "message": "Database connection failed"},
+		},
+		{
+			name:   "Docker",
+			line:   `2025-01-19T10:30:00.123456789Z INFO started`,
+			format: DockerFormat,
+			want:   map[string]string{"timestamp": "2025-01-19T10:30:00.123456789Z", "level": "INFO", "message": "started"},
+		},
+		{
+			name:   "Kubernetes",
+			line:   `2025-01-19T10:30:00.123Z 1 main.go:42] Database connection failed`,
+			format: KubernetesFormat,
+			want: map[string]string{
+				"timestamp": "2025-01-19T10:30:00.123Z", "thread": "1", "file": "main.go", "line": "42",
+				"message": "Database connection failed",
+			},
+		},
+		{
+			name:   "Heroku",
+			line:   `2025-01-19T10:30:00+00:00 app[web.1]: FATAL out of memory`,
+			format: HerokuFormat,
+			want: map[string]string{
+				"timestamp": "2025-01-19T10:30:00+00:00", "dyno": "web.1", "message": "FATAL out of memory", "level": "FATAL",
+			},
+		},
+		{
+			name:   "Java stack frame",
+			line:   `	at com.example.Main.run(Main.java:42)`,
+			format: JavaExceptionFormat,
+			want:   map[string]string{"function": "com.example.Main.run", "file": "Main.java", "line": "42"},
+		},
+		{
+			name:   "format without fields",
+			line:   `some plain text`,
+			format: UnknownFormat,
+			want:   nil,
+		},
+		{
+			name:   "line that doesn't fit its format",
+			line:   `not really nginx`,
+			format: NginxFormat,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fields(tt.line, tt.format); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []JSONToken
+	}{
+		{
+			name: "nested object and array",
+			line: `{"level":"warn","tags":["a",1],"http":{"ok":true}}`,
+			want: []JSONToken{
+				{Span: Span{2, 7}, Value: "level", Key: true, String: true, Depth: 1},
+				{Span: Span{10, 14}, Value: "warn", String: true, Member: "level", Depth: 1},
+				{Span: Span{17, 21}, Value: "tags", Key: true, String: true, Depth: 1},
+				{Span: Span{25, 26}, Value: "a", String: true, Depth: 2},
+				{Span: Span{28, 29}, Value: "1", Depth: 2},
+				{Span: Span{32, 36}, Value: "http", Key: true, String: true, Depth: 1},
+				{Span: Span{40, 42}, Value: "ok", Key: true, String: true, Depth: 2},
+				{Span: Span{44, 48}, Value: "true", Member: "ok", Depth: 2},
+			},
+		},
+		{
+			name: "escapes decoded",
+			line: ` {"msg": "say \"hi\""} `,
+			want: []JSONToken{
+				{Span: Span{3, 6}, Value: "msg", Key: true, String: true, Depth: 1},
+				{Span: Span{10, 20}, Value: `say "hi"`, String: true, Member: "msg", Depth: 1},
+			},
+		},
+		{
+			name: "top-level scalar",
+			line: `null`,
+			want: []JSONToken{{Span: Span{0, 4}, Value: "null"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []JSONToken
+			if !ScanJSON(tt.line, func(tok JSONToken) { got = append(got, tok) }) {
+				t.Fatalf("ScanJSON(%q) = false, want true", tt.line)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanJSON() tokens = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if ScanJSON(`{"broken":`, func(JSONToken) { t.Error("visit called for invalid JSON") }) {
+		t.Error("ScanJSON() = true for invalid JSON")
+	}
+}
@@ -24,6 +24,13 @@ const (
 	PythonExceptionFormat
 	JavaScriptExceptionFormat
 	GoroutineStackTraceFormat
+	RFC5424Format
+	JournalJSONFormat
+	JournalExportFormat
+	KlogFormat
+
+	// numBuiltinFormats is where the formats allocated by RegisterFormat start
+	numBuiltinFormats
 )
 
 // String returns the string representation of the log format
@@ -61,11 +68,32 @@ func (f LogFormat) String() string {
 		return "JavaScript Exception"
 	case GoroutineStackTraceFormat:
 		return "Goroutine Stack Trace"
+	case RFC5424Format:
+		return "RFC 5424"
+	case JournalJSONFormat:
+		return "Journal JSON"
+	case JournalExportFormat:
+		return "Journal Export"
+	case KlogFormat:
+		return "Klog"
 	default:
+		if name, ok := registeredFormatName(f); ok {
+			return name
+		}
 		return "Unknown"
 	}
 }
 
+// BuiltinFormats returns the formats splash detects without any registered
+// or configured ones, in the order of their LogFormat values
+func BuiltinFormats() []LogFormat {
+	formats := make([]LogFormat, 0, numBuiltinFormats-1)
+	for format := UnknownFormat + 1; format < numBuiltinFormats; format++ {
+		formats = append(formats, format)
+	}
+	return formats
+}
+
 // DetectFormat is deprecated. Use NewParser().DetectFormat() for stateful detection with better performance and accuracy.
 // This function is kept only for backward compatibility and will be removed in a future version.
 func DetectFormat(line string) LogFormat {
@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// journalKeys are the journal fields that fill each dedicated field, in order
+// of preference. Every field is kept as an attribute as well.
+var journalKeys = map[string][]string{
+	"timestamp": {"__REALTIME_TIMESTAMP"},
+	"message":   {"MESSAGE"},
+	"host":      {"_HOSTNAME"},
+	"process":   {"_SYSTEMD_UNIT", "SYSLOG_IDENTIFIER", "_COMM"},
+	"pid":       {"_PID", "SYSLOG_PID"},
+}
+
+// promoteJournalFields fills the dedicated fields from the journal's field
+// names, and the level from PRIORITY, which holds a bare syslog severity
+func (e *LogEntry) promoteJournalFields() {
+	for _, typed := range e.typedFields() {
+		for _, key := range journalKeys[typed.name] {
+			if field, ok := e.Attribute(key); ok {
+				*typed.field = field
+				break
+			}
+		}
+	}
+	if priority, ok := e.Attribute("PRIORITY"); ok {
+		if p, ok := ParsePriority(priority.Value); ok && p.Facility == 0 {
+			e.LevelText = Field{Value: p.SeverityName(), Span: priority.Span, found: true}
+		}
+	}
+}
+
+// exportAttributes reads a KEY=value line of a journal export record. Binary
+// fields, which have no '=', yield nothing.
+func exportAttributes(line string) []Attribute {
+	key, _, ok := strings.Cut(line, "=")
+	if !ok || key == "" {
+		return nil
+	}
+	return []Attribute{{Key: key, KeySpan: Span{0, len(key)}, Value: newField(line, len(key)+1, len(line))}}
+}
+
+// journalCursorTimestamp reads the realtime timestamp, hex microseconds since
+// the epoch, from the t= part of an export record's __CURSOR line. It is the
+// first line of the record, so a record's lines stay together when merged.
+func journalCursorTimestamp(line string) (time.Time, bool) {
+	cursor, ok := strings.CutPrefix(line, "__CURSOR=")
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, part := range strings.Split(cursor, ";") {
+		if hex, ok := strings.CutPrefix(part, "t="); ok {
+			usec, err := strconv.ParseInt(hex, 16, 64)
+			if err != nil || usec <= 0 {
+				return time.Time{}, false
+			}
+			return time.UnixMicro(usec), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// JournalJSONDetector matches records written by journalctl -o json, which
+// are JSON objects carrying the journal's __REALTIME_TIMESTAMP field
+type JournalJSONDetector struct{}
+
+func (d *JournalJSONDetector) Detect(_ context.Context, line string) bool {
+	return strings.Contains(line, `"__REALTIME_TIMESTAMP":`) && jsonAttributes(line) != nil
+}
+
+func (d *JournalJSONDetector) FirstBytes() string {
+	return "{" + whitespaceBytes
+}
+
+func (d *JournalJSONDetector) MayMatch(line string) bool {
+	return strings.HasSuffix(strings.TrimSpace(line), "}")
+}
+
+func (d *JournalJSONDetector) Format() LogFormat {
+	return JournalJSONFormat
+}
+
+func (d *JournalJSONDetector) Specificity() int {
+	return 110 // Above JSON: every journal record is also a JSON object
+}
+
+func (d *JournalJSONDetector) PatternLength() int {
+	return 0 // Non-regex based detection
+}
+
+func (d *JournalJSONDetector) Pattern() string {
+	return "a JSON object with a __REALTIME_TIMESTAMP key"
+}
+
+func (d *JournalJSONDetector) Sample() string {
+	return `{"__REALTIME_TIMESTAMP":"1737282600123456","PRIORITY":"3","_HOSTNAME":"web01","_SYSTEMD_UNIT":"billing.service","_PID":"4321","MESSAGE":"Payment failed"}`
+}
+
+// isJournalFieldName reports whether name is a journal field name: upper
+// case letters, digits and underscores, not starting with a digit
+func isJournalFieldName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// JournalExportDetector handles the records of journalctl -o export: a
+// __CURSOR= line, one KEY=value line per field and a blank line after the
+// record. A binary field is a bare KEY line followed by the data's length as
+// 8 little-endian bytes, which always include a NUL, and then the raw data.
+type JournalExportDetector struct{}
+
+func (d *JournalExportDetector) DetectStart(_ context.Context, line string) bool {
+	return strings.HasPrefix(line, "__CURSOR=")
+}
+
+func (d *JournalExportDetector) DetectContinuation(ctx context.Context, line string) bool {
+	if d.DetectStart(ctx, line) {
+		return false
+	}
+	name, _, _ := strings.Cut(line, "=")
+	return isJournalFieldName(name) || strings.IndexByte(line, 0) >= 0
+}
+
+func (d *JournalExportDetector) DetectEnd(_ context.Context, line string) bool {
+	return line == ""
+}
+
+func (d *JournalExportDetector) Detect(ctx context.Context, line string) bool {
+	return d.DetectStart(ctx, line)
+}
+
+func (d *JournalExportDetector) FirstBytes() string {
+	return "_"
+}
+
+func (d *JournalExportDetector) Format() LogFormat {
+	return JournalExportFormat
+}
+
+func (d *JournalExportDetector) Specificity() int {
+	return 110 // Above logfmt: a __CURSOR= line is also a key=value pair
+}
+
+func (d *JournalExportDetector) PatternLength() int {
+	return len("__CURSOR=")
+}
+
+func (d *JournalExportDetector) Pattern() string {
+	return "__CURSOR= starts a record, a blank line ends it"
+}
+
+func (d *JournalExportDetector) Sample() string {
+	return `__CURSOR=s=6f1c2a0e8d7b4b5f;i=1a2b;b=0c9e3f;m=2d4a1;t=62c0ca0413c40;x=4e5d`
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAsJournal(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		format  LogFormat
+		level   Level
+		time    time.Time
+		message string
+		host    string
+		process string
+		pid     string
+	}{
+		{
+			name:    "JSON",
+			line:    `{"__REALTIME_TIMESTAMP":"1737282600123456","PRIORITY":"3","SYSLOG_IDENTIFIER":"billing","_HOSTNAME":"web01","_SYSTEMD_UNIT":"billing.service","_PID":"4321","MESSAGE":"Payment failed"}`,
+			format:  JournalJSONFormat,
+			level:   LevelError,
+			time:    time.UnixMicro(1737282600123456),
+			message: "Payment failed",
+			host:    "web01",
+			process: "billing.service",
+			pid:     "4321",
+		},
+		{
+			name:    "JSON without a unit",
+			line:    `{"__REALTIME_TIMESTAMP":"1737282600123456","PRIORITY":"2","SYSLOG_IDENTIFIER":"kernel","MESSAGE":"EXT4-fs error"}`,
+			format:  JournalJSONFormat,
+			level:   LevelFatal,
+			time:    time.UnixMicro(1737282600123456),
+			message: "EXT4-fs error",
+			process: "kernel",
+		},
+		{
+			name:    "export message",
+			line:    `MESSAGE=Payment failed: gateway timeout`,
+			format:  JournalExportFormat,
+			message: "Payment failed: gateway timeout",
+		},
+		{
+			name:   "export priority",
+			line:   `PRIORITY=4`,
+			format: JournalExportFormat,
+			level:  LevelWarn,
+		},
+		{
+			name:   "export timestamp",
+			line:   `__REALTIME_TIMESTAMP=1737282600123456`,
+			format: JournalExportFormat,
+			time:   time.UnixMicro(1737282600123456),
+		},
+		{
+			name:   "export binary field name",
+			line:   `COREDUMP`,
+			format: JournalExportFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseAs(tt.line, tt.format)
+			if entry.Level != tt.level {
+				t.Errorf("Level = %v, want %v", entry.Level, tt.level)
+			}
+			if !entry.Timestamp.Time.Equal(tt.time) {
+				t.Errorf("Timestamp.Time = %v, want %v", entry.Timestamp.Time, tt.time)
+			}
+			got := []string{entry.Message.Value, entry.Host.Value, entry.Process.Value, entry.PID.Value}
+			if want := []string{tt.message, tt.host, tt.process, tt.pid}; !reflect.DeepEqual(got, want) {
+				t.Errorf("message, host, process, pid = %q, want %q", got, want)
+			}
+			if entry.Message.Found() && tt.line[entry.Message.Span.Start:entry.Message.Span.End] != tt.message {
+				t.Errorf("Message span covers %q", tt.line[entry.Message.Span.Start:entry.Message.Span.End])
+			}
+		})
+	}
+}
+
+func TestJournalExportEntries(t *testing.T) {
+	lines := []string{
+		"__CURSOR=s=6f1c;i=1a2b;b=0c9e;m=2d4a1;t=62c0ca0413c40;x=4e5d",
+		"__REALTIME_TIMESTAMP=1737282600123456",
+		"PRIORITY=3",
+		"COREDUMP",
+		"\x17\x00\x00\x00\x00\x00\x00\x00raw bytes = not a field",
+		"MESSAGE=Payment failed",
+		"",
+		"__CURSOR=s=6f1c;i=1a2c;b=0c9e;m=2d4a2;t=62c0ca0563d60;x=4e5e",
+		"MESSAGE=Payment settled",
+		"",
+		"plain text",
+	}
+	want := []struct {
+		format    LogFormat
+		continued bool
+	}{
+		{JournalExportFormat, false},
+		{JournalExportFormat, true},
+		{JournalExportFormat, true},
+		{JournalExportFormat, true},
+		{JournalExportFormat, true},
+		{JournalExportFormat, true},
+		{JournalExportFormat, true},
+		{JournalExportFormat, false},
+		{JournalExportFormat, true},
+		{JournalExportFormat, true},
+		{UnknownFormat, false},
+	}
+
+	p := NewParser()
+	for i, line := range lines {
+		format, continued := p.DetectEntry(line)
+		if format != want[i].format || continued != want[i].continued {
+			t.Errorf("DetectEntry(%q) = %v, %v, want %v, %v", line, format, continued, want[i].format, want[i].continued)
+		}
+	}
+}
+
+func TestJournalCursorTimestamp(t *testing.T) {
+	tests := []struct {
+		line string
+		want time.Time
+		ok   bool
+	}{
+		{line: "__CURSOR=s=6f1c;i=1a2b;b=0c9e;m=2d4a1;t=62c0ca0413c40;x=4e5d", want: time.UnixMicro(1737282600123456), ok: true},
+		{line: "__CURSOR=s=6f1c;i=1a2b", ok: false},
+		{line: "__CURSOR=s=6f1c;t=zz", ok: false},
+		{line: "__REALTIME_TIMESTAMP=1737282600123456", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseTimestamp(tt.line, JournalExportFormat)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want %v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
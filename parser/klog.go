@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// klogSeverities are the levels of klog's severity letters
+var klogSeverities = map[string]string{"I": "info", "W": "warning", "E": "error", "F": "fatal"}
+
+// parseKlog fills the entry from a klog line. Structured lines, written by
+// klog's InfoS and ErrorS, quote the message and follow it with key="value"
+// pairs, which become attributes.
+func (e *LogEntry) parseKlog() {
+	loc := klogRegex.FindStringSubmatchIndex(e.Raw)
+	if loc == nil {
+		return
+	}
+	for i, name := range klogRegex.SubexpNames() {
+		if name != "" {
+			e.setField(name, Span{}, newField(e.Raw, loc[2*i], loc[2*i+1]))
+		}
+	}
+	if severity, ok := e.Attribute("severity"); ok {
+		e.LevelText = Field{Value: klogSeverities[severity.Value], Span: severity.Span, found: true}
+	}
+
+	start := loc[1]
+	if !strings.HasPrefix(e.Raw[start:], `"`) {
+		e.Message = newField(e.Raw, start, len(e.Raw))
+		return
+	}
+	end := closingQuote(e.Raw, start)
+	e.Message = quotedField(e.Raw, start, end)
+	e.Attributes = append(e.Attributes, logfmtAttributesFrom(e.Raw, min(end+1, len(e.Raw)))...)
+}
+
+// KlogDetector matches the lines of klog, the logger of Kubernetes components
+// such as kube-apiserver and kubelet: a severity letter, the date and time,
+// a thread ID and the file:line that logged the line
+type KlogDetector struct{}
+
+// klogPattern is the header of a klog line, which both detects the line and
+// splits the header from the message
+const klogPattern = `^(?P<severity>[IWEF])(?P<timestamp>\d{4} \d{2}:\d{2}:\d{2}\.\d+) +(?P<thread>\d+) (?P<file>[^\s:]+):(?P<line>\d+)\] `
+
+var klogRegex = regexp.MustCompile(klogPattern)
+
+func (d *KlogDetector) Detect(_ context.Context, line string) bool {
+	return klogRegex.MatchString(line)
+}
+
+// Confidence rises with the parts of the header that klog always writes the
+// same way: microseconds in the timestamp and a Go source file
+func (d *KlogDetector) Confidence(_ context.Context, line string) float64 {
+	loc := klogRegex.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return 0
+	}
+	timestamp, file := line[loc[4]:loc[5]], line[loc[8]:loc[9]]
+	return evidenceConfidence(0.8, len(timestamp) == len("0102 15:04:05.000000"), strings.HasSuffix(file, ".go"))
+}
+
+func (d *KlogDetector) FirstBytes() string {
+	return "IWEF"
+}
+
+func (d *KlogDetector) MayMatch(line string) bool {
+	return strings.Contains(line, "] ")
+}
+
+func (d *KlogDetector) Format() LogFormat {
+	return KlogFormat
+}
+
+func (d *KlogDetector) Specificity() int {
+	return 50 // Tier 2: Regex-based formats
+}
+
+func (d *KlogDetector) PatternLength() int {
+	return len(klogPattern)
+}
+
+func (d *KlogDetector) Pattern() string {
+	return klogPattern
+}
+
+func (d *KlogDetector) Sample() string {
+	return `I0119 10:30:00.123456       1 main.go:42] "Pod status updated" pod="kube-system/coredns-5d78c9869d-xk2lp" status="ready"`
+}
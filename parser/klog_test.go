@@ -0,0 +1,103 @@
+package parser
+
+import "testing"
+
+func TestParseAsKlog(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		level      Level
+		levelText  string
+		message    string
+		file       string
+		lineNum    string
+		attributes map[string]string
+	}{
+		{
+			name:      "structured info",
+			line:      `I0119 10:30:00.123456       1 main.go:42] "Pod status updated" pod="kube-system/coredns" status="ready"`,
+			level:     LevelInfo,
+			levelText: "info",
+			message:   "Pod status updated",
+			file:      "main.go",
+			lineNum:   "42",
+			attributes: map[string]string{
+				"severity":  "I",
+				"timestamp": "0119 10:30:00.123456",
+				"thread":    "1",
+				"pod":       "kube-system/coredns",
+				"status":    "ready",
+			},
+		},
+		{
+			name:      "structured error with escaped quotes",
+			line:      `E0119 10:30:01.000001    4521 reflector.go:138] "Failed to watch \"pods\"" err="connection refused" retries=3`,
+			level:     LevelError,
+			levelText: "error",
+			message:   `Failed to watch "pods"`,
+			file:      "reflector.go",
+			lineNum:   "138",
+			attributes: map[string]string{
+				"err":     "connection refused",
+				"retries": "3",
+			},
+		},
+		{
+			name:      "plain warning",
+			line:      `W0119 10:30:02.000000       7 cache.go:9] Unexpected "key=value" in message`,
+			level:     LevelWarn,
+			levelText: "warning",
+			message:   `Unexpected "key=value" in message`,
+			file:      "cache.go",
+			lineNum:   "9",
+		},
+		{
+			name:      "fatal",
+			line:      `F0119 10:30:03.000000       1 server.go:77] unable to bind port`,
+			level:     LevelFatal,
+			levelText: "fatal",
+			message:   "unable to bind port",
+			file:      "server.go",
+			lineNum:   "77",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseAs(tt.line, KlogFormat)
+			if entry.Level != tt.level {
+				t.Errorf("Level = %v, want %v", entry.Level, tt.level)
+			}
+			if entry.LevelText.Value != tt.levelText {
+				t.Errorf("LevelText = %q, want %q", entry.LevelText.Value, tt.levelText)
+			}
+			if entry.Message.Value != tt.message {
+				t.Errorf("Message = %q, want %q", entry.Message.Value, tt.message)
+			}
+			if entry.File.Value != tt.file || entry.Line.Value != tt.lineNum {
+				t.Errorf("File:Line = %s:%s, want %s:%s", entry.File.Value, entry.Line.Value, tt.file, tt.lineNum)
+			}
+			if entry.Timestamp.Time.IsZero() {
+				t.Errorf("Timestamp %q was not parsed", entry.Timestamp.Value)
+			}
+
+			fields := entry.Fields()
+			for key, want := range tt.attributes {
+				if got := fields[key]; got != want {
+					t.Errorf("field %q = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAsKlogMessageSpan(t *testing.T) {
+	line := `I0119 10:30:00.123456       1 main.go:42] "Pod status updated" pod="a"`
+	entry := ParseAs(line, KlogFormat)
+
+	// The span of a quoted message covers the text inside the quotes
+	got := line[entry.Message.Span.Start:entry.Message.Span.End]
+	if got != "Pod status updated" {
+		t.Errorf("Message span covers %q, want the unquoted message", got)
+	}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import "strings"
+
+// Level is a log level normalized from the many ways formats spell it
+type Level int
+
+const (
+	LevelUnknown Level = iota
+	LevelTrace
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+// levelNames maps the lowercase spellings of each level to it
+var levelNames = map[string]Level{
+	"trace":     LevelTrace,
+	"debug":     LevelDebug,
+	"info":      LevelInfo,
+	"notice":    LevelInfo,
+	"warn":      LevelWarn,
+	"warning":   LevelWarn,
+	"error":     LevelError,
+	"err":       LevelError,
+	"fatal":     LevelFatal,
+	"critical":  LevelFatal,
+	"emerg":     LevelFatal,
+	"emergency": LevelFatal,
+	"alert":     LevelFatal,
+	"crit":      LevelFatal,
+	"panic":     LevelFatal,
+}
+
+// ParseLevel normalizes a level name such as "WARNING" or "err", regardless
+// of case. It returns LevelUnknown for text that isn't a level.
+func ParseLevel(text string) Level {
+	return levelNames[strings.ToLower(strings.TrimSpace(text))]
+}
+
+// String returns the level's canonical uppercase name
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "TRACE"
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelFatal:
+		return "FATAL"
+	default:
+		return "UNKNOWN"
+	}
+}
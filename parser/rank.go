@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"context"
+	"sort"
+)
+
+// Scoring of detector matches. A detector's confidence in a line is at most
+// fullConfidence, and the context of earlier lines adds to it.
+const (
+	fullConfidence = 1.0
+	// previousLineBonus favors the previous line's format, as lines of a
+	// stream tend to share one
+	previousLineBonus = 0.25
+	// activeEntryBonus favors the format of a multi-line entry that the line
+	// broke, as one stack trace often follows another
+	activeEntryBonus = 0.25
+)
+
+// evidenceConfidence is the confidence of a match that has the base
+// confidence on its own and gains the rest of fullConfidence in equal shares
+// for each piece of evidence found, such as an optional part of the format's
+// layout being present
+func evidenceConfidence(base float64, evidence ...bool) float64 {
+	found := 0
+	for _, ok := range evidence {
+		if ok {
+			found++
+		}
+	}
+	switch found {
+	case 0:
+		return base
+	case len(evidence):
+		return fullConfidence
+	}
+	return base + (fullConfidence-base)*float64(found)/float64(len(evidence))
+}
+
+// Candidate is a detector that matched a line
+type Candidate struct {
+	Format        LogFormat
+	Specificity   int
+	PatternLength int
+	Rank          int     // position among the parser's detectors, 0 for the first checked
+	Confidence    float64 // how well the line matches on its own, up to 1
+	Score         float64 // the confidence plus the bonuses from earlier lines
+}
+
+// lineContext is what the parser knows from the lines before the one being detected
+type lineContext struct {
+	previous LogFormat // the previous line's format, unless it started a multi-line entry
+	active   LogFormat // the multi-line entry in progress, or UnknownFormat
+}
+
+// currentContext returns the context for the next line. The caller holds p.mu.
+func (p *Parser) currentContext() lineContext {
+	lc := lineContext{previous: UnknownFormat, active: UnknownFormat}
+	if p.activeStatefulDetector != nil {
+		lc.active = p.activeStatefulFormat
+	}
+	// Stateful detectors only match again through their entry's continuations
+	if _, stateful := p.previousDetector.(StatefulDetector); p.previousDetector != nil && !stateful {
+		lc.previous = p.previousDetector.Format()
+	}
+	return lc
+}
+
+// score returns how confident a detector is in a line and its score given
+// the context. Both are 0 when the detector rejects the line.
+func (lc lineContext) score(ctx context.Context, detector FormatDetector, line string) (confidence, score float64) {
+	if scorer, ok := detector.(ConfidenceScorer); ok {
+		confidence = scorer.Confidence(ctx, line)
+	} else if detector.Detect(ctx, line) {
+		confidence = fullConfidence
+	}
+	if confidence <= 0 {
+		return 0, 0
+	}
+
+	score = confidence
+	format := detector.Format()
+	if format == lc.previous {
+		score += previousLineBonus
+	}
+	if format == lc.active {
+		score += activeEntryBonus
+	}
+	return confidence, score
+}
+
+// Rank scores every detector that matches the line, given the formats of the
+// lines the parser has seen, without advancing its state. Candidates are
+// ordered by score, ties going to the previous line's format and then to the
+// most specific detector, so the first is the format DetectEntry picks unless
+// the line continues a multi-line entry or the parser is forced or locked to
+// a format.
+func (p *Parser) Rank(line string) []Candidate {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rank(Unwrap(line), p.currentContext())
+}
+
+// rank implements Rank. The caller holds p.mu.
+func (p *Parser) rank(line string, lc lineContext) []Candidate {
+	ctx := context.Background()
+	var candidates []Candidate
+	for rank, detector := range p.detectors {
+		confidence, score := lc.score(ctx, detector, line)
+		if confidence <= 0 {
+			continue
+		}
+		candidates = append(candidates, Candidate{
+			Format:        detector.Format(),
+			Specificity:   detector.Specificity(),
+			PatternLength: detector.PatternLength(),
+			Rank:          rank,
+			Confidence:    confidence,
+			Score:         score,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Format == lc.previous && b.Format != lc.previous
+	})
+	return candidates
+}
@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// scoredDetector is a prefixDetector with a fixed confidence
+type scoredDetector struct {
+	prefixDetector
+	confidence float64
+}
+
+func (d scoredDetector) Confidence(ctx context.Context, line string) float64 {
+	if !d.Detect(ctx, line) {
+		return 0
+	}
+	return d.confidence
+}
+
+func TestConfidence(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want LogFormat
+	}{
+		{
+			name: "a certain match beats a weak one of higher specificity",
+			line: "2025/01/19 10:30:00 user=bob action=login status=ok",
+			want: GoStandardFormat,
+		},
+		{
+			name: "a weak match still wins alone",
+			line: "42",
+			want: JSONFormat,
+		},
+		{
+			name: "logfmt with a stray word",
+			line: "level=info msg=started extra",
+			want: LogfmtFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewParser().DetectFormat(tt.line); got != tt.want {
+				t.Errorf("DetectFormat(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectorConfidence(t *testing.T) {
+	tests := []struct {
+		name     string
+		detector ConfidenceScorer
+		line     string
+		want     float64
+	}{
+		{"JSON object", &JSONDetector{}, `{"level":"info"}`, 0.9},
+		{"logfmt lone pair", &LogfmtDetector{}, `PATH=/usr/local/bin`, 0.5},
+		{"logfmt rejects JSON", &LogfmtDetector{}, `{"url":"/?a=b"}`, 0},
+		{"syslog bare tag", &SyslogDetector{}, `Jan 19 10:30:00 web01 kernel: usb 1-1: new device`, 0.8},
+		{"syslog with pid", &SyslogDetector{}, `Jan 19 10:30:00 web01 sshd[812]: Accepted publickey`, 0.9},
+		{"syslog with priority and pid", &SyslogDetector{}, `<34>Jan 19 10:30:00 web01 sshd[812]: Accepted publickey`, 1},
+		{"RFC 5424 without timestamp", &RFC5424Detector{}, `<134>1 - web01 billing 4321 - [origin ip="10.0.0.5"] settled`, 0.9},
+		{"RFC 5424 with broken structured data", &RFC5424Detector{}, `<134>1 2025-01-19T10:30:00Z web01 billing 4321 - [origin ip=10.0.0.5] settled`, 0.9},
+		{"Go standard", &GoStandardDetector{}, `2025/01/19 10:30:00 server started`, 0.9},
+		{"Go standard with flags", &GoStandardDetector{}, `2025/01/19 10:30:00.123456 main.go:42: server started`, 1},
+		{"Rails without separator", &RailsDetector{}, `[2025-01-19 10:30:00] INFO  Started GET "/"`, 0.8},
+		{"Docker without level", &DockerDetector{}, `2025-01-19T10:30:00.123456789Z Starting server`, 0.75},
+		{"Docker with level", &DockerDetector{}, `2025-01-19T10:30:00.123456789Z WARN disk almost full`, 1},
+		{"Kubernetes non-Go file", &KubernetesDetector{}, `2025-01-19T10:30:00.123Z 1 server.py:42] started`, 0.8},
+		{"Heroku dyno", &HerokuDetector{}, `2025-01-19T10:30:00+00:00 app[web.1]: started`, 1},
+		{"Heroku odd dyno", &HerokuDetector{}, `2025-01-19T10:30:00+00:00 app[api]: started`, 0.8},
+		{"klog millisecond timestamp", &KlogDetector{}, `I0119 10:30:00.123       1 main.go:42] started`, 0.9},
+		{"go test FAIL message", &GoTestDetector{}, `FAIL to connect to database`, 0.5},
+		{"go test package result", &GoTestDetector{}, `FAIL github.com/joshi4/splash/parser 0.213s`, 1},
+		{"Java frame of a JavaScript file", &StatefulJavaExceptionDetector{}, `    at handler (/app/server.js:12:5)`, 0.75},
+		{"JavaScript frame", &StatefulJavaScriptExceptionDetector{}, `    at handler (/app/server.js:12:5)`, 1},
+		{"JavaScript frame of a Java file", &StatefulJavaScriptExceptionDetector{}, `	at com.example.Main.run(Main.java:42)`, 0.75},
+		{"JavaScript-only error", &StatefulJavaScriptExceptionDetector{}, `ReferenceError: x is not defined`, 1},
+		{"error both languages throw", &StatefulJavaScriptExceptionDetector{}, `TypeError: x is not a function`, 0.9},
+		{"Python exception line", &StatefulPythonExceptionDetector{}, `ValueError: invalid literal`, 0.75},
+		{"goroutine frame", &StatefulGoroutineStackTraceDetector{}, `main.main()`, 1},
+		{"prose matching the goroutine pattern", &StatefulGoroutineStackTraceDetector{}, `e.g. the docs`, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.detector.Confidence(context.Background(), tt.line); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Confidence(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviousLineBonus(t *testing.T) {
+	newParser := func() *Parser {
+		return NewParser(WithDetectors(
+			scoredDetector{prefixDetector{prefix: "[", format: GoStandardFormat, specificity: 50}, 0.8},
+			prefixDetector{prefix: "[rails]", format: RailsFormat, specificity: 40},
+		))
+	}
+	line := "[rails] Completed 200 OK"
+
+	if got := newParser().DetectFormat(line); got != RailsFormat {
+		t.Errorf("DetectFormat() on its own = %v, want %v", got, RailsFormat)
+	}
+
+	p := newParser()
+	p.DetectFormat("[go] started")
+	if got := p.DetectFormat(line); got != GoStandardFormat {
+		t.Errorf("DetectFormat() after a Go Standard line = %v, want %v", got, GoStandardFormat)
+	}
+}
+
+func TestRank(t *testing.T) {
+	p := NewParser()
+	p.DetectFormat("level=info msg=started")
+
+	line := "2025/01/19 10:30:00 user=bob action=login status=ok"
+	candidates := p.Rank(line)
+	if len(candidates) != 2 {
+		t.Fatalf("Rank() = %+v, want 2 candidates", candidates)
+	}
+	want := []Candidate{
+		{Format: GoStandardFormat, Confidence: 0.9, Score: 0.9},
+		{Format: LogfmtFormat, Confidence: 0.6, Score: 0.85},
+	}
+	for i, c := range candidates {
+		if c.Format != want[i].Format || c.Confidence != want[i].Confidence || c.Score != want[i].Score {
+			t.Errorf("Rank()[%d] = %+v, want %+v", i, c, want[i])
+		}
+	}
+
+	// Ranking doesn't advance the parser
+	p.Rank(`{"level":"info"}`)
+	if got := p.DetectFormat(line); got != candidates[0].Format {
+		t.Errorf("DetectFormat() = %v, want %v", got, candidates[0].Format)
+	}
+}
+
+func TestActiveEntryBonus(t *testing.T) {
+	lc := lineContext{previous: UnknownFormat, active: JavaExceptionFormat}
+	ctx := context.Background()
+	line := `Exception in thread "main" java.lang.IllegalStateException: boom`
+
+	if _, score := lc.score(ctx, &StatefulJavaExceptionDetector{}, line); score != fullConfidence+activeEntryBonus {
+		t.Errorf("score() of the active entry's format = %v, want %v", score, fullConfidence+activeEntryBonus)
+	}
+	if _, score := lc.score(ctx, &GoStandardDetector{}, line); score != 0 {
+		t.Errorf("score() of a detector rejecting the line = %v, want 0", score)
+	}
+}
+
+// timestampedFormat is the format of timestampedDetector
+var timestampedFormat = RegisterFormat("Test Timestamped")
+
+var timestampedRegex = regexp.MustCompile(`^\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}`)
+
+// timestampedDetector claims every line starting with a date and time, as a
+// catch-all detector that an embedding program might add. It is more
+// specific than the built-in detectors it overlaps and has a longer pattern,
+// so only their confidence keeps their lines from it.
+type timestampedDetector struct{}
+
+func (d timestampedDetector) Detect(ctx context.Context, line string) bool {
+	return d.Confidence(ctx, line) > 0
+}
+
+func (timestampedDetector) Confidence(_ context.Context, line string) float64 {
+	if timestampedRegex.MatchString(line) {
+		return 0.6
+	}
+	return 0
+}
+
+func (timestampedDetector) Format() LogFormat  { return timestampedFormat }
+func (timestampedDetector) Specificity() int   { return 90 }
+func (timestampedDetector) PatternLength() int { return 1000 }
+
+func TestOverlappingDetectorKeepsTestDataWinners(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "testdata", "*.log"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no testdata found: %v", err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			builtin := NewParser()
+			extended := NewParser(WithDetectors(append(builtin.Detectors(), timestampedDetector{})...))
+			scanner := NewLineScanner(file)
+			for n := 1; scanner.Scan(); n++ {
+				line := scanner.Text()
+				// Ties would go to the most specific detector, which a new
+				// one can outdo, so contested lines must be won on score
+				if candidates := builtin.Rank(line); len(candidates) > 1 && candidates[0].Score == candidates[1].Score {
+					t.Errorf("line %d: %v and %v tie on score %.2f: %s", n, candidates[0].Format, candidates[1].Format, candidates[0].Score, line)
+				}
+				want := builtin.DetectFormat(line)
+				// The new detector may take lines no built-in one matched
+				if got := extended.DetectFormat(line); want != UnknownFormat && got != want {
+					t.Errorf("line %d: DetectFormat() = %v with an overlapping detector, want %v: %s", n, got, want, line)
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one log record: a single line, or all the lines of a multi-line
+// record such as a Java exception, Python traceback or goroutine dump
+type Entry struct {
+	Format    LogFormat
+	StartLine int // 1-based number of the entry's first line in the input
+	EndLine   int // number of its last line
+	Lines     []string
+}
+
+// Text returns the lines of the entry joined by newlines
+func (e Entry) Text() string {
+	return strings.Join(e.Lines, "\n")
+}
+
+// EntryReader reads an input as a sequence of complete entries, using the
+// same multi-line tracking as Parser.DetectEntry. An entry is only known to
+// be complete once the line after it arrives, so on a live stream the last
+// entry would wait for the next line; a flush timeout bounds that wait.
+// Partial CRI lines are rejoined first, as by LineScanner, so StartLine and
+// EndLine count a rejoined line once.
+type EntryReader struct {
+	scanner      *LineScanner
+	parser       *Parser
+	flushTimeout time.Duration
+
+	next   *Entry // the line that starts the next entry, once read
+	lineNo int
+
+	// With a flush timeout, lines are read ahead in the background
+	lines     chan string
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEntryReader returns a reader of the entries in r, detected by a parser
+// created with opts
+func NewEntryReader(r io.Reader, opts ...Option) *EntryReader {
+	return &EntryReader{
+		scanner: NewLineScanner(r),
+		parser:  NewParser(opts...),
+		done:    make(chan struct{}),
+	}
+}
+
+// SetFlushTimeout makes Next return the entry read so far when no line
+// arrives for d, instead of waiting for the line that would end it. A line
+// that arrives after the flush starts a new entry even if it continues the
+// flushed one. Zero, the default, waits indefinitely. It must be called
+// before the first call to Next.
+func (r *EntryReader) SetFlushTimeout(d time.Duration) {
+	r.flushTimeout = d
+}
+
+// Next returns the next entry. At the end of the input it returns io.EOF,
+// or the error reading the input failed with.
+func (r *EntryReader) Next() (Entry, error) {
+	var entry Entry
+	if r.next != nil {
+		entry, r.next = *r.next, nil
+	}
+
+	for {
+		text, ok, timedOut := r.readLine(len(entry.Lines) > 0)
+		if timedOut {
+			return entry, nil
+		}
+		if !ok {
+			if len(entry.Lines) > 0 {
+				return entry, nil
+			}
+			// With a flush timeout, lines has been closed so the scanner is idle
+			if err := r.scanner.Err(); err != nil {
+				return Entry{}, err
+			}
+			return Entry{}, io.EOF
+		}
+
+		r.lineNo++
+		format, continued := r.parser.DetectEntry(text)
+		line := Entry{Format: format, StartLine: r.lineNo, EndLine: r.lineNo, Lines: []string{text}}
+		switch {
+		case len(entry.Lines) == 0:
+			entry = line
+		case continued:
+			entry.Lines = append(entry.Lines, text)
+			entry.EndLine = r.lineNo
+		default:
+			r.next = &line
+			return entry, nil
+		}
+	}
+}
+
+// Close stops reading ahead in the background. It leaves the reader passed
+// to NewEntryReader open, as that stays the caller's to close. A read ahead
+// blocked on a live input, such as a pipe, ends without delivering its line
+// once the read returns, for example when the caller closes the input.
+func (r *EntryReader) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+	return nil
+}
+
+// readLine returns the next line of the input. ok is false at the end of the
+// input. When canFlush is set and a flush timeout is configured, it gives up
+// after the timeout and reports timedOut.
+func (r *EntryReader) readLine(canFlush bool) (text string, ok, timedOut bool) {
+	if r.flushTimeout <= 0 {
+		if !r.scanner.Scan() {
+			return "", false, false
+		}
+		return r.scanner.Text(), true, false
+	}
+
+	if r.lines == nil {
+		r.lines = make(chan string)
+		go r.readAhead()
+	}
+
+	if !canFlush {
+		text, ok = <-r.lines
+		return text, ok, false
+	}
+
+	timer := time.NewTimer(r.flushTimeout)
+	defer timer.Stop()
+	select {
+	case text, ok = <-r.lines:
+		return text, ok, false
+	case <-timer.C:
+		return "", false, true
+	}
+}
+
+// readAhead feeds lines to readLine until the input ends or the reader is closed
+func (r *EntryReader) readAhead() {
+	defer close(r.lines)
+	for r.scanner.Scan() {
+		// A line read after Close is dropped, even if Next is still waiting
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+		select {
+		case r.lines <- r.scanner.Text():
+		case <-r.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntryReader(t *testing.T) {
+	input := strings.Join([]string{
+		`{"level":"INFO","message":"Starting"}`,
+		`Exception in thread "main" java.lang.IllegalStateException: boom`,
+		"\tat com.example.Main.run(Main.java:42)",
+		"Caused by: java.io.IOException: closed",
+		"\tat com.example.Main.read(Main.java:7)",
+		"Traceback (most recent call last):",
+		`  File "app.py", line 3, in <module>`,
+		"ValueError: bad value",
+		"2025/01/19 10:31:15 Services restored",
+	}, "\n")
+
+	want := []Entry{
+		{Format: JSONFormat, StartLine: 1, EndLine: 1, Lines: []string{`{"level":"INFO","message":"Starting"}`}},
+		{Format: JavaExceptionFormat, StartLine: 2, EndLine: 5, Lines: []string{
+			`Exception in thread "main" java.lang.IllegalStateException: boom`,
+			"\tat com.example.Main.run(Main.java:42)",
+			"Caused by: java.io.IOException: closed",
+			"\tat com.example.Main.read(Main.java:7)",
+		}},
+		{Format: PythonExceptionFormat, StartLine: 6, EndLine: 8, Lines: []string{
+			"Traceback (most recent call last):",
+			`  File "app.py", line 3, in <module>`,
+			"ValueError: bad value",
+		}},
+		{Format: GoStandardFormat, StartLine: 9, EndLine: 9, Lines: []string{"2025/01/19 10:31:15 Services restored"}},
+	}
+
+	for _, timeout := range []time.Duration{0, time.Minute} {
+		r := NewEntryReader(strings.NewReader(input))
+		r.SetFlushTimeout(timeout)
+
+		var got []Entry
+		for {
+			entry, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("timeout %v: Next() error = %v", timeout, err)
+			}
+			got = append(got, entry)
+		}
+		_ = r.Close()
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("timeout %v: entries = %+v, want %+v", timeout, got, want)
+		}
+	}
+}
+
+func TestEntryReaderFlushTimeout(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	r := NewEntryReader(pr)
+	r.SetFlushTimeout(20 * time.Millisecond)
+	defer r.Close()
+
+	go func() {
+		_, _ = io.WriteString(pw, "Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>\nValueError: bad value\n")
+	}()
+
+	// The traceback is flushed without waiting for another line
+	entry, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if entry.Format != PythonExceptionFormat || entry.StartLine != 1 || entry.EndLine != 3 {
+		t.Errorf("Next() = %+v, want the 3 line traceback", entry)
+	}
+
+	go func() {
+		_, _ = io.WriteString(pw, "2025/01/19 10:31:15 Services restored\n")
+		_ = pw.Close()
+	}()
+	entry, err = r.Next()
+	if err != nil || entry.StartLine != 4 || entry.Text() != "2025/01/19 10:31:15 Services restored" {
+		t.Errorf("Next() = %+v, %v; want line 4", entry, err)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() at end error = %v, want io.EOF", err)
+	}
+}
+
+func TestEntryReaderCloseStopsReadAhead(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	r := NewEntryReader(pr)
+	r.SetFlushTimeout(20 * time.Millisecond)
+
+	go func() {
+		_, _ = io.WriteString(pw, "2025/01/19 10:31:15 Services restored\n")
+	}()
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+
+	// The read ahead is now blocked reading the pipe for a line that never comes
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// The pipe is still the caller's: the blocked read gets the next line,
+	// and the read ahead drops it and exits
+	written := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(pw, "2025/01/19 10:31:16 Late line\n")
+		written <- err
+	}()
+	select {
+	case _, ok := <-r.lines:
+		if ok {
+			t.Error("read ahead returned a line after Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("read ahead still running a second after its read returned")
+	}
+	if err := <-written; err != nil {
+		t.Errorf("writing to the input after Close failed: %v", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk on fire")
+}
+
+func TestEntryReaderError(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Minute} {
+		r := NewEntryReader(failingReader{})
+		r.SetFlushTimeout(timeout)
+		if _, err := r.Next(); err == nil || err.Error() != "disk on fire" {
+			t.Errorf("timeout %v: Next() error = %v, want disk on fire", timeout, err)
+		}
+		_ = r.Close()
+	}
+}
@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Role names the part a span of a line plays, such as its timestamp or
+// level, so the colorizer can pick a theme style for it
+type Role string
+
+const (
+	RoleTimestamp   Role = "timestamp"
+	RoleLevel       Role = "level"
+	RoleMessage     Role = "message"
+	RoleHostname    Role = "hostname"
+	RoleService     Role = "service"
+	RolePID         Role = "pid"
+	RoleIP          Role = "ip"
+	RoleMethod      Role = "method"
+	RoleURL         Role = "url"
+	RoleStatus      Role = "status"
+	RoleFilename    Role = "filename"
+	RoleLineNum     Role = "linenum"
+	RoleKey         Role = "key"
+	RoleValue       Role = "value"
+	RoleString      Role = "string"
+	RoleNumber      Role = "number"
+	RolePunctuation Role = "punctuation"
+)
+
+// roles lists every Role, in the order they are documented
+var roles = []Role{
+	RoleTimestamp, RoleLevel, RoleMessage, RoleHostname, RoleService, RolePID, RoleIP, RoleMethod,
+	RoleURL, RoleStatus, RoleFilename, RoleLineNum, RoleKey, RoleValue, RoleString, RoleNumber, RolePunctuation,
+}
+
+func isRole(role Role) bool {
+	return slices.Contains(roles, role)
+}
+
+// roleList names every role for error messages
+func roleList() string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Segment is a span of a line along with the role it plays
+type Segment struct {
+	Span Span
+	Role Role
+}
+
+// ColorizeFunc splits a line of a registered format into the segments the
+// colorizer styles. Text outside every segment is shown unstyled.
+type ColorizeFunc func(line string) []Segment
+
+// registry holds the formats and detectors added with RegisterFormat and Register
+var registry struct {
+	mu        sync.RWMutex
+	names     []string // indexed by format - numBuiltinFormats
+	detectors []FormatDetector
+	colorize  map[LogFormat]ColorizeFunc
+	layouts   map[LogFormat]layout
+}
+
+// layout splits the lines of a format defined in a config file into fields
+type layout struct {
+	pattern *regexp.Regexp
+	roles   map[string]Role // capture group name -> role
+}
+
+// RegisterFormat allocates a new LogFormat with the given name, for a
+// detector to return from Format. Names must be unique, as compared by
+// FormatByName.
+func RegisterFormat(name string) LogFormat {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, taken := lookupFormat(formatKey(name)); taken {
+		panic(fmt.Sprintf("parser: format name %q already in use", name))
+	}
+	registry.names = append(registry.names, name)
+	return numBuiltinFormats + LogFormat(len(registry.names)-1)
+}
+
+// Register adds a detector to every Parser created afterwards, ranked among
+// the built-in detectors by its Specificity and PatternLength. Its format
+// must come from RegisterFormat. colorize renders the format's lines and may
+// be nil, in which case they are colorized like unknown lines.
+func Register(detector FormatDetector, colorize ColorizeFunc) {
+	format := detector.Format()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if format < numBuiltinFormats || int(format-numBuiltinFormats) >= len(registry.names) {
+		panic(fmt.Sprintf("parser: Register called with format %d, which wasn't allocated by RegisterFormat", format))
+	}
+	registry.detectors = append(registry.detectors, detector)
+	if colorize != nil {
+		if registry.colorize == nil {
+			registry.colorize = make(map[LogFormat]ColorizeFunc)
+		}
+		registry.colorize[format] = colorize
+	}
+}
+
+// FormatColorizer returns the ColorizeFunc registered for a format, or nil
+func FormatColorizer(format LogFormat) ColorizeFunc {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.colorize[format]
+}
+
+// registerLayout records how ParseAs splits a registered format's lines into fields
+func registerLayout(format LogFormat, pattern *regexp.Regexp, roles map[string]Role) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.layouts == nil {
+		registry.layouts = make(map[LogFormat]layout)
+	}
+	registry.layouts[format] = layout{pattern: pattern, roles: roles}
+}
+
+// registeredLayout returns the layout registered for a format
+func registeredLayout(format LogFormat) (layout, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	l, ok := registry.layouts[format]
+	return l, ok
+}
+
+// FormatByName looks up a built-in or registered format by its name,
+// regardless of case, spaces, hyphens and underscores, so "Go Standard",
+// "go-standard" and "gostandard" all name the same format
+func FormatByName(name string) (LogFormat, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return lookupFormat(formatKey(name))
+}
+
+// lookupFormat finds a format by its formatKey. The caller holds registry.mu.
+func lookupFormat(key string) (LogFormat, bool) {
+	for format := UnknownFormat + 1; format < numBuiltinFormats; format++ {
+		if formatKey(format.String()) == key {
+			return format, true
+		}
+	}
+	for i, registered := range registry.names {
+		if formatKey(registered) == key {
+			return numBuiltinFormats + LogFormat(i), true
+		}
+	}
+	return UnknownFormat, false
+}
+
+// formatKey normalizes a format name for lookups
+func formatKey(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(name))
+}
+
+// registeredFormatName returns the name a format was registered under
+func registeredFormatName(format LogFormat) (string, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	i := int(format - numBuiltinFormats)
+	if format < numBuiltinFormats || i >= len(registry.names) {
+		return "", false
+	}
+	return registry.names[i], true
+}
+
+// registeredDetectors returns the detectors added with Register
+func registeredDetectors() []FormatDetector {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return append([]FormatDetector(nil), registry.detectors...)
+}
+
+// Option configures a Parser created by NewParser
+type Option func(*parserOptions)
+
+type parserOptions struct {
+	detectors  []FormatDetector
+	without    map[LogFormat]bool
+	format     LogFormat
+	forced     bool
+	lockAfter  int
+	lockMisses int
+}
+
+// WithDetectors makes the parser use only the given detectors instead of the
+// built-in and registered ones. They are ranked as usual, with their order
+// breaking ties between detectors of equal specificity and pattern length.
+func WithDetectors(detectors ...FormatDetector) Option {
+	return func(o *parserOptions) {
+		o.detectors = detectors
+	}
+}
+
+// WithoutFormats leaves out the detectors of the given formats
+func WithoutFormats(formats ...LogFormat) Option {
+	return func(o *parserOptions) {
+		if o.without == nil {
+			o.without = make(map[LogFormat]bool)
+		}
+		for _, format := range formats {
+			o.without[format] = true
+		}
+	}
+}
+
+// WithFormat makes the parser report every line as the given format, skipping
+// detection. The format's detector, when it has one, still groups the lines
+// of multi-line entries.
+func WithFormat(format LogFormat) Option {
+	return func(o *parserOptions) {
+		o.format = format
+		o.forced = true
+	}
+}
+
+// WithAutoLock pins the parser to a format once after consecutive lines are
+// detected as it. While pinned, lines are reported as the pinned format
+// without checking other detectors, except for lines that start a multi-line
+// entry such as a stack trace. The lock is released, and detection resumes,
+// after the pinned detector rejects misses consecutive lines; a misses below
+// 1 counts as 1. Multi-line formats are never pinned. An after of zero, the
+// default, disables locking.
+func WithAutoLock(after, misses int) Option {
+	return func(o *parserOptions) {
+		o.lockAfter = after
+		o.lockMisses = max(misses, 1)
+	}
+}
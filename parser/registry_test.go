@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// auditFormat is a format registered the way an embedding program would
+var auditFormat = RegisterFormat("Test Audit")
+
+// auditDetector matches lines like "AUDIT|alice|login"
+type auditDetector struct{}
+
+func (auditDetector) Detect(_ context.Context, line string) bool {
+	return strings.HasPrefix(line, "AUDIT|")
+}
+func (auditDetector) Format() LogFormat  { return auditFormat }
+func (auditDetector) Specificity() int   { return 90 }
+func (auditDetector) PatternLength() int { return 0 }
+
+func auditSegments(line string) []Segment {
+	user, _, _ := strings.Cut(strings.TrimPrefix(line, "AUDIT|"), "|")
+	return []Segment{{Span: Span{6, 6 + len(user)}, Role: RoleService}}
+}
+
+func init() {
+	Register(auditDetector{}, auditSegments)
+}
+
+func TestRegisteredFormat(t *testing.T) {
+	if auditFormat < numBuiltinFormats {
+		t.Fatalf("RegisterFormat() = %d, want a format after the built-in ones", auditFormat)
+	}
+	if got := auditFormat.String(); got != "Test Audit" {
+		t.Errorf("String() = %q, want %q", got, "Test Audit")
+	}
+	if other := RegisterFormat("Test Other"); other == auditFormat {
+		t.Errorf("RegisterFormat() reused format %d", other)
+	}
+	if FormatColorizer(auditFormat) == nil {
+		t.Error("FormatColorizer() = nil for a registered format")
+	}
+	if FormatColorizer(JSONFormat) != nil {
+		t.Error("FormatColorizer() != nil for a built-in format")
+	}
+
+	if got := NewParser().DetectFormat("AUDIT|alice|login"); got != auditFormat {
+		t.Errorf("DetectFormat() = %v, want %v", got, auditFormat)
+	}
+}
+
+func TestRegisterRejectsUnallocatedFormat(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() with a built-in format didn't panic")
+		}
+	}()
+	Register(&JSONDetector{}, nil)
+}
+
+func TestParserOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		line string
+		want LogFormat
+	}{
+		{
+			name: "without a format",
+			opts: []Option{WithoutFormats(JSONFormat)},
+			line: `{"level":"info"}`,
+			want: UnknownFormat,
+		},
+		{
+			name: "without a registered format",
+			opts: []Option{WithoutFormats(auditFormat)},
+			line: "AUDIT|alice|login",
+			want: UnknownFormat,
+		},
+		{
+			name: "only the given detectors",
+			opts: []Option{WithDetectors(&SyslogDetector{})},
+			line: `{"level":"info"}`,
+			want: UnknownFormat,
+		},
+		{
+			name: "given detectors still detect their formats",
+			opts: []Option{WithDetectors(&SyslogDetector{})},
+			line: "Jan 19 10:30:00 web01 myapp[1234]: started",
+			want: SyslogFormat,
+		},
+		{
+			name: "ranking outweighs list order",
+			opts: []Option{WithDetectors(&ApacheCommonDetector{}, &NginxDetector{})},
+			line: `127.0.0.1 - - [19/Jan/2025:10:30:00 +0000] "GET / HTTP/1.1" 200 1 "-" "curl"`,
+			want: NginxFormat,
+		},
+		{
+			name: "forced format",
+			opts: []Option{WithFormat(NginxFormat)},
+			line: `{"level":"info"}`,
+			want: NginxFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewParser(tt.opts...).DetectFormat(tt.line); got != tt.want {
+				t.Errorf("DetectFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinSamplesDetected(t *testing.T) {
+	for _, detector := range builtinDetectors() {
+		describer, ok := detector.(FormatDescriber)
+		if !ok {
+			t.Errorf("%v detector doesn't describe itself", detector.Format())
+			continue
+		}
+		if describer.Pattern() == "" {
+			t.Errorf("%v detector has no pattern", detector.Format())
+		}
+		if got := NewParser().DetectFormat(describer.Sample()); got != detector.Format() {
+			t.Errorf("sample %q detected as %v, want %v", describer.Sample(), got, detector.Format())
+		}
+	}
+}
+
+func TestFormatByName(t *testing.T) {
+	tests := []struct {
+		name string
+		want LogFormat
+		ok   bool
+	}{
+		{"nginx", NginxFormat, true},
+		{"Go Standard", GoStandardFormat, true},
+		{"go-standard", GoStandardFormat, true},
+		{"goroutine_stack_trace", GoroutineStackTraceFormat, true},
+		{"test audit", auditFormat, true},
+		{"Unknown", UnknownFormat, false},
+		{"cobol", UnknownFormat, false},
+	}
+	for _, tt := range tests {
+		if got, ok := FormatByName(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("FormatByName(%q) = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBuiltinFormats(t *testing.T) {
+	detected := make(map[LogFormat]bool)
+	for _, detector := range builtinDetectors() {
+		detected[detector.Format()] = true
+	}
+
+	formats := BuiltinFormats()
+	for _, format := range formats {
+		if !detected[format] {
+			t.Errorf("no built-in detector for %v", format)
+		}
+		if got, ok := FormatByName(format.String()); !ok || got != format {
+			t.Errorf("FormatByName(%q) = %v, %v; want %v", format.String(), got, ok, format)
+		}
+	}
+	if len(formats) != len(detected) {
+		t.Errorf("BuiltinFormats() = %v, want the %d formats of the built-in detectors", formats, len(detected))
+	}
+}
+
+func TestForcedFormatGroupsEntries(t *testing.T) {
+	p := NewParser(WithFormat(PythonExceptionFormat))
+	lines := []string{
+		"Traceback (most recent call last):",
+		`  File "app.py", line 3, in <module>`,
+		"ValueError: bad value",
+		"2025/01/19 10:31:15 Services restored",
+	}
+	wantContinued := []bool{false, true, true, false}
+	for i, line := range lines {
+		format, continued := p.DetectEntry(line)
+		if format != PythonExceptionFormat || continued != wantContinued[i] {
+			t.Errorf("DetectEntry(%q) = %v, %v, want %v, %v", line, format, continued, PythonExceptionFormat, wantContinued[i])
+		}
+	}
+}
+
+func TestAutoLock(t *testing.T) {
+	p := NewParser(WithAutoLock(3, 2))
+	steps := []struct {
+		line     string
+		want     LogFormat
+		decision Decision
+	}{
+		{"level=info msg=a", LogfmtFormat, DecisionRanked},
+		{"level=info msg=b", LogfmtFormat, DecisionPrevious},
+		{"level=info msg=c", LogfmtFormat, DecisionPrevious},
+		// Locked: a line of another format is kept as the pinned one
+		{"2025/01/19 10:30:00 connected", LogfmtFormat, DecisionLocked},
+		{"level=info msg=d", LogfmtFormat, DecisionLocked},
+		// Stack traces still start while locked
+		{"Traceback (most recent call last):", PythonExceptionFormat, DecisionRanked},
+		{`  File "app.py", line 3, in <module>`, PythonExceptionFormat, DecisionContinued},
+		{"ValueError: bad value", PythonExceptionFormat, DecisionJoined},
+		{"plain text", LogfmtFormat, DecisionLocked},
+		// The second miss in a row releases the lock
+		{"2025/01/19 10:30:01 reconnected", GoStandardFormat, DecisionRanked},
+		{"2025/01/19 10:30:02 ready", GoStandardFormat, DecisionPrevious},
+	}
+	for _, step := range steps {
+		e := p.Explain(step.line)
+		if e.Format != step.want || e.Decision != step.decision {
+			t.Errorf("Explain(%q) = %v by %q, want %v by %q", step.line, e.Format, e.Decision, step.want, step.decision)
+		}
+	}
+}
+
+func TestAutoLockSkipsMultiLineFormats(t *testing.T) {
+	p := NewParser(WithAutoLock(1, 1))
+	p.DetectFormat("Traceback (most recent call last):")
+	if e := p.Explain("plain text"); e.Locked != UnknownFormat {
+		t.Errorf("Locked = %v after a traceback, want %v", e.Locked, UnknownFormat)
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"context"
 	"regexp"
+	"strings"
 )
 
 // StatefulDetector defines the interface for multi-line log format detectors
@@ -18,6 +19,19 @@ type StatefulDetector interface {
 	DetectEnd(ctx context.Context, line string) bool
 }
 
+// EntryJoiner is an optional interface for stateful detectors whose entries
+// contain lines that are neither continuations nor new entries, such as the
+// "Caused by:" line of a Java exception. JoinsEntry is consulted while the
+// detector is active and reports whether the line belongs to the current entry.
+type EntryJoiner interface {
+	JoinsEntry(line string) bool
+}
+
+// isIndented reports whether the line starts with a space or tab
+func isIndented(line string) bool {
+	return len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+}
+
 // StatefulRsyslogDetector handles multi-line rsyslog entries
 type StatefulRsyslogDetector struct{}
 
@@ -25,23 +39,13 @@ const rsyslogStartPattern = `^\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2}\s+\S+\s+(?:rsy
 
 var rsyslogStartRegex = regexp.MustCompile(rsyslogStartPattern)
 
-func (d *StatefulRsyslogDetector) DetectStart(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- rsyslogStartRegex.MatchString(line)
-	}()
-
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
-	}
+func (d *StatefulRsyslogDetector) DetectStart(_ context.Context, line string) bool {
+	return rsyslogStartRegex.MatchString(line)
 }
 
 func (d *StatefulRsyslogDetector) DetectContinuation(_ context.Context, line string) bool {
 	// Continuation lines have leading whitespace
-	return len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+	return isIndented(line)
 }
 
 func (d *StatefulRsyslogDetector) DetectEnd(_ context.Context, _ string) bool {
@@ -55,6 +59,14 @@ func (d *StatefulRsyslogDetector) Detect(ctx context.Context, line string) bool
 	return d.DetectStart(ctx, line)
 }
 
+func (d *StatefulRsyslogDetector) FirstBytes() string {
+	return wordBytes
+}
+
+func (d *StatefulRsyslogDetector) MayMatch(line string) bool {
+	return strings.Contains(line, "syslogd[")
+}
+
 func (d *StatefulRsyslogDetector) Format() LogFormat {
 	return RsyslogFormat
 }
@@ -67,6 +79,14 @@ func (d *StatefulRsyslogDetector) PatternLength() int {
 	return len(rsyslogStartPattern)
 }
 
+func (d *StatefulRsyslogDetector) Pattern() string {
+	return rsyslogStartPattern
+}
+
+func (d *StatefulRsyslogDetector) Sample() string {
+	return `Aug  8 00:15:23 your-macbook-pro syslogd[347]: ASL Sender Statistics`
+}
+
 // StatefulJavaExceptionDetector handles multi-line Java exception traces
 type StatefulJavaExceptionDetector struct{}
 
@@ -76,23 +96,16 @@ const javaStackTraceLinePattern = `^\s+(at\s+|\.\.\.|\d+\s+more)`
 var javaExceptionStartRegex = regexp.MustCompile(javaExceptionStartPattern)
 var javaStackTraceLineRegex = regexp.MustCompile(javaStackTraceLinePattern)
 
-func (d *StatefulJavaExceptionDetector) DetectStart(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- javaExceptionStartRegex.MatchString(line)
-	}()
-
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
-	}
+// javaFrameSourceRegex matches the source a JVM stack frame names in parentheses
+var javaFrameSourceRegex = regexp.MustCompile(`\((?:[^()\s:]+\.(?:java|kt|scala|groovy):\d+|Native Method|Unknown Source)\)`)
+
+func (d *StatefulJavaExceptionDetector) DetectStart(_ context.Context, line string) bool {
+	return javaExceptionStartRegex.MatchString(line)
 }
 
 func (d *StatefulJavaExceptionDetector) DetectContinuation(_ context.Context, line string) bool {
 	// Java stack trace lines start with whitespace
-	return len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+	return isIndented(line)
 }
 
 func (d *StatefulJavaExceptionDetector) DetectEnd(_ context.Context, _ string) bool {
@@ -101,23 +114,34 @@ func (d *StatefulJavaExceptionDetector) DetectEnd(_ context.Context, _ string) b
 	return false
 }
 
-func (d *StatefulJavaExceptionDetector) Detect(ctx context.Context, line string) bool {
+func (d *StatefulJavaExceptionDetector) Detect(_ context.Context, line string) bool {
 	// Match exception headers OR stack trace lines for backward compatibility
-	done := make(chan bool, 1)
-	go func() {
-		isStart := javaExceptionStartRegex.MatchString(line)
-		isStackTrace := javaStackTraceLineRegex.MatchString(line)
-		done <- isStart || isStackTrace
-	}()
-
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+	return javaExceptionStartRegex.MatchString(line) || javaStackTraceLineRegex.MatchString(line)
+}
+
+// Confidence is full for exception headers and "... 12 more" lines. Frames
+// start with "at" in JavaScript too, so they need a JVM source to be certain.
+func (d *StatefulJavaExceptionDetector) Confidence(_ context.Context, line string) float64 {
+	switch {
+	case javaExceptionStartRegex.MatchString(line):
+		return fullConfidence
+	case javaStackTraceLineRegex.MatchString(line):
+		frame := strings.HasPrefix(strings.TrimSpace(line), "at")
+		return evidenceConfidence(0.75, !frame || javaFrameSourceRegex.MatchString(line))
+	default:
+		return 0
 	}
 }
 
+func (d *StatefulJavaExceptionDetector) JoinsEntry(line string) bool {
+	// A chained cause continues the same exception
+	return strings.HasPrefix(line, "Caused by:")
+}
+
+func (d *StatefulJavaExceptionDetector) FirstBytes() string {
+	return "EC" + whitespaceBytes
+}
+
 func (d *StatefulJavaExceptionDetector) Format() LogFormat {
 	return JavaExceptionFormat
 }
@@ -130,6 +154,14 @@ func (d *StatefulJavaExceptionDetector) PatternLength() int {
 	return len(javaExceptionStartPattern) + len(javaStackTraceLinePattern)
 }
 
+func (d *StatefulJavaExceptionDetector) Pattern() string {
+	return javaExceptionStartPattern + "\n" + javaStackTraceLinePattern
+}
+
+func (d *StatefulJavaExceptionDetector) Sample() string {
+	return `Exception in thread "main" java.lang.ArithmeticException: / by zero`
+}
+
 // StatefulPythonExceptionDetector handles multi-line Python exception traces
 type StatefulPythonExceptionDetector struct{}
 
@@ -139,25 +171,13 @@ const pythonExceptionLinePattern = `^[A-Za-z][A-Za-z0-9]*Error:`
 var pythonExceptionStartRegex = regexp.MustCompile(pythonExceptionStartPattern)
 var pythonExceptionLineRegex = regexp.MustCompile(pythonExceptionLinePattern)
 
-func (d *StatefulPythonExceptionDetector) DetectStart(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		isTracebackStart := pythonExceptionStartRegex.MatchString(line)
-		isExceptionLine := pythonExceptionLineRegex.MatchString(line)
-		done <- isTracebackStart || isExceptionLine
-	}()
-
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
-	}
+func (d *StatefulPythonExceptionDetector) DetectStart(_ context.Context, line string) bool {
+	return pythonExceptionStartRegex.MatchString(line) || pythonExceptionLineRegex.MatchString(line)
 }
 
 func (d *StatefulPythonExceptionDetector) DetectContinuation(_ context.Context, line string) bool {
 	// Python traceback lines start with whitespace
-	return len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+	return isIndented(line)
 }
 
 func (d *StatefulPythonExceptionDetector) DetectEnd(_ context.Context, _ string) bool {
@@ -168,21 +188,36 @@ func (d *StatefulPythonExceptionDetector) DetectEnd(_ context.Context, _ string)
 
 func (d *StatefulPythonExceptionDetector) Detect(ctx context.Context, line string) bool {
 	// Match traceback headers OR exception lines for backward compatibility
-	done := make(chan bool, 1)
-	go func() {
-		isStart := pythonExceptionStartRegex.MatchString(line)
-		isException := pythonExceptionLineRegex.MatchString(line)
-		done <- isStart || isException
-	}()
-
-	select {
-	case result := <-done:
-		return result
-	case <-ctx.Done():
-		return false
+	return d.DetectStart(ctx, line)
+}
+
+// Confidence is full for a traceback header and lower for an exception line
+// on its own, which closes a traceback rather than starting one and names
+// errors the way JavaScript does
+func (d *StatefulPythonExceptionDetector) Confidence(_ context.Context, line string) float64 {
+	switch {
+	case pythonExceptionStartRegex.MatchString(line):
+		return fullConfidence
+	case pythonExceptionLineRegex.MatchString(line):
+		return 0.75
+	default:
+		return 0
 	}
 }
 
+func (d *StatefulPythonExceptionDetector) JoinsEntry(line string) bool {
+	// The exception line closes the traceback it follows
+	return pythonExceptionLineRegex.MatchString(line)
+}
+
+func (d *StatefulPythonExceptionDetector) FirstBytes() string {
+	return upperBytes + lowerBytes
+}
+
+func (d *StatefulPythonExceptionDetector) MayMatch(line string) bool {
+	return strings.Contains(line, "Error:") || strings.HasPrefix(line, "Traceback ")
+}
+
 func (d *StatefulPythonExceptionDetector) Format() LogFormat {
 	return PythonExceptionFormat
 }
@@ -195,6 +230,14 @@ func (d *StatefulPythonExceptionDetector) PatternLength() int {
 	return len(pythonExceptionStartPattern) + len(pythonExceptionLinePattern)
 }
 
+func (d *StatefulPythonExceptionDetector) Pattern() string {
+	return pythonExceptionStartPattern + "\n" + pythonExceptionLinePattern
+}
+
+func (d *StatefulPythonExceptionDetector) Sample() string {
+	return `Traceback (most recent call last):`
+}
+
 // StatefulGoroutineStackTraceDetector handles multi-line Go goroutine stack traces
 type StatefulGoroutineStackTraceDetector struct{}
 
@@ -204,28 +247,17 @@ const goroutineStackTraceLinePattern = `^(\s+[a-zA-Z_][a-zA-Z0-9_]*\.|[a-zA-Z_][
 var goroutineStartRegex = regexp.MustCompile(goroutineStartPattern)
 var goroutineStackTraceLineRegex = regexp.MustCompile(goroutineStackTraceLinePattern)
 
-func (d *StatefulGoroutineStackTraceDetector) DetectStart(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- goroutineStartRegex.MatchString(line)
-	}()
-
-	if ctx != nil {
-		select {
-		case result := <-done:
-			return result
-		case <-ctx.Done():
-			return false
-		}
-	} else {
-		// Handle nil context case
-		return <-done
-	}
+// goroutineFrameRegex matches what a stack line shows of a frame: the .go
+// file and line, or the call's arguments
+var goroutineFrameRegex = regexp.MustCompile(`\.go:\d+|\(.*\)$`)
+
+func (d *StatefulGoroutineStackTraceDetector) DetectStart(_ context.Context, line string) bool {
+	return goroutineStartRegex.MatchString(line)
 }
 
 func (d *StatefulGoroutineStackTraceDetector) DetectContinuation(_ context.Context, line string) bool {
 	// Goroutine stack trace lines start with whitespace
-	return len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+	return isIndented(line)
 }
 
 func (d *StatefulGoroutineStackTraceDetector) DetectEnd(_ context.Context, _ string) bool {
@@ -234,28 +266,38 @@ func (d *StatefulGoroutineStackTraceDetector) DetectEnd(_ context.Context, _ str
 	return false
 }
 
-func (d *StatefulGoroutineStackTraceDetector) Detect(ctx context.Context, line string) bool {
+func (d *StatefulGoroutineStackTraceDetector) Detect(_ context.Context, line string) bool {
 	// Match goroutine headers OR stack trace lines for backward compatibility
-	done := make(chan bool, 1)
-	go func() {
-		isStart := goroutineStartRegex.MatchString(line)
-		isStackTrace := goroutineStackTraceLineRegex.MatchString(line)
-		done <- isStart || isStackTrace
-	}()
-
-	if ctx != nil {
-		select {
-		case result := <-done:
-			return result
-		case <-ctx.Done():
-			return false
-		}
-	} else {
-		// Handle nil context case
-		return <-done
+	return goroutineStartRegex.MatchString(line) || goroutineStackTraceLineRegex.MatchString(line)
+}
+
+// Confidence is full for a goroutine header and for stack lines showing a
+// .go file or a call. The stack line pattern alone is loose enough to match
+// prose such as "see e.g. the docs", so such lines score low.
+func (d *StatefulGoroutineStackTraceDetector) Confidence(_ context.Context, line string) float64 {
+	switch {
+	case goroutineStartRegex.MatchString(line):
+		return fullConfidence
+	case goroutineStackTraceLineRegex.MatchString(line):
+		return evidenceConfidence(0.5, goroutineFrameRegex.MatchString(line))
+	default:
+		return 0
 	}
 }
 
+func (d *StatefulGoroutineStackTraceDetector) JoinsEntry(line string) bool {
+	// Function lines of a goroutine's stack aren't indented, only their file lines are
+	return goroutineStackTraceLineRegex.MatchString(line)
+}
+
+func (d *StatefulGoroutineStackTraceDetector) FirstBytes() string {
+	return upperBytes + lowerBytes + "_" + whitespaceBytes
+}
+
+func (d *StatefulGoroutineStackTraceDetector) MayMatch(line string) bool {
+	return strings.HasPrefix(line, "goroutine ") || strings.ContainsAny(line, "./")
+}
+
 func (d *StatefulGoroutineStackTraceDetector) Format() LogFormat {
 	return GoroutineStackTraceFormat
 }
@@ -268,6 +310,14 @@ func (d *StatefulGoroutineStackTraceDetector) PatternLength() int {
 	return len(goroutineStartPattern) + len(goroutineStackTraceLinePattern)
 }
 
+func (d *StatefulGoroutineStackTraceDetector) Pattern() string {
+	return goroutineStartPattern + "\n" + goroutineStackTraceLinePattern
+}
+
+func (d *StatefulGoroutineStackTraceDetector) Sample() string {
+	return `goroutine 1 [running]:`
+}
+
 // StatefulJavaScriptExceptionDetector handles multi-line JavaScript exception traces
 type StatefulJavaScriptExceptionDetector struct{}
 
@@ -277,23 +327,15 @@ const jsStackTraceLinePattern = `^\s+at\s+`
 var jsExceptionStartRegex = regexp.MustCompile(jsExceptionStartPattern)
 var jsStackTraceLineRegex = regexp.MustCompile(jsStackTraceLinePattern)
 
-func (d *StatefulJavaScriptExceptionDetector) DetectStart(ctx context.Context, line string) bool {
-	done := make(chan bool, 1)
-	go func() {
-		done <- jsExceptionStartRegex.MatchString(line)
-	}()
-
-	if ctx != nil {
-		select {
-		case result := <-done:
-			return result
-		case <-ctx.Done():
-			return false
-		}
-	} else {
-		// Handle nil context case
-		return <-done
-	}
+// jsSharedExceptionRegex matches the exception headers that Python and JVM
+// languages write as well
+var jsSharedExceptionRegex = regexp.MustCompile(`^(?:TypeError|SyntaxError|[A-Z][a-zA-Z]*Exception):`)
+
+// jsFrameLocationRegex matches the line:column a JavaScript stack frame ends with
+var jsFrameLocationRegex = regexp.MustCompile(`:\d+:\d+\)?$`)
+
+func (d *StatefulJavaScriptExceptionDetector) DetectStart(_ context.Context, line string) bool {
+	return jsExceptionStartRegex.MatchString(line)
 }
 
 func (d *StatefulJavaScriptExceptionDetector) DetectContinuation(_ context.Context, line string) bool {
@@ -307,28 +349,31 @@ func (d *StatefulJavaScriptExceptionDetector) DetectEnd(_ context.Context, _ str
 	return false
 }
 
-func (d *StatefulJavaScriptExceptionDetector) Detect(ctx context.Context, line string) bool {
+func (d *StatefulJavaScriptExceptionDetector) Detect(_ context.Context, line string) bool {
 	// Match exception headers OR stack trace lines for backward compatibility
-	done := make(chan bool, 1)
-	go func() {
-		isStart := jsExceptionStartRegex.MatchString(line)
-		isStackTrace := jsStackTraceLineRegex.MatchString(line)
-		done <- isStart || isStackTrace
-	}()
-
-	if ctx != nil {
-		select {
-		case result := <-done:
-			return result
-		case <-ctx.Done():
-			return false
-		}
-	} else {
-		// Handle nil context case
-		return <-done
+	return jsExceptionStartRegex.MatchString(line) || jsStackTraceLineRegex.MatchString(line)
+}
+
+// Confidence is full for the errors only JavaScript throws, and lower for
+// those other languages name the same way. Frames start with "at" in Java
+// too, so they need a line:column location to be certain.
+func (d *StatefulJavaScriptExceptionDetector) Confidence(_ context.Context, line string) float64 {
+	switch {
+	case jsStackTraceLineRegex.MatchString(line):
+		return evidenceConfidence(0.75, jsFrameLocationRegex.MatchString(line))
+	case jsSharedExceptionRegex.MatchString(line):
+		return 0.9
+	case jsExceptionStartRegex.MatchString(line):
+		return fullConfidence
+	default:
+		return 0
 	}
 }
 
+func (d *StatefulJavaScriptExceptionDetector) FirstBytes() string {
+	return upperBytes + whitespaceBytes
+}
+
 func (d *StatefulJavaScriptExceptionDetector) Format() LogFormat {
 	return JavaScriptExceptionFormat
 }
@@ -340,3 +385,11 @@ func (d *StatefulJavaScriptExceptionDetector) Specificity() int {
 func (d *StatefulJavaScriptExceptionDetector) PatternLength() int {
 	return len(jsExceptionStartPattern) + len(jsStackTraceLinePattern)
 }
+
+func (d *StatefulJavaScriptExceptionDetector) Pattern() string {
+	return jsExceptionStartPattern + "\n" + jsStackTraceLinePattern
+}
+
+func (d *StatefulJavaScriptExceptionDetector) Sample() string {
+	return `TypeError: Cannot read properties of undefined (reading 'id')`
+}
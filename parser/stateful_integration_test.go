@@ -215,3 +215,36 @@ func TestStatefulParserEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// TestDetectEntryGroupsMultiLineEntries checks that every line of a stack trace,
+// including chained causes and trailing exception lines, continues its entry
+func TestDetectEntryGroupsMultiLineEntries(t *testing.T) {
+	lines := []struct {
+		line      string
+		continued bool
+	}{
+		{`{"level":"INFO","message":"Application starting"}`, false},
+		{`Exception in thread "main" java.lang.RuntimeException: Database connection failed`, false},
+		{"\tat com.example.service.DatabaseService.connect(DatabaseService.java:45)", true},
+		{"Caused by: java.sql.SQLException: Connection timeout after 30 seconds", true},
+		{"\tat com.mysql.cj.jdbc.ConnectionImpl.createNewIO(ConnectionImpl.java:836)", true},
+		{"\t... 15 more", true},
+		{"Traceback (most recent call last):", false},
+		{`  File "database.py", line 18, in execute`, true},
+		{"    cursor.execute(sql, params)", true},
+		{"DatabaseError: connection already closed", true},
+		{"goroutine 1 [running]:", false},
+		{"main.main()", true},
+		{"\t/app/main.go:12 +0x1d", true},
+		{`{"level":"INFO","message":"Recovered"}`, false},
+		{`{"level":"INFO","message":"Still running"}`, false},
+	}
+
+	parser := NewParser()
+	for i, tc := range lines {
+		_, continued := parser.DetectEntry(tc.line)
+		if continued != tc.continued {
+			t.Errorf("Line %d %q: continued = %v, want %v", i+1, tc.line, continued, tc.continued)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Priority is a syslog PRI value, which packs the facility a message came
+// from and its severity as facility*8 + severity
+type Priority struct {
+	Facility int
+	Severity int
+}
+
+// ParsePriority parses a PRI value with or without its angle brackets, as in
+// "<134>" or "134"
+func ParsePriority(text string) (Priority, bool) {
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "<"), ">")
+	if text == "" || len(text) > 3 {
+		return Priority{}, false
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < 0 || n > 191 {
+		return Priority{}, false
+	}
+	return Priority{Facility: n / 8, Severity: n % 8}, true
+}
+
+// facilityNames are the names of the syslog facilities, by number
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// severityNames are the names of the syslog severities, by number
+var severityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// FacilityName returns the facility's conventional name, such as "local0"
+func (p Priority) FacilityName() string {
+	if p.Facility < 0 || p.Facility >= len(facilityNames) {
+		return strconv.Itoa(p.Facility)
+	}
+	return facilityNames[p.Facility]
+}
+
+// SeverityName returns the severity's conventional name, such as "warning"
+func (p Priority) SeverityName() string {
+	if p.Severity < 0 || p.Severity >= len(severityNames) {
+		return strconv.Itoa(p.Severity)
+	}
+	return severityNames[p.Severity]
+}
+
+// Level returns the log level of the severity
+func (p Priority) Level() Level {
+	return ParseLevel(p.SeverityName())
+}
+
+// SDElement is an element of RFC 5424 structured data, such as
+// [origin@32473 ip="10.0.0.1"]. Span covers the brackets.
+type SDElement struct {
+	Span   Span
+	ID     Field
+	Params []SDParam
+}
+
+// SDParam is a name="value" parameter of a structured data element. The
+// value's span is inside the quotes and its value has escapes decoded.
+type SDParam struct {
+	Name  Field
+	Value Field
+}
+
+// ParseStructuredData reads the RFC 5424 structured data at offset start of
+// line, either "-" or one or more elements. It returns the elements and the
+// offset just past them, or false when there is no well-formed structured
+// data at start.
+func ParseStructuredData(line string, start int) ([]SDElement, int, bool) {
+	if strings.HasPrefix(line[start:], "-") {
+		return nil, start + 1, true
+	}
+
+	var elements []SDElement
+	pos := start
+	for pos < len(line) && line[pos] == '[' {
+		element, end, ok := parseSDElement(line, pos)
+		if !ok {
+			return nil, start, false
+		}
+		elements = append(elements, element)
+		pos = end
+	}
+	if len(elements) == 0 {
+		return nil, start, false
+	}
+	return elements, pos, true
+}
+
+// parseSDElement reads the element starting with the '[' at offset start
+func parseSDElement(line string, start int) (SDElement, int, bool) {
+	pos := start + 1
+	name := func() Field {
+		begin := pos
+		for pos < len(line) && !strings.ContainsRune(` ="]`, rune(line[pos])) {
+			pos++
+		}
+		return newField(line, begin, pos)
+	}
+
+	element := SDElement{ID: name()}
+	if element.ID.Value == "" {
+		return SDElement{}, start, false
+	}
+	for pos < len(line) && line[pos] == ' ' {
+		pos++
+		param := SDParam{Name: name()}
+		if param.Name.Value == "" || !strings.HasPrefix(line[pos:], `="`) {
+			return SDElement{}, start, false
+		}
+		pos += 2
+
+		begin := pos
+		var value strings.Builder
+		for pos < len(line) && line[pos] != '"' {
+			// Only '"', '\' and ']' are escaped, any other backslash is literal
+			if line[pos] == '\\' && pos+1 < len(line) && strings.ContainsRune(`"\]`, rune(line[pos+1])) {
+				pos++
+			}
+			value.WriteByte(line[pos])
+			pos++
+		}
+		if pos >= len(line) {
+			return SDElement{}, start, false
+		}
+		param.Value = Field{Value: value.String(), Span: Span{begin, pos}, found: true}
+		pos++ // closing quote
+		element.Params = append(element.Params, param)
+	}
+	if pos >= len(line) || line[pos] != ']' {
+		return SDElement{}, start, false
+	}
+	pos++
+	element.Span = Span{start, pos}
+	return element, pos, true
+}
+
+// rfc5424HeaderRegex splits the header of an RFC 5424 line, up to its structured data
+var rfc5424HeaderRegex = regexp.MustCompile(`^<(?P<pri>\d{1,3})>(?P<version>\d{1,2}) (?P<timestamp>\S+) (?P<host>\S+) (?P<process>\S+) (?P<pid>\S+) (?P<msgid>\S+) `)
+
+// parseRFC5424 fills the entry from an RFC 5424 line. Header fields holding
+// "-", the nil value, are left unset. Structured data parameters become
+// attributes keyed by element ID and parameter name, as in "origin@32473.ip".
+func (e *LogEntry) parseRFC5424() {
+	loc := rfc5424HeaderRegex.FindStringSubmatchIndex(e.Raw)
+	if loc == nil {
+		return
+	}
+	for i, name := range rfc5424HeaderRegex.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if field := newField(e.Raw, loc[2*i], loc[2*i+1]); field.Value != "-" {
+			e.setField(name, Span{}, field)
+		}
+	}
+
+	elements, end, ok := ParseStructuredData(e.Raw, loc[1])
+	if !ok {
+		return
+	}
+	for _, element := range elements {
+		for _, param := range element.Params {
+			e.setField(element.ID.Value+"."+param.Name.Value, param.Name.Span, param.Value)
+		}
+	}
+	if end < len(e.Raw) && e.Raw[end] == ' ' {
+		start := end + 1
+		// A byte order mark may introduce a UTF-8 message
+		if strings.HasPrefix(e.Raw[start:], "\ufeff") {
+			start += len("\ufeff")
+		}
+		e.Message = newField(e.Raw, start, len(e.Raw))
+	}
+}
+
+// decodePriority adds the facility and severity packed in a syslog PRI
+// field, and takes the entry's level from the severity unless it has one
+func (e *LogEntry) decodePriority(pri Field) {
+	priority, ok := ParsePriority(pri.Value)
+	if !ok {
+		return
+	}
+	e.setField("facility", Span{}, Field{Value: priority.FacilityName(), Span: pri.Span, found: true})
+	severity := Field{Value: priority.SeverityName(), Span: pri.Span, found: true}
+	e.setField("severity", Span{}, severity)
+	if !e.LevelText.Found() {
+		e.LevelText = severity
+	}
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		text     string
+		want     Priority
+		ok       bool
+		facility string
+		severity string
+		level    Level
+	}{
+		{text: "<134>", want: Priority{Facility: 16, Severity: 6}, ok: true, facility: "local0", severity: "info", level: LevelInfo},
+		{text: "13", want: Priority{Facility: 1, Severity: 5}, ok: true, facility: "user", severity: "notice", level: LevelInfo},
+		{text: "<0>", want: Priority{}, ok: true, facility: "kern", severity: "emerg", level: LevelFatal},
+		{text: "<187>", want: Priority{Facility: 23, Severity: 3}, ok: true, facility: "local7", severity: "err", level: LevelError},
+		{text: "<12>", want: Priority{Facility: 1, Severity: 4}, ok: true, facility: "user", severity: "warning", level: LevelWarn},
+		{text: "<192>"},
+		{text: "<>"},
+		{text: "<-1>"},
+		{text: "<1x>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := ParsePriority(tt.text)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("ParsePriority(%q) = %+v, %v, want %+v, %v", tt.text, got, ok, tt.want, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got.FacilityName() != tt.facility || got.SeverityName() != tt.severity || got.Level() != tt.level {
+				t.Errorf("ParsePriority(%q) = %s.%s (%v), want %s.%s (%v)", tt.text,
+					got.FacilityName(), got.SeverityName(), got.Level(), tt.facility, tt.severity, tt.level)
+			}
+		})
+	}
+}
+
+func TestParseStructuredData(t *testing.T) {
+	type param struct{ name, value, raw string }
+	type element struct {
+		id     string
+		params []param
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		elements []element
+		rest     string
+		ok       bool
+	}{
+		{name: "nil", data: "- msg", rest: " msg", ok: true},
+		{
+			name: "one element",
+			data: `[origin@32473 ip="10.0.0.5" region="eu"] msg`,
+			elements: []element{{id: "origin@32473", params: []param{
+				{name: "ip", value: "10.0.0.5", raw: "10.0.0.5"},
+				{name: "region", value: "eu", raw: "eu"},
+			}}},
+			rest: " msg",
+			ok:   true,
+		},
+		{
+			name:     "several elements, one without params",
+			data:     `[timeQuality tzKnown="1"][meta]`,
+			elements: []element{{id: "timeQuality", params: []param{{name: "tzKnown", value: "1", raw: "1"}}}, {id: "meta"}},
+			ok:       true,
+		},
+		{
+			name:     "escapes",
+			data:     `[x@1 q="say \"hi\" \\ [a\]" path="C:\dir"]`,
+			elements: []element{{id: "x@1", params: []param{{name: "q", value: `say "hi" \ [a]`, raw: `say \"hi\" \\ [a\]`}, {name: "path", value: `C:\dir`, raw: `C:\dir`}}}},
+			ok:       true,
+		},
+		{name: "unterminated value", data: `[x@1 a="b]`},
+		{name: "unquoted value", data: `[x@1 a=b]`},
+		{name: "missing bracket", data: `[x@1 a="b"`},
+		{name: "empty ID", data: `[]`},
+		{name: "message", data: `msg`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := "<134>1 " + tt.data
+			start := len("<134>1 ")
+			elements, end, ok := ParseStructuredData(line, start)
+			if ok != tt.ok {
+				t.Fatalf("ParseStructuredData(%q) ok = %v, want %v", tt.data, ok, tt.ok)
+			}
+			if !ok {
+				if end != start {
+					t.Errorf("ParseStructuredData(%q) end = %d, want %d", tt.data, end, start)
+				}
+				return
+			}
+			if rest := line[end:]; rest != tt.rest {
+				t.Errorf("ParseStructuredData(%q) left %q, want %q", tt.data, rest, tt.rest)
+			}
+
+			var got []element
+			for _, e := range elements {
+				if e.ID.Value != line[e.ID.Span.Start:e.ID.Span.End] {
+					t.Errorf("ID %q has span covering %q", e.ID.Value, line[e.ID.Span.Start:e.ID.Span.End])
+				}
+				el := element{id: e.ID.Value}
+				for _, p := range e.Params {
+					el.params = append(el.params, param{name: p.Name.Value, value: p.Value.Value, raw: line[p.Value.Span.Start:p.Value.Span.End]})
+				}
+				got = append(got, el)
+			}
+			if !reflect.DeepEqual(got, tt.elements) {
+				t.Errorf("ParseStructuredData(%q) = %+v, want %+v", tt.data, got, tt.elements)
+			}
+		})
+	}
+}
+
+func TestParseAsRFC5424(t *testing.T) {
+	line := `<165>1 2025-01-19T10:30:00.123Z web01 billing 4321 PAY001 [origin@32473 ip="10.0.0.5"] Payment settled`
+	entry := ParseAs(line, RFC5424Format)
+
+	if entry.Level != LevelInfo || entry.LevelText.Value != "notice" {
+		t.Errorf("Level = %v from %q, want %v from %q", entry.Level, entry.LevelText.Value, LevelInfo, "notice")
+	}
+	if want := time.Date(2025, 1, 19, 10, 30, 0, 123000000, time.UTC); !entry.Timestamp.Time.Equal(want) {
+		t.Errorf("Timestamp.Time = %v, want %v", entry.Timestamp.Time, want)
+	}
+	want := map[string]string{
+		"pri":             "165",
+		"version":         "1",
+		"timestamp":       "2025-01-19T10:30:00.123Z",
+		"host":            "web01",
+		"process":         "billing",
+		"pid":             "4321",
+		"msgid":           "PAY001",
+		"origin@32473.ip": "10.0.0.5",
+		"message":         "Payment settled",
+		"facility":        "local4",
+		"severity":        "notice",
+		"level":           "notice",
+	}
+	if got := entry.Fields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+	if pri, _ := entry.Attribute("severity"); line[pri.Span.Start:pri.Span.End] != "165" {
+		t.Errorf("severity span covers %q, want the PRI", line[pri.Span.Start:pri.Span.End])
+	}
+
+	// Nil header fields are left unset, and a level in the message doesn't override the PRI
+	entry = ParseAs("<11>1 - - - - - - \ufeffERROR: disk full", RFC5424Format)
+	if entry.Timestamp.Found() || entry.Host.Found() || entry.Process.Found() || entry.PID.Found() {
+		t.Errorf("ParseAs() set nil fields: %+v", entry)
+	}
+	if _, ok := entry.Attribute("msgid"); ok {
+		t.Error(`Attribute("msgid") found for a nil MSGID`)
+	}
+	if entry.Message.Value != "ERROR: disk full" {
+		t.Errorf("Message = %q, want %q", entry.Message.Value, "ERROR: disk full")
+	}
+	if entry.LevelText.Value != "err" || entry.Level != LevelError {
+		t.Errorf("Level = %v from %q, want %v from %q", entry.Level, entry.LevelText.Value, LevelError, "err")
+	}
+}
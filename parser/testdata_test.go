@@ -21,10 +21,17 @@ func TestDetectionWithTestData(t *testing.T) {
 		{"nginx.log", NginxFormat, "Nginx Combined Log format"},
 		{"syslog.log", SyslogFormat, "System log format"},
 		{"rsyslog.log", RsyslogFormat, "Rsyslog multi-line entries"},
+		{"rfc5424.log", RFC5424Format, "RFC 5424 syslog with structured data"},
+		{"journal-short.log", SyslogFormat, "journalctl short-iso and short-precise output"},
+		{"journal-json.log", JournalJSONFormat, "journalctl JSON output"},
+		{"journal-export.log", JournalExportFormat, "journalctl export records"},
 		{"go_standard.log", GoStandardFormat, "Go standard log format"},
 		{"rails.log", RailsFormat, "Rails application logs"},
 		{"docker.log", DockerFormat, "Docker container logs"},
 		{"kubernetes.log", KubernetesFormat, "Kubernetes pod logs"},
+		{"klog.log", KlogFormat, "klog output of Kubernetes components"},
+		{"cri.log", JSONFormat, "JSON app logs in CRI envelopes, with a partial line"},
+		{"kubectl.log", LogfmtFormat, "logfmt app logs from kubectl logs --prefix --timestamps"},
 		{"heroku.log", HerokuFormat, "Heroku dyno logs"},
 	}
 
@@ -40,7 +47,7 @@ func TestDetectionWithTestData(t *testing.T) {
 			}
 			defer file.Close()
 
-			scanner := bufio.NewScanner(file)
+			scanner := NewLineScanner(file) // rejoins partial CRI lines, as splash does
 			lineCount := 0
 			correctDetections := 0
 
@@ -155,3 +162,57 @@ func BenchmarkTestDataDetection(b *testing.B) {
 		}
 	}
 }
+
+// loadTestDataCorpus reads every testdata/*.log file and groups the lines by the
+// format a fresh parser detects for them, keeping file order within each group
+func loadTestDataCorpus(tb testing.TB) map[LogFormat][]string {
+	tb.Helper()
+
+	paths, err := filepath.Glob(filepath.Join("..", "testdata", "*.log"))
+	if err != nil || len(paths) == 0 {
+		tb.Fatalf("Failed to find testdata logs: %v", err)
+	}
+
+	corpus := make(map[LogFormat][]string)
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			tb.Fatalf("Failed to open %s: %v", path, err)
+		}
+
+		parser := NewParser()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			format := parser.DetectFormat(line)
+			corpus[format] = append(corpus[format], line)
+		}
+		file.Close()
+	}
+	return corpus
+}
+
+// BenchmarkTestDataCorpusByFormat reports detection throughput in lines/sec for
+// every format found in the testdata corpus
+func BenchmarkTestDataCorpusByFormat(b *testing.B) {
+	corpus := loadTestDataCorpus(b)
+
+	for format := UnknownFormat; format < numBuiltinFormats; format++ {
+		lines := corpus[format]
+		if len(lines) == 0 {
+			continue
+		}
+
+		b.Run(strings.ReplaceAll(format.String(), " ", ""), func(b *testing.B) {
+			parser := NewParser()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, line := range lines {
+					parser.DetectFormat(line)
+				}
+			}
+			b.ReportMetric(float64(b.N*len(lines))/b.Elapsed().Seconds(), "lines/s")
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+)
+
+// timestampKeys are the structured-log keys that commonly hold an entry's timestamp
+var timestampKeys = []string{"timestamp", "time", "ts", "@timestamp", "datetime", "date", "t"}
+
+// timestampLayouts are the layouts tried for free-standing timestamp values.
+// Fractional seconds are accepted after the seconds field even when a layout
+// doesn't spell them out.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// ParseTimestamp extracts the timestamp of a log line using what its detected
+// format says about where the timestamp lives. ok is false when the line has
+// no timestamp, which is how continuation lines (stack frames and the like)
+// can be told apart from the entries they belong to. Timestamps without a
+// zone are interpreted in the local time zone.
+func ParseTimestamp(line string, format LogFormat) (t time.Time, ok bool) {
+	// The message's own timestamp wins over its envelope's
+	if envelope, ok := ParseEnvelope(line); ok {
+		if t, ok := ParseTimestamp(line[envelope.Span.End:], format); ok {
+			return t, true
+		}
+		return envelope.Timestamp.Time, envelope.Timestamp.Found()
+	}
+
+	switch format {
+	case JSONFormat:
+		return jsonTimestamp(line)
+	case LogfmtFormat:
+		return logfmtTimestamp(line)
+	case ApacheCommonFormat, NginxFormat:
+		return bracketedTimestamp(line, "02/Jan/2006:15:04:05 -0700")
+	case SyslogFormat, RFC5424Format, JournalJSONFormat, KlogFormat:
+		return layoutTimestamp(line, format)
+	case JournalExportFormat:
+		return journalCursorTimestamp(line)
+	case RsyslogFormat:
+		return syslogTimestamp(line, time.Now())
+	case GoStandardFormat:
+		return leadingTimestamp(line, 2)
+	case RailsFormat:
+		return bracketedTimestamp(line, "2006-01-02 15:04:05")
+	case DockerFormat, KubernetesFormat, HerokuFormat:
+		return leadingTimestamp(line, 1)
+	default:
+		return time.Time{}, false
+	}
+}
+
+// parseTimeValue parses a timestamp value using the common layouts
+func parseTimeValue(value string) (time.Time, bool) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseEpoch interprets a number as seconds, milliseconds, microseconds or
+// nanoseconds since the Unix epoch depending on its magnitude
+func parseEpoch(n float64) (time.Time, bool) {
+	if n <= 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return time.Time{}, false
+	}
+	switch {
+	case n >= 1e17:
+		return time.Unix(0, int64(n)), true
+	case n >= 1e14:
+		return time.UnixMicro(int64(n)), true
+	case n >= 1e11:
+		return time.UnixMilli(int64(n)), true
+	default:
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(frac*1e9)), true
+	}
+}
+
+// jsonTimestamp reads the first well-known timestamp key of a JSON object
+func jsonTimestamp(line string) (time.Time, bool) {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return time.Time{}, false
+	}
+
+	for _, key := range timestampKeys {
+		switch v := obj[key].(type) {
+		case string:
+			if t, ok := parseTimeValue(v); ok {
+				return t, true
+			}
+		case float64:
+			if t, ok := parseEpoch(v); ok {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// logfmtTimestamp reads the first well-known timestamp key of a logfmt line
+func logfmtTimestamp(line string) (time.Time, bool) {
+	values := logfmtFields(line)
+	for _, key := range timestampKeys {
+		value, found := values[key]
+		if !found {
+			continue
+		}
+		if t, ok := parseTimeValue(value); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// bracketedTimestamp parses the contents of the first [...] group in the line
+func bracketedTimestamp(line, layout string) (time.Time, bool) {
+	start := strings.IndexByte(line, '[')
+	if start < 0 {
+		return time.Time{}, false
+	}
+	end := strings.IndexByte(line[start:], ']')
+	if end < 0 {
+		return time.Time{}, false
+	}
+
+	t, err := time.ParseInLocation(layout, line[start+1:start+end], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// leadingTimestamp parses the first n space-separated fields of the line
+func leadingTimestamp(line string, n int) (time.Time, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < n {
+		return time.Time{}, false
+	}
+	return parseTimeValue(strings.Join(fields[:n], " "))
+}
+
+// layoutTimestamp parses the timestamp field ParseAs finds in the line
+func layoutTimestamp(line string, format LogFormat) (time.Time, bool) {
+	t := ParseAs(line, format).Timestamp.Time
+	return t, !t.IsZero()
+}
+
+// syslogTimestamp parses a BSD syslog "Jan  2 15:04:05" timestamp. The format
+// has no year, so the year is chosen to put the timestamp no later than a day
+// after now, which keeps December entries read in January in the right year.
+func syslogTimestamp(line string, now time.Time) (time.Time, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return time.Time{}, false
+	}
+
+	t, err := time.ParseInLocation("Jan 2 15:04:05", strings.Join(fields[:3], " "), time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return withInferredYear(t, now), true
+}
+
+// klogTimestamp parses a klog "0102 15:04:05.000000" timestamp, which has no
+// year either
+func klogTimestamp(text string, now time.Time) (time.Time, bool) {
+	t, err := time.ParseInLocation("0102 15:04:05", text, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return withInferredYear(t, now), true
+}
+
+// withInferredYear moves a timestamp parsed without a year to the year that
+// puts it no later than a day after now
+func withInferredYear(t, now time.Time) time.Time {
+	year := now.Year()
+	if time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.Local).After(now.Add(24 * time.Hour)) {
+		year--
+	}
+	return time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+}
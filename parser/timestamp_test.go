@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	utc := time.Date(2025, 1, 19, 8, 30, 0, 0, time.UTC)
+	local := time.Date(2025, 1, 19, 8, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name   string
+		line   string
+		format LogFormat
+		want   time.Time
+		wantOK bool
+	}{
+		{"JSON RFC3339", `{"timestamp":"2025-01-19T08:30:00Z","level":"INFO"}`, JSONFormat, utc, true},
+		{"JSON time key", `{"level":"INFO","time":"2025-01-19T08:30:00.000Z"}`, JSONFormat, utc, true},
+		{"JSON epoch seconds", `{"ts":1737275400,"msg":"x"}`, JSONFormat, utc, true},
+		{"JSON epoch millis", `{"ts":1737275400000,"msg":"x"}`, JSONFormat, utc, true},
+		{"JSON without timestamp", `{"level":"INFO","msg":"x"}`, JSONFormat, time.Time{}, false},
+		{"Logfmt", `timestamp=2025-01-19T08:30:00Z level=info msg="Application started"`, LogfmtFormat, utc, true},
+		{"Logfmt quoted time after message", `level=info msg="started up" time="2025-01-19T08:30:00Z"`, LogfmtFormat, utc, true},
+		{"Apache", `127.0.0.1 - - [19/Jan/2025:08:30:00 +0000] "GET / HTTP/1.1" 200 1234`, ApacheCommonFormat, utc, true},
+		{"Nginx", `127.0.0.1 - - [19/Jan/2025:08:30:00 +0000] "GET / HTTP/1.1" 200 1234 "-" "curl"`, NginxFormat, utc, true},
+		{"Go standard", `2025/01/19 08:30:00 INFO: Application started`, GoStandardFormat, local, true},
+		{"Go standard microseconds", `2025/01/19 08:30:00.000000 main.go:12: started`, GoStandardFormat, local, true},
+		{"Syslog high-precision", `2025-01-19T08:30:00.000000+00:00 web01 sshd[812]: Accepted`, SyslogFormat, utc, true},
+		{"journalctl short-iso", `2025-01-19T08:30:00+0000 web01 billing[4321]: started`, SyslogFormat, utc, true},
+		{"Journal JSON", `{"__REALTIME_TIMESTAMP":"1737275400000000","MESSAGE":"started"}`, JournalJSONFormat, utc, true},
+		{"RFC 5424", `<134>1 2025-01-19T08:30:00.000Z web01 app - - - started`, RFC5424Format, utc, true},
+		{"RFC 5424 nil timestamp", `<134>1 - web01 app - - - started`, RFC5424Format, time.Time{}, false},
+		{"Rails", `[2025-01-19 08:30:00] INFO -- : Started GET "/"`, RailsFormat, local, true},
+		{"Docker", `2025-01-19T08:30:00.000000000Z INFO Container started`, DockerFormat, utc, true},
+		{"Kubernetes", `2025-01-19T08:30:00.000Z 1 main.go:42] INFO Starting`, KubernetesFormat, utc, true},
+		{"CRI envelope", `2025-01-19T08:30:00.000000000Z stdout F started`, UnknownFormat, utc, true},
+		{"CRI envelope around a timestamp", `2025-01-19T09:00:00Z stdout F {"time":"2025-01-19T08:30:00Z","msg":"x"}`, JSONFormat, utc, true},
+		{"kubectl prefix without a timestamp", `[pod/web-1/app] started`, UnknownFormat, time.Time{}, false},
+		{"Heroku", `2025-01-19T08:30:00+00:00 app[web.1]: INFO Starting`, HerokuFormat, utc, true},
+		{"Java stack frame", `	at com.example.Main.run(Main.java:42)`, JavaExceptionFormat, time.Time{}, false},
+		{"Unknown", `just some text`, UnknownFormat, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseTimestamp(tt.line, tt.format)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseTimestamp() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("ParseTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyslogTimestampInfersYear(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.Local)
+
+	got, ok := syslogTimestamp("Jan  1 08:30:00 host app[1]: started", now)
+	if !ok || !got.Equal(time.Date(2025, 1, 1, 8, 30, 0, 0, time.Local)) {
+		t.Errorf("syslogTimestamp() = %v, %v; want Jan 1 2025", got, ok)
+	}
+
+	// December entries read in early January belong to the previous year
+	got, ok = syslogTimestamp("Dec 31 23:59:59 host app[1]: stopping", now)
+	if !ok || !got.Equal(time.Date(2024, 12, 31, 23, 59, 59, 0, time.Local)) {
+		t.Errorf("syslogTimestamp() = %v, %v; want Dec 31 2024", got, ok)
+	}
+}
+
+func TestKlogTimestampInfersYear(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.Local)
+
+	got, ok := klogTimestamp("0101 08:30:00.123456", now)
+	if !ok || !got.Equal(time.Date(2025, 1, 1, 8, 30, 0, 123456000, time.Local)) {
+		t.Errorf("klogTimestamp() = %v, %v; want Jan 1 2025 with microseconds", got, ok)
+	}
+
+	got, ok = klogTimestamp("1231 23:59:59.000000", now)
+	if !ok || !got.Equal(time.Date(2024, 12, 31, 23, 59, 59, 0, time.Local)) {
+		t.Errorf("klogTimestamp() = %v, %v; want Dec 31 2024", got, ok)
+	}
+
+	if _, ok := klogTimestamp("1399 08:30:00.000000", now); ok {
+		t.Error("klogTimestamp() accepted an invalid date")
+	}
+}
@@ -0,0 +1,165 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokCompare // == != > >= < <= =~ !~
+	tokAnd
+	tokOr
+	tokNot
+	tokIn
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+// token is one lexical element of an expression, with the byte offset it starts at
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// describe names a token for error messages
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return "string " + quote(t.text)
+	default:
+		return quote(t.text)
+	}
+}
+
+func quote(s string) string {
+	return `"` + s + `"`
+}
+
+// compareOps lists the comparison operators, longest first so ">=" wins over ">"
+var compareOps = []string{"==", "!=", ">=", "<=", "=~", "!~", ">", "<"}
+
+// isWordRune reports whether r can be part of a bare word: a field name,
+// number or unquoted value such as http.request.method, 250 or 10.0.0.5
+func isWordRune(r rune) bool {
+	if unicode.IsSpace(r) {
+		return false
+	}
+	return !strings.ContainsRune(`()!=<>~&|,"'`, r)
+}
+
+// lex splits an expression into tokens
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	for pos < len(expr) {
+		rest := expr[pos:]
+		r := rune(rest[0])
+
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: pos})
+			pos++
+			continue
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: pos})
+			pos++
+			continue
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: pos})
+			pos++
+			continue
+		case strings.HasPrefix(rest, "&&"):
+			tokens = append(tokens, token{kind: tokAnd, text: "&&", pos: pos})
+			pos += 2
+			continue
+		case strings.HasPrefix(rest, "||"):
+			tokens = append(tokens, token{kind: tokOr, text: "||", pos: pos})
+			pos += 2
+			continue
+		case r == '"' || r == '\'':
+			text, end, err := lexString(expr, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: text, pos: pos})
+			pos = end
+			continue
+		}
+
+		if op := compareOp(rest); op != "" {
+			tokens = append(tokens, token{kind: tokCompare, text: op, pos: pos})
+			pos += len(op)
+			continue
+		}
+		if r == '!' {
+			tokens = append(tokens, token{kind: tokNot, text: "!", pos: pos})
+			pos++
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) })
+		if end == 0 {
+			if r == '=' {
+				return nil, &SyntaxError{Pos: pos, Msg: `unexpected "=", use "==" to compare`}
+			}
+			return nil, &SyntaxError{Pos: pos, Msg: "unexpected " + quote(string(r))}
+		}
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		kind := tokWord
+		if word == "in" {
+			kind = tokIn
+		}
+		tokens = append(tokens, token{kind: kind, text: word, pos: pos})
+		pos += end
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(expr)})
+	return tokens, nil
+}
+
+// compareOp returns the comparison operator at the start of s, if any
+func compareOp(s string) string {
+	for _, op := range compareOps {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// lexString reads a single or double quoted string starting at pos. A
+// backslash escapes a quote or another backslash and is kept as is before
+// anything else, so regular expressions like "\d+" need no extra escaping.
+// It returns the unescaped text and the offset just past the closing quote.
+func lexString(expr string, pos int) (string, int, error) {
+	quoteChar := expr[pos]
+	var text strings.Builder
+	for i := pos + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			if i+1 < len(expr) && (expr[i+1] == quoteChar || expr[i+1] == '\\') {
+				i++
+			}
+			text.WriteByte(expr[i])
+		case quoteChar:
+			return text.String(), i + 1, nil
+		default:
+			text.WriteByte(expr[i])
+		}
+	}
+	return "", 0, &SyntaxError{Pos: pos, Msg: "unterminated string"}
+}
@@ -0,0 +1,328 @@
+// Package query evaluates --where expressions against the fields of a log line.
+//
+// An expression compares fields with values and combines the comparisons:
+//
+//	level in (error,fatal) && service == "api" && status >= 500
+//	http.request.method == POST || !(user_id)
+//	msg =~ "timeout|deadline"
+//
+// Values compare as numbers when both sides are numbers and as text
+// otherwise. == != and in ignore case; =~ and !~ take a regular expression.
+// A bare field name tests whether the field is present. A comparison against
+// a missing field is false, except != and !~, which are true.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed expression
+type Query struct {
+	expr string
+	root node
+}
+
+// SyntaxError describes what is wrong with an expression and where
+type SyntaxError struct {
+	Pos int // byte offset into the expression
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Msg)
+}
+
+// Parse compiles an expression. Errors are *SyntaxError.
+func Parse(expr string) (*Query, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, &SyntaxError{Pos: 0, Msg: "empty expression"}
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: "unexpected " + t.describe()}
+	}
+	return &Query{expr: expr, root: root}, nil
+}
+
+// String returns the expression the query was parsed from
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Match reports whether fields satisfy the query
+func (q *Query) Match(fields map[string]string) bool {
+	return q.root.eval(fields)
+}
+
+// node is one part of a parsed expression
+type node interface {
+	eval(fields map[string]string) bool
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(fields map[string]string) bool {
+	return n.left.eval(fields) || n.right.eval(fields)
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(fields map[string]string) bool {
+	return n.left.eval(fields) && n.right.eval(fields)
+}
+
+type notNode struct{ x node }
+
+func (n notNode) eval(fields map[string]string) bool {
+	return !n.x.eval(fields)
+}
+
+// existsNode tests whether a field is present
+type existsNode struct{ field string }
+
+func (n existsNode) eval(fields map[string]string) bool {
+	_, ok := fields[n.field]
+	return ok
+}
+
+// compareNode compares a field with a value
+type compareNode struct {
+	field string
+	op    string
+	value string
+	regex *regexp.Regexp // for =~ and !~
+}
+
+func (n compareNode) eval(fields map[string]string) bool {
+	actual, ok := fields[n.field]
+	if !ok {
+		return n.op == "!=" || n.op == "!~"
+	}
+
+	switch n.op {
+	case "==":
+		return equal(actual, n.value)
+	case "!=":
+		return !equal(actual, n.value)
+	case "=~":
+		return n.regex.MatchString(actual)
+	case "!~":
+		return !n.regex.MatchString(actual)
+	}
+
+	cmp := compare(actual, n.value)
+	switch n.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default: // "<="
+		return cmp <= 0
+	}
+}
+
+// inNode tests whether a field equals any of a list of values
+type inNode struct {
+	field  string
+	values []string
+}
+
+func (n inNode) eval(fields map[string]string) bool {
+	actual, ok := fields[n.field]
+	if !ok {
+		return false
+	}
+	for _, v := range n.values {
+		if equal(actual, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares numerically when both values are numbers, and otherwise
+// as text regardless of case
+func equal(a, b string) bool {
+	if x, y, ok := numbers(a, b); ok {
+		return x == y
+	}
+	return strings.EqualFold(a, b)
+}
+
+// compare orders numerically when both values are numbers, and otherwise as text
+func compare(a, b string) int {
+	if x, y, ok := numbers(a, b); ok {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func numbers(a, b string) (float64, float64, bool) {
+	x, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	y, err := strconv.ParseFloat(b, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// parser is a recursive descent parser over the tokens of an expression:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" or ")" | field [ compare value | "in" "(" value { "," value } ")" ]
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNot:
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, &SyntaxError{Pos: closing.pos, Msg: "expected \")\" to close \"(\" at column " + strconv.Itoa(t.pos+1) + ", found " + closing.describe()}
+		}
+		return x, nil
+	case tokWord:
+		return p.parseCondition(t)
+	default:
+		return nil, &SyntaxError{Pos: t.pos, Msg: "expected a field name, found " + t.describe()}
+	}
+}
+
+// parseCondition parses what follows a field name
+func (p *parser) parseCondition(field token) (node, error) {
+	switch op := p.peek(); op.kind {
+	case tokCompare:
+		p.next()
+		value, err := p.parseValue(op)
+		if err != nil {
+			return nil, err
+		}
+		n := compareNode{field: field.text, op: op.text, value: value.text}
+		if op.text == "=~" || op.text == "!~" {
+			n.regex, err = regexp.Compile(value.text)
+			if err != nil {
+				return nil, &SyntaxError{Pos: value.pos, Msg: "invalid regular expression: " + err.Error()}
+			}
+		}
+		return n, nil
+	case tokIn:
+		p.next()
+		return p.parseList(field, op)
+	default:
+		return existsNode{field: field.text}, nil
+	}
+}
+
+// parseValue reads the value after an operator
+func (p *parser) parseValue(op token) (token, error) {
+	t := p.next()
+	if t.kind != tokWord && t.kind != tokString {
+		return t, &SyntaxError{Pos: t.pos, Msg: "expected a value after " + op.describe() + ", found " + t.describe()}
+	}
+	return t, nil
+}
+
+// parseList reads the parenthesized values after "in"
+func (p *parser) parseList(field, in token) (node, error) {
+	if t := p.next(); t.kind != tokLParen {
+		return nil, &SyntaxError{Pos: t.pos, Msg: "expected \"(\" after \"in\", found " + t.describe()}
+	}
+
+	n := inNode{field: field.text}
+	for {
+		value, err := p.parseValue(in)
+		if err != nil {
+			return nil, err
+		}
+		n.values = append(n.values, value.text)
+
+		switch t := p.next(); t.kind {
+		case tokComma:
+		case tokRParen:
+			return n, nil
+		default:
+			return nil, &SyntaxError{Pos: t.pos, Msg: "expected \",\" or \")\" in list, found " + t.describe()}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	fields := map[string]string{
+		"level":               "ERROR",
+		"service":             "api",
+		"status":              "503",
+		"duration_ms":         "312.5",
+		"http.request.method": "POST",
+		"msg":                 "upstream timeout after 30s",
+		"ts":                  "2025-01-19T08:30:00Z",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`level in (error,fatal) && service == "api" && status >= 500 && duration_ms > 250`, true},
+		{`level in (warn, info)`, false},
+		{`level == error`, true},
+		{`level != error`, false},
+		{`service == 'api'`, true},
+		{`status == 503.0`, true},
+		{`status > 600`, false},
+		{`status < 1000`, true},
+		{`status <= 503 && status >= 503`, true},
+		{`http.request.method == post`, true},
+		{`msg =~ "timeout|deadline"`, true},
+		{`msg =~ "\d+s$"`, true},
+		{`msg !~ timeout`, false},
+		{`ts >= "2025-01-19T08:00:00Z"`, true},
+		{`missing == x`, false},
+		{`missing != x`, true},
+		{`missing in (x)`, false},
+		{`service`, true},
+		{`!missing`, true},
+		{`!(level == error)`, false},
+		{`level == info || status >= 500`, true},
+		{`level == info || status >= 500 && service == web`, false},
+		{`(level == info || status >= 500) && service == api`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := q.Match(fields); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{``, 0, "empty expression"},
+		{`status >=`, 9, `expected a value after ">=", found end of expression`},
+		{`level = error`, 6, `unexpected "=", use "==" to compare`},
+		{`level in error`, 9, `expected "(" after "in", found "error"`},
+		{`level in (error fatal)`, 16, `expected "," or ")" in list, found "fatal"`},
+		{`(level == error`, 15, `expected ")" to close "(" at column 1, found end of expression`},
+		{`level == error &&`, 17, "expected a field name, found end of expression"},
+		{`level == error)`, 14, `unexpected ")"`},
+		{`msg == "open`, 7, "unterminated string"},
+		{`msg =~ "("`, 7, "invalid regular expression: error parsing regexp: missing closing ): `(`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse() error = %v, want a *SyntaxError", err)
+			}
+			if syntaxErr.Pos != tt.pos || syntaxErr.Msg != tt.msg {
+				t.Errorf("Parse() error at %d %q, want at %d %q", syntaxErr.Pos, syntaxErr.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}